@@ -0,0 +1,312 @@
+package mazon
+
+import (
+	"errors"
+	"maps"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+// AddressNormalizer 地址规范化，比如 usaddress.Normalize
+//
+// 返回的 validation.Errors（以 entity.Address 的 JSON 字段名称为键）会转换为请求中对应字段的验证错误。
+type AddressNormalizer func(a entity.Address) (entity.Address, error)
+
+// 通用地址字段与请求字段的对应关系
+var (
+	recipientFields = map[string]string{
+		"name":        "oa_firstname",
+		"company":     "oa_company",
+		"phone":       "oa_telphone",
+		"country":     "oa_country",
+		"state":       "oa_state",
+		"city":        "oa_city",
+		"postal_code": "oa_postcode",
+		"line1":       "oa_street_address1",
+		"line2":       "oa_street_address2",
+	}
+	shipperFields = map[string]string{
+		"name":        "shipper_name",
+		"company":     "shipper_company",
+		"phone":       "shipper_tel_phone",
+		"country":     "shipper_country",
+		"state":       "shipper_state_province",
+		"city":        "shipper_city",
+		"postal_code": "shipper_postal_code",
+		"line1":       "shipper_address1",
+		"line2":       "shipper_address2",
+		"code":        "shipper_code",
+	}
+	returnFields = map[string]string{
+		"name":        "first_name",
+		"phone":       "phone",
+		"state":       "state",
+		"city":        "city",
+		"postal_code": "zip_code_and_plus4",
+		"line1":       "street_address",
+		"line2":       "secondary_address",
+	}
+)
+
+// normalizeAddress 规范化地址，验证错误转换为请求的字段名称后保存到 errs 中（key 不为空时作为 key 的嵌套错误）
+func normalizeAddress(normalize AddressNormalizer, a entity.Address, key string, fields map[string]string, errs validation.Errors) (entity.Address, error) {
+	normalized, err := normalize(a)
+	if err == nil {
+		return normalized, nil
+	}
+	var addressErrs validation.Errors
+	if !errors.As(err, &addressErrs) {
+		return a, err
+	}
+
+	fieldErrs := make(validation.Errors, len(addressErrs))
+	for name, e := range addressErrs {
+		if field, ok := fields[name]; ok {
+			name = field
+		}
+		fieldErrs[name] = e
+	}
+	if key == "" {
+		maps.Copy(errs, fieldErrs)
+	} else {
+		errs[key] = fieldErrs
+	}
+	return a, nil
+}
+
+// normalizeAddresses 规范化收件人、发件人和退件地址
+func normalizeAddresses(normalize AddressNormalizer, recipient *entity.Address, shipper *entity.ShipperAddress, returnAddress *entity.ReturnAddress) error {
+	errs := validation.Errors{}
+	a, err := normalizeAddress(normalize, *recipient, "", recipientFields, errs)
+	if err != nil {
+		return err
+	}
+	*recipient = a
+
+	if shipper != nil {
+		a, err = normalizeAddress(normalize, shipper.Address(), "shipper_address", shipperFields, errs)
+		if err != nil {
+			return err
+		}
+		*shipper = a.ShipperAddress()
+	}
+
+	if returnAddress != nil {
+		a, err = normalizeAddress(normalize, returnAddress.Address(), "return_address", returnFields, errs)
+		if err != nil {
+			return err
+		}
+		// 保留原来的名字和姓氏
+		firstName, lastName := returnAddress.FirstName, returnAddress.LastName
+		*returnAddress = a.ReturnAddress()
+		returnAddress.FirstName, returnAddress.LastName = firstName, lastName
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// recipientRules 收件人字段的验证规则（创建订单和运费试算共用）
+func recipientRules(name, company, phone, country, state, city, postcode, line1, line2 *string) []*validation.FieldRules {
+	return []*validation.FieldRules{
+		validation.Field(name,
+			validation.Required.Error("收件人不能为空"),
+			validation.Length(3, 35).Error("收件人长度必须在 {{.min}} ~ {{.max}} 个字符"),
+		),
+		validation.Field(company, validation.When(*company != "", validation.Length(1, 35).Error("收件人公司不能超过 {{.max}} 个字符"))),
+		validation.Field(line1,
+			validation.Required.Error("收件人地址1不能为空"),
+			validation.Length(1, 35).Error("收件人地址1长度不能超过 {{.max}} 个字符"),
+		),
+		validation.Field(line2,
+			validation.When(*line2 != "", validation.Length(1, 35).Error("收件人地址2长度不能超过 {{.max}} 个字符")),
+		),
+		validation.Field(postcode, validation.Required.Error("收件人邮编不能为空")),
+		validation.Field(state, validation.Required.Error("收件人州不能为空")),
+		validation.Field(city, validation.Required.Error("收件人城市不能为空")),
+		validation.Field(country, validation.Required.Error("收件人国家（国家二字码）不能为空")),
+		validation.Field(phone,
+			validation.Required.Error("收件人电话不能为空"),
+			validation.Length(10, 15).Error("收件人电话长度必须在 {{.min}} ~ {{.max}} 个字符"),
+		),
+	}
+}
+
+// Recipient 返回收件人地址
+func (m CreateOrderRequest) Recipient() entity.Address {
+	return entity.Address{
+		Name:       m.OAFirstname,
+		Company:    m.OACompany,
+		Phone:      m.OATelephone,
+		Country:    m.OACountry,
+		State:      m.OAState,
+		City:       m.OACity,
+		PostalCode: m.OAPostcode,
+		Line1:      m.OAStreetAddress1,
+		Line2:      m.OAStreetAddress2,
+	}
+}
+
+// SetRecipient 设置收件人地址
+func (m *CreateOrderRequest) SetRecipient(a entity.Address) {
+	m.OAFirstname = a.Name
+	m.OACompany = a.Company
+	m.OATelephone = a.Phone
+	m.OACountry = a.Country
+	m.OAState = a.State
+	m.OACity = a.City
+	m.OAPostcode = a.PostalCode
+	m.OAStreetAddress1 = a.Line1
+	m.OAStreetAddress2 = a.Line2
+}
+
+// NormalizeAddresses 规范化收件人、发件人和退件地址，比如 req.NormalizeAddresses(usaddress.Normalize)
+//
+// 规范化失败时返回 validation.Errors（字段名称与 Validate 相同），可以使用 ValidateRequest 的方式转换为 *ValidationError。
+func (m *CreateOrderRequest) NormalizeAddresses(normalize AddressNormalizer) error {
+	// 不修改调用方的发件人和退件地址
+	m.ShipperAddress = cloneShipperAddress(m.ShipperAddress)
+	if m.ReturnAddress != nil {
+		returnAddress := *m.ReturnAddress
+		m.ReturnAddress = &returnAddress
+	}
+	recipient := m.Recipient()
+	if err := normalizeAddresses(normalize, &recipient, m.ShipperAddress, m.ReturnAddress); err != nil {
+		return err
+	}
+	m.SetRecipient(recipient)
+	return nil
+}
+
+// SetShipper 设置发件人信息（地址编码为发件人编码，只有地址编码时不设置发件人地址）
+func (m *CreateOrderRequest) SetShipper(a entity.Address) {
+	m.ShipperAddress = newShipperAddress(a)
+	m.ShipperCode = a.Code
+}
+
+// SetReturn 设置退件地址
+func (m *CreateOrderRequest) SetReturn(a entity.Address) {
+	returnAddress := a.ReturnAddress()
+	m.ReturnAddress = &returnAddress
+}
+
+// Recipient 返回收件人地址
+func (m RateCalcRequest) Recipient() entity.Address {
+	return entity.Address{
+		Name:       m.OAFirstname,
+		Company:    m.OACompany,
+		Phone:      m.OATelephone,
+		Country:    m.OACountry,
+		State:      m.OAState,
+		City:       m.OACity,
+		PostalCode: m.OAPostcode,
+		Line1:      m.OAStreetAddress1,
+		Line2:      m.OAStreetAddress2,
+	}
+}
+
+// SetRecipient 设置收件人地址
+func (m *RateCalcRequest) SetRecipient(a entity.Address) {
+	m.OAFirstname = a.Name
+	m.OACompany = a.Company
+	m.OATelephone = a.Phone
+	m.OACountry = a.Country
+	m.OAState = a.State
+	m.OACity = a.City
+	m.OAPostcode = a.PostalCode
+	m.OAStreetAddress1 = a.Line1
+	m.OAStreetAddress2 = a.Line2
+}
+
+// NormalizeAddresses 规范化收件人和发件人地址
+func (m *RateCalcRequest) NormalizeAddresses(normalize AddressNormalizer) error {
+	m.ShipperAddress = cloneShipperAddress(m.ShipperAddress)
+	recipient := m.Recipient()
+	if err := normalizeAddresses(normalize, &recipient, m.ShipperAddress, nil); err != nil {
+		return err
+	}
+	m.SetRecipient(recipient)
+	return nil
+}
+
+// SetShipper 设置发件人信息（地址编码为发件人编码，只有地址编码时不设置发件人地址）
+func (m *RateCalcRequest) SetShipper(a entity.Address) {
+	m.ShipperAddress = newShipperAddress(a)
+	m.ShipperCode = a.Code
+}
+
+// NewRateCalcRequest 根据创建订单请求生成运费试算请求（包裹只保留尺寸和重量）
+func NewRateCalcRequest(req CreateOrderRequest) RateCalcRequest {
+	rateReq := RateCalcRequest{
+		ReferenceNO:      req.ReferenceNO,
+		SMCode:           req.SMCode,
+		Remark:           req.Remark,
+		IsMoreBox:        req.IsMoreBox,
+		SignatureService: req.SignatureService,
+		PickUp:           req.PickUp,
+		WeightUnitType:   req.WeightUnitType,
+		ShipperAddress:   cloneShipperAddress(req.ShipperAddress),
+		ShipperCode:      req.ShipperCode,
+	}
+	rateReq.SetRecipient(req.Recipient())
+	if req.BoxList != nil {
+		rateReq.BoxList = make([]RateCalcOrderBox, len(req.BoxList))
+		for i, box := range req.BoxList {
+			rateReq.BoxList[i] = RateCalcOrderBox{
+				Length:       box.Length,
+				Width:        box.Width,
+				Height:       box.Height,
+				ActualWeight: box.ActualWeight,
+			}
+		}
+	}
+	return rateReq
+}
+
+// NewCreateOrderRequest 根据运费试算请求生成创建订单请求（包裹的申报等信息、退件地址和面单设置需要另行填写）
+func NewCreateOrderRequest(req RateCalcRequest) CreateOrderRequest {
+	orderReq := CreateOrderRequest{
+		ReferenceNO:      req.ReferenceNO,
+		SMCode:           req.SMCode,
+		Remark:           req.Remark,
+		IsMoreBox:        req.IsMoreBox,
+		SignatureService: req.SignatureService,
+		PickUp:           req.PickUp,
+		WeightUnitType:   req.WeightUnitType,
+		ShipperAddress:   cloneShipperAddress(req.ShipperAddress),
+		ShipperCode:      req.ShipperCode,
+	}
+	orderReq.SetRecipient(req.Recipient())
+	if req.BoxList != nil {
+		orderReq.BoxList = make([]OrderBox, len(req.BoxList))
+		for i, box := range req.BoxList {
+			orderReq.BoxList[i] = OrderBox{
+				Length:       box.Length,
+				Width:        box.Width,
+				Height:       box.Height,
+				ActualWeight: box.ActualWeight,
+			}
+		}
+	}
+	return orderReq
+}
+
+// newShipperAddress 转换为发件人地址，只有地址编码时返回 nil（发件人地址会覆盖发件人编码）
+func newShipperAddress(a entity.Address) *entity.ShipperAddress {
+	if a == (entity.Address{Code: a.Code}) {
+		return nil
+	}
+	shipper := a.ShipperAddress()
+	return &shipper
+}
+
+func cloneShipperAddress(a *entity.ShipperAddress) *entity.ShipperAddress {
+	if a == nil {
+		return nil
+	}
+	clone := *a
+	return &clone
+}
@@ -0,0 +1,189 @@
+package mazon
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/hiscaler/mazon-go/usaddress"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRecipient = entity.Address{
+	Name:       "John Doe",
+	Company:    "Apple Inc.",
+	Phone:      "9095550100",
+	Country:    "US",
+	State:      "CA",
+	City:       "Ontario",
+	PostalCode: "91761-1234",
+	Line1:      "2078 E Francis Street",
+	Line2:      "Suite 100",
+}
+
+func TestCreateOrderRequest_Recipient(t *testing.T) {
+	var req CreateOrderRequest
+	req.SetRecipient(testRecipient)
+	assert.Equal(t, "John Doe", req.OAFirstname)
+	assert.Equal(t, "91761-1234", req.OAPostcode)
+	assert.Equal(t, testRecipient, req.Recipient())
+
+	req.SetShipper(entity.Address{Code: "S0004", Name: "Mazon Test", Country: "US"})
+	assert.Equal(t, "S0004", req.ShipperCode)
+	assert.Equal(t, "Mazon Test", req.ShipperAddress.ShipperName)
+	req.SetReturn(testRecipient)
+	assert.Equal(t, "John", req.ReturnAddress.FirstName)
+	assert.Equal(t, "91761-1234", req.ReturnAddress.ZipCodeAndPlus4)
+
+	// 只有发件人编码
+	req.SetShipper(entity.Address{Code: "S0004"})
+	assert.Equal(t, "S0004", req.ShipperCode)
+	assert.Nil(t, req.ShipperAddress)
+}
+
+func TestSetShipper_Code(t *testing.T) {
+	req := CreateOrderRequest{ReferenceNO: "business-id", SMCode: "USPS GA13", IsMoreBox: 1}
+	req.SetRecipient(entity.Address{Name: "John", Phone: "9095550100", Country: "US", State: "CA", City: "Ontario", PostalCode: "91761", Line1: "2025 D Francis Street"})
+	req.SetShipper(entity.Address{Code: "S0004"})
+	req.BoxList = []OrderBox{{Length: 1, Width: 1, Height: 1, ActualWeight: 1, Sku: "MKG001", CnName: "马克杯", EngName: "Mug"}}
+	assert.Nil(t, req.Validate())
+
+	rateReq := NewRateCalcRequest(req)
+	assert.Nil(t, rateReq.ShipperAddress)
+	assert.Nil(t, rateReq.Validate())
+
+	rateReq = RateCalcRequest{}
+	rateReq.SetShipper(entity.Address{Code: "S0004"})
+	assert.Equal(t, "S0004", rateReq.ShipperCode)
+	assert.Nil(t, rateReq.ShipperAddress)
+}
+
+// jsonFields 返回结构体的 JSON 字段值（以 JSON 名称为键）
+func jsonFields(t *testing.T, v any) map[string]any {
+	b, err := json.Marshal(v)
+	assert.Nil(t, err)
+	var fields map[string]any
+	assert.Nil(t, json.Unmarshal(b, &fields))
+	return fields
+}
+
+// fill 将结构体中所有的字符串、数字字段设置为非零值
+func fill(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(v.Type().Field(i).Name)
+		case reflect.Int:
+			f.SetInt(int64(i + 1))
+		case reflect.Float64:
+			f.SetFloat(float64(i) + 0.5)
+		}
+	}
+}
+
+func TestNewRateCalcRequest(t *testing.T) {
+	var req CreateOrderRequest
+	fill(reflect.ValueOf(&req).Elem())
+	req.BoxList = []OrderBox{{Length: 1, Width: 2, Height: 3, ActualWeight: 4, Sku: "SKU-1"}}
+	req.ShipperAddress = &entity.ShipperAddress{ShipperCode: "S0004"}
+
+	// 运费试算请求的所有字段都从创建订单请求中复制
+	rateReq := NewRateCalcRequest(req)
+	orderFields := jsonFields(t, req)
+	rateFields := jsonFields(t, rateReq)
+	for name, value := range rateFields {
+		if name != "box_list" {
+			assert.Equal(t, orderFields[name], value, name)
+		}
+	}
+	assert.Equal(t, []RateCalcOrderBox{{Length: 1, Width: 2, Height: 3, ActualWeight: 4}}, rateReq.BoxList)
+	assert.Equal(t, req.Recipient(), rateReq.Recipient())
+	assert.NotSame(t, req.ShipperAddress, rateReq.ShipperAddress)
+
+	orderReq := NewCreateOrderRequest(rateReq)
+	for name, value := range jsonFields(t, orderReq) {
+		if name != "box_list" && name != "return_address" {
+			assert.Equal(t, orderFields[name], value, name)
+		}
+	}
+	assert.Equal(t, []OrderBox{{Length: 1, Width: 2, Height: 3, ActualWeight: 4}}, orderReq.BoxList)
+}
+
+func TestWithAddressNormalizer(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	c := NewClient(ctx, s.Config(), WithTokenStore(NewMemoryTokenStore()), WithAddressNormalizer(usaddress.Normalize))
+
+	returnAddress := &entity.ReturnAddress{
+		StreetAddress:   "100 Main St.",
+		ZipCodeAndPlus4: "751152500",
+		City:            "Dallas",
+		State:           "texas",
+		FirstName:       "Mary Ann",
+	}
+	req := CreateOrderRequest{
+		ReferenceNO:   "TEST-NORMALIZE",
+		SMCode:        "USPS GA13",
+		IsMoreBox:     1,
+		ShipperCode:   mazontest.ShipperCode,
+		BoxList:       []OrderBox{{Length: 1, Width: 1, Height: 1, ActualWeight: 1}},
+		ReturnAddress: returnAddress,
+	}
+	req.SetRecipient(entity.Address{
+		Name:       "John Doe",
+		Phone:      "+1 909-555-0100",
+		Country:    "USA",
+		State:      "California",
+		City:       "Ontario",
+		PostalCode: "91761",
+		Line1:      "2078 E. Francis Street, Building 4, Dock Door 12",
+	})
+
+	// 运费试算
+	_, err := c.Services.Rate.Calc(ctx, NewRateCalcRequest(req))
+	assert.Nil(t, err)
+
+	res, err := c.Services.Order.Create(ctx, req)
+	assert.Nil(t, err)
+	o, ok := s.Order(res.OrderCode)
+	if assert.True(t, ok) {
+		assert.Equal(t, "CA", o.State)
+		assert.Equal(t, "US", o.Country)
+		assert.Equal(t, "2078 E Francis Street Building 4", o.StreetAddress1)
+	}
+	// 不修改调用方的地址
+	assert.Equal(t, "texas", returnAddress.State)
+
+	// 规范化错误转换为请求字段的验证错误
+	req.ReferenceNO = "TEST-NORMALIZE-2"
+	req.OAState = "Calif"
+	returnAddress.ZipCodeAndPlus4 = "751152500"
+	returnAddress.Phone = "+1 972 555 0100 ext 12"
+	_, err = c.Services.Order.Create(ctx, req)
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		_, ok = validationErr.Field("$.oa_state")
+		assert.True(t, ok)
+		_, ok = validationErr.Field("return_address.zip_code_and_plus4")
+		assert.False(t, ok)
+	}
+
+	returnAddress.ZipCodeAndPlus4 = "751"
+	_, err = c.Services.Order.Create(WithLocale(ctx, LocaleEnUS), req)
+	if assert.True(t, errors.As(err, &validationErr)) {
+		field, ok := validationErr.Field("$.return_address.zip_code_and_plus4")
+		if assert.True(t, ok) {
+			assert.Equal(t, "validation_us_zip_invalid", field.Code)
+			assert.Equal(t, "Invalid ZIP code 751", field.Message)
+		}
+		field, ok = validationErr.Field("$.oa_state")
+		if assert.True(t, ok) {
+			assert.Equal(t, "validation_us_state_invalid", field.Code)
+			assert.Equal(t, "Invalid state Calif", field.Message)
+		}
+	}
+}
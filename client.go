@@ -8,13 +8,15 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-resty/resty/v2"
-	"github.com/hiscaler/aar"
 	"github.com/hiscaler/mazon-go/config"
 	"github.com/hiscaler/mazon-go/entity"
 )
@@ -29,21 +31,91 @@ const (
 const (
 	Version   = "0.0.1"
 	userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/143.0.0.0 Safari/537.36"
-	baseUrl   = "https://api.mazonlabel.com/api/svc" // 美正无测试地址
 )
 
 type Client struct {
 	config     *config.Config // 配置
 	httpClient *resty.Client  // Resty Client
-	retry      bool           // 是否重新发起请求，如果是重新发起的，需要重新获取 token
+	tokenStore TokenStore     // Token 存储
+	tokenMu    sync.Mutex     // 保护 tokenCall
+	tokenCall  *tokenCall     // 正在进行中的 Token 刷新
 	logger     *logger
-	Services   services // API Services
+	normalizer AddressNormalizer // 地址规范化
+	rateCache  *rateCache        // 运费试算结果缓存
+	limiter    *Limiter          // 请求频率和并发限制
+	Services   services          // API Services
 }
 
-func NewClient(ctx context.Context, cfg config.Config) *Client {
+// Option 客户端选项
+type Option func(c *Client)
+
+// WithTokenStore 设置 Token 存储，不设置的情况下默认使用 aar 存储
+func WithTokenStore(store TokenStore) Option {
+	return func(c *Client) {
+		c.tokenStore = store
+	}
+}
+
+// WithAddressNormalizer 设置地址规范化，创建订单和运费试算之前将使用 normalize 规范化收件人、发件人和退件地址，比如：
+//
+//	client := NewClient(ctx, cfg, WithAddressNormalizer(usaddress.Normalize))
+func WithAddressNormalizer(normalize AddressNormalizer) Option {
+	return func(c *Client) {
+		c.normalizer = normalize
+	}
+}
+
+// WithRateCache 设置运费试算结果缓存，相同的试算请求（忽略订单参考号和备注，见 RateCacheKey）在 ttl（小于等于 0 时为 DefaultRateCacheTTL）内直接返回缓存的结果，
+// 同时进行的相同请求只会调用一次试算接口，比如：
+//
+//	client := NewClient(ctx, cfg, WithRateCache(NewMemoryRateCache(1000), 5*time.Minute))
+func WithRateCache(store RateCache, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.rateCache = newRateCache(store, ttl, c.config.AppKey)
+	}
+}
+
+// WithLimiter 设置请求频率和并发限制，发送到接口地址的请求（包括获取 Token 和重试）在发送之前会等待限制器，
+// 使用同一个 Limiter 的客户端共用限制，等待统计可以通过 Limiter.Stats 获取。
+func WithLimiter(limiter *Limiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithRateLimit 按 AppKey 设置请求频率和并发限制，同一个进程中相同 AppKey 的客户端共用限制（见 SharedLimiter），比如：
+//
+//	client := NewClient(ctx, cfg, WithRateLimit(RateLimit{PerSecond: 10, MaxInFlight: 4}))
+func WithRateLimit(limit RateLimit) Option {
+	return func(c *Client) {
+		c.limiter = SharedLimiter(c.config.AppKey, limit)
+	}
+}
+
+func NewClient(ctx context.Context, cfg config.Config, opts ...Option) *Client {
 	l := createLogger()
 	mazonClient := &Client{
 		config: &cfg,
+		logger: l,
+	}
+	for _, opt := range opts {
+		opt(mazonClient)
+	}
+	if mazonClient.tokenStore == nil {
+		mazonClient.tokenStore = NewAarTokenStore(mazonClient.tokenDuration())
+	}
+	baseUrl := cfg.Endpoint()
+	if baseUrl == "" {
+		l.l.ErrorContext(ctx, "Invalid environment", "environment", cfg.Environment)
+	}
+	var transport http.RoundTripper = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+		}).DialContext,
+	}
+	if mazonClient.limiter != nil {
+		transport = newLimitTransport(transport, mazonClient.limiter, baseUrl)
 	}
 	httpClient := resty.New().
 		SetDebug(cfg.Debug).
@@ -54,37 +126,20 @@ func NewClient(ctx context.Context, cfg config.Config) *Client {
 			"Accept":       "application/json",
 			"User-Agent":   userAgent,
 		}).
-		SetTransport(&http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			DialContext: (&net.Dialer{
-				Timeout: 10 * time.Second,
-			}).DialContext,
-		}).
+		SetTransport(transport).
 		SetTimeout(time.Duration(cfg.Timeout) * time.Second).
 		OnBeforeRequest(func(client *resty.Client, request *resty.Request) error {
-			var token string
-			ar, err := aar.New("mazon.access.token.%s.%s", cfg.AppKey, cfg.AppToken)
-			if err == nil {
-				token, _ = ar.SetDuration(time.Duration(min(max(cfg.TokenDuration, 1), 4)) * time.Hour).Read()
+			// 未指定语言时使用配置中的语言
+			if _, ok := request.Context().Value(localeKey{}).(Locale); !ok {
+				request.SetContext(WithLocale(request.Context(), ParseLocale(cfg.Locale)))
 			}
-			if token == "" || mazonClient.retry {
-				// 重新获取 Token
-				msg := ""
-				if token == "" {
-					msg = "token is empty"
-				} else if mazonClient.retry {
-					msg = "token expired and retry"
-				}
-				l.l.InfoContext(ctx, "Get access token", "why", msg)
-				if token, err = mazonClient.getAccessToken(ctx); err != nil {
-					l.l.ErrorContext(ctx, "Get access token", "error", err)
-					return err
-				}
-				if err = ar.Write([]byte(token)); err != nil {
-					l.l.ErrorContext(ctx, "Write token to cache", "error", err)
-				}
+			// 上一次请求返回了 401 时，会在请求的 context 中记录当时使用的 Token
+			stale, _ := request.Context().Value(staleTokenKey{}).(string)
+			token, err := mazonClient.accessToken(request.Context(), stale)
+			if err != nil {
+				return err
 			}
-			client.SetHeader("Authorization", token)
+			request.SetHeader("Authorization", token)
 			return nil
 		}).
 		OnAfterResponse(func(client *resty.Client, response *resty.Response) error {
@@ -101,21 +156,30 @@ func NewClient(ctx context.Context, cfg config.Config) *Client {
 		SetRetryWaitTime(2 * time.Second).
 		SetRetryMaxWaitTime(10 * time.Second).
 		AddRetryCondition(func(response *resty.Response, err error) bool {
+			if errors.Is(err, ErrRateLimited) {
+				// 请求没有发送，重试也会被取消
+				return false
+			}
 			if response == nil {
 				return true
 			}
 			var r NormalResponse
-			retry := json.Unmarshal(response.Body(), &r) == nil && r.Code == InvalidToken
-			mazonClient.retry = retry
-			return retry
+			if json.Unmarshal(response.Body(), &r) != nil || r.Code != InvalidToken {
+				return false
+			}
+			// 仅当前请求需要刷新 Token
+			request := response.Request
+			request.SetContext(context.WithValue(request.Context(), staleTokenKey{}, request.Header.Get("Authorization")))
+			return true
 		})
 	mazonClient.httpClient = httpClient
-	mazonClient.logger = l
 
 	xService := service{
 		config:     &cfg,
 		logger:     l.l,
 		httpClient: mazonClient.httpClient,
+		normalizer: mazonClient.normalizer,
+		rateCache:  mazonClient.rateCache,
 	}
 	mazonClient.Services = services{
 		Order:         (orderService)(xService),
@@ -133,28 +197,120 @@ type NormalResponse struct {
 	Result  any    `json:"result"`
 }
 
-// accessToken 获取 Access Token 值
+// tokenKey Token 存储键名
+func (c *Client) tokenKey() string {
+	return fmt.Sprintf("mazon.access.token.%s.%s", c.config.AppKey, c.config.AppToken)
+}
+
+// tokenDuration Token 有效期（1 ~ 4 小时）
+func (c *Client) tokenDuration() time.Duration {
+	return time.Duration(min(max(c.config.TokenDuration, 1), 4)) * time.Hour
+}
+
+// staleTokenKey 请求 context 中记录已失效 Token 的键
+type staleTokenKey struct{}
+
+// tokenCall 正在进行中的 Token 刷新，等待者共享刷新结果
+type tokenCall struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// accessToken 获取可用的 Access Token
+//
+// stale 为已经被服务端判定为无效的 Token，为空表示不是因为 Token 失效而重试的请求。
+// 同一时间只会有一个刷新请求在执行，并发的调用方会等待并共享该刷新结果。
+func (c *Client) accessToken(ctx context.Context, stale string) (string, error) {
+	token, err := c.tokenStore.Get(ctx, c.tokenKey())
+	if err != nil {
+		c.logger.l.ErrorContext(ctx, "Read token from store", "error", err)
+	}
+	if token != "" && token != stale {
+		return token, nil
+	}
+
+	c.tokenMu.Lock()
+	if call := c.tokenCall; call != nil {
+		c.tokenMu.Unlock()
+		select {
+		case <-call.done:
+			return call.token, call.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	call := &tokenCall{done: make(chan struct{})}
+	c.tokenCall = call
+	c.tokenMu.Unlock()
+
+	// 刷新结果由所有等待者共享，不能因为发起者的请求被取消而中断
+	call.token, call.err = c.refreshAccessToken(context.WithoutCancel(ctx), stale)
+
+	c.tokenMu.Lock()
+	c.tokenCall = nil
+	c.tokenMu.Unlock()
+	close(call.done)
+	return call.token, call.err
+}
+
+// refreshAccessToken 重新获取 Token 并写入存储
+func (c *Client) refreshAccessToken(ctx context.Context, stale string) (string, error) {
+	key := c.tokenKey()
+	// 可能已经被其他请求（或者共享存储的其他实例）刷新过了
+	token, err := c.tokenStore.Get(ctx, key)
+	if err != nil {
+		c.logger.l.ErrorContext(ctx, "Read token from store", "error", err)
+	}
+	if token != "" && token != stale {
+		return token, nil
+	}
+
+	msg := "token is empty"
+	if stale != "" {
+		msg = "token expired and retry"
+		if err = c.tokenStore.Invalidate(ctx, key); err != nil {
+			c.logger.l.ErrorContext(ctx, "Invalidate token", "error", err)
+		}
+	}
+	c.logger.l.InfoContext(ctx, "Get access token", "why", msg)
+	if token, err = c.getAccessToken(ctx); err != nil {
+		c.logger.l.ErrorContext(ctx, "Get access token", "error", err)
+		return "", err
+	}
+	if err = c.tokenStore.Set(ctx, key, token, c.tokenDuration()); err != nil {
+		c.logger.l.ErrorContext(ctx, "Write token to store", "error", err)
+	}
+	return token, nil
+}
+
+// getAccessToken 从接口获取 Access Token 值
 func (c *Client) getAccessToken(ctx context.Context) (string, error) {
 	result := struct {
 		NormalResponse
 		Result *entity.Token `json:"result"`
 	}{}
+	var transport http.RoundTripper = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+		}).DialContext,
+	}
+	// 获取 Token 也计入请求限制
+	if c.limiter != nil {
+		transport = newLimitTransport(transport, c.limiter, c.config.Endpoint())
+	}
 	httpClient := resty.New().
 		SetDebug(c.config.Debug).
-		SetBaseURL(baseUrl).
+		SetBaseURL(c.config.Endpoint()).
 		SetHeaders(map[string]string{
 			"Content-Type": "application/json",
 			"Accept":       "application/json",
 			"User-Agent":   userAgent,
 		}).
-		SetTransport(&http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			DialContext: (&net.Dialer{
-				Timeout: 10 * time.Second,
-			}).DialContext,
-		})
+		SetTransport(transport)
 	resp, err := httpClient.R().
-		SetContext(context.Background()).
+		SetContext(ctx).
 		SetBody(map[string]string{
 			"app_key":   c.config.AppKey,
 			"app_token": c.config.AppToken,
@@ -173,7 +329,10 @@ func (c *Client) getAccessToken(ctx context.Context) (string, error) {
 		}
 	}
 	if err = recheckError(resp, result.NormalResponse, err); err != nil {
-		return "", nil
+		return "", err
+	}
+	if result.Result == nil || result.Result.AccessToken == "" {
+		return "", errorWrap(InvalidToken, "")
 	}
 	return result.Result.AccessToken, nil
 }
@@ -195,68 +354,125 @@ func errorWrap(code int, message string) error {
 		} else {
 			message = strings.TrimSpace(message)
 			if message == "" {
-				message = "Unknown error"
+				message = "未知错误"
 			}
 		}
 	}
-	return fmt.Errorf("%d: %s", code, message)
+	return &APIError{Code: code, Message: message}
 }
 
-func invalidInput(e error) error {
+func invalidInput(locale Locale, e error) error {
 	var errs validation.Errors
 	if !errors.As(e, &errs) {
 		return e
 	}
 
-	if len(errs) == 0 {
+	fields := fieldErrors(locale, "", "$", errs)
+	if len(fields) == 0 {
 		return nil
 	}
+	return &ValidationError{Fields: fields}
+}
 
-	fields := make([]string, 0)
-	messages := make([]string, 0)
-	for field := range errs {
-		fields = append(fields, field)
+// fieldErrors 展开（嵌套的）验证错误
+//
+// 字段名称使用 . 连接（比如 box_list.0.box_length），JSONPath 格式为 $.box_list[0].box_length
+func fieldErrors(locale Locale, field, jsonPath string, errs validation.Errors) []FieldError {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
 	}
-	sort.Strings(fields)
+	// 数组下标按照数字大小排序
+	sort.Slice(names, func(i, j int) bool {
+		a, errA := strconv.Atoi(names[i])
+		b, errB := strconv.Atoi(names[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
 
-	for _, field := range fields {
-		e1 := errs[field]
-		if e1 == nil {
+	fields := make([]FieldError, 0, len(errs))
+	for _, name := range names {
+		e := errs[name]
+		if e == nil {
 			continue
 		}
 
-		var errObj validation.ErrorObject
-		if errors.As(e1, &errObj) {
-			e1 = errObj
+		f, p := name, jsonPath
+		if field != "" {
+			f = field + "." + name
+		}
+		if _, err := strconv.Atoi(name); err == nil {
+			p += "[" + name + "]"
 		} else {
-			var errs1 validation.Errors
-			if errors.As(e1, &errs1) {
-				e1 = invalidInput(errs1)
-				if e1 == nil {
-					continue
-				}
-			}
+			p += "." + name
+		}
+		var errObj validation.ErrorObject
+		isErrObj := errors.As(e, &errObj)
+		var nestedErrs validation.Errors
+		if !isErrObj && errors.As(e, &nestedErrs) {
+			fields = append(fields, fieldErrors(locale, f, p, nestedErrs)...)
+			continue
 		}
 
-		messages = append(messages, e1.Error())
+		fieldErr := FieldError{Field: f, JSONPath: p, Message: e.Error()}
+		if isErrObj {
+			fieldErr.Code = errObj.Code()
+			fieldErr.Message = translateErrorObject(locale, errObj).Error()
+		}
+		fields = append(fields, fieldErr)
 	}
-	return errors.New(strings.Join(messages, "; "))
+	return fields
+}
+
+// isTimeout 是否为超时错误
+func isTimeout(e error) bool {
+	if errors.Is(e, http.ErrHandlerTimeout) || errors.Is(e, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(e, &netErr) && netErr.Timeout()
 }
 
 func recheckError(resp *resty.Response, result NormalResponse, e error) error {
+	apiError := func(err error) error {
+		var apiErr *APIError
+		if resp != nil && errors.As(err, &apiErr) {
+			apiErr.HTTPStatus = resp.StatusCode()
+			apiErr.RawBody = resp.Body()
+			if resp.Request != nil {
+				// 请求的 context 中已经设置了语言（参见 OnBeforeRequest）
+				apiErr.Message = translate(localeOf(resp.Request.Context(), nil), apiErr.Message)
+				if resp.Request.RawRequest != nil {
+					// 美正的接口都位于同一级目录下，只保留接口名称
+					apiErr.Endpoint = "/" + path.Base(resp.Request.RawRequest.URL.Path)
+				}
+			}
+		}
+		return err
+	}
+
 	if e != nil {
-		if errors.Is(e, http.ErrHandlerTimeout) {
-			return errorWrap(http.StatusRequestTimeout, e.Error())
+		if errors.Is(e, ErrRateLimited) {
+			return e
+		}
+		if isTimeout(e) {
+			return apiError(&APIError{Code: http.StatusRequestTimeout, Message: "请求超时", Err: e})
 		}
 		return e
 	}
 
 	if resp.IsError() {
-		return errorWrap(resp.StatusCode(), resp.Error().(string))
+		message := strings.TrimSpace(resp.String())
+		if message == "" {
+			message = http.StatusText(resp.StatusCode())
+		}
+		return apiError(errorWrap(resp.StatusCode(), message))
 	}
 
 	if result.Code != http.StatusOK {
-		return errorWrap(result.Code, result.Message)
+		return apiError(errorWrap(result.Code, result.Message))
 	}
 	return nil
 }
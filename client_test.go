@@ -5,29 +5,51 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hiscaler/mazon-go/config"
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
 	"github.com/stretchr/testify/assert"
 )
 
 var client *Client
 var ctx context.Context
+var mockServer *mazontest.Server
 
+// TestMain 默认使用 mazontest 模拟服务进行测试，设置环境变量 MAZON_LIVE=1 时使用 config.json 中的配置请求美正接口
 func TestMain(m *testing.M) {
-	b, err := os.ReadFile("./config/config.json")
-	if err != nil {
-		panic(fmt.Sprintf("Read config error: %s", err.Error()))
-	}
 	var cfg config.Config
-	err = json.Unmarshal(b, &cfg)
-	if err != nil {
-		panic(fmt.Sprintf("Parse config file error: %s", err.Error()))
+	if os.Getenv("MAZON_LIVE") == "1" {
+		b, err := os.ReadFile("./config/config.json")
+		if err != nil {
+			panic(fmt.Sprintf("Read config error: %s", err.Error()))
+		}
+		err = json.Unmarshal(b, &cfg)
+		if err != nil {
+			panic(fmt.Sprintf("Parse config file error: %s", err.Error()))
+		}
+	} else {
+		mockServer = mazontest.NewServer()
+		mockServer.AddOrder(mazontest.Order{
+			Order: entity.Order{
+				ReferenceNo: "TEST-ORDER",
+				OrderCode:   "EPB00120250912114236000021",
+			},
+			SMCode: "USPS GA13",
+		}, "9234690397703300025653")
+		cfg = mockServer.Config()
 	}
 
-	client = NewClient(ctx, cfg)
 	ctx = context.Background()
-	m.Run()
+	client = NewClient(ctx, cfg, WithTokenStore(NewMemoryTokenStore()))
+	code := m.Run()
+	if mockServer != nil {
+		mockServer.Close()
+	}
+	os.Exit(code)
 }
 
 func TestClient_GetAccessToken(t *testing.T) {
@@ -35,3 +57,30 @@ func TestClient_GetAccessToken(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotEmpty(t, token)
 }
+
+func TestClient_RefreshTokenConcurrently(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+
+	store := NewMemoryTokenStore()
+	c := NewClient(ctx, s.Config(), WithTokenStore(store))
+	_, err := c.Services.User.Information(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, s.Requests("/getToken"))
+
+	// 所有 Token 失效后并发请求，只允许刷新一次
+	s.ExpireTokens()
+	s.Inject(mazontest.Fault{Path: "/getToken", Latency: 50 * time.Millisecond})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := c.Services.User.Information(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, mazontest.CustomerCode, info.Code)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, s.Requests("/getToken"))
+}
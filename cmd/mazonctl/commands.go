@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/entity"
+	"gopkg.in/yaml.v3"
+)
+
+// newFlagSet 创建子命令的参数解析器
+func newFlagSet(a *app, name, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet("mazonctl "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "用法：mazonctl %s %s\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags 解析子命令参数（解析失败时 flag 已经输出了错误信息和用法）
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{}
+	}
+	return nil
+}
+
+// readRequest 读取请求文件，根据扩展名解析 JSON 或者 YAML（.yaml、.yml）格式，字段名称与 JSON 相同
+func readRequest(filename string, v any) error {
+	if filename == "" {
+		return usagef("请使用 -file 指定请求文件")
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		var m any
+		if err = yaml.Unmarshal(b, &m); err != nil {
+			return fmt.Errorf("解析请求文件 %s 失败：%w", filename, err)
+		}
+		if b, err = json.Marshal(m); err != nil {
+			return fmt.Errorf("解析请求文件 %s 失败：%w", filename, err)
+		}
+	}
+	// 不允许未知字段，避免字段名称拼写错误时被忽略
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err = d.Decode(v); err != nil {
+		return fmt.Errorf("解析请求文件 %s 失败：%w", filename, err)
+	}
+	return nil
+}
+
+// orderFlags 订单号和参考号参数
+func orderFlags(fs *flag.FlagSet) (orderCode, referenceNo *string) {
+	return fs.String("order-code", "", "订单号"), fs.String("reference-no", "", "参考号")
+}
+
+func userInfo(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "user info", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	info, err := a.client.Services.User.Information(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(info, func(t *tables) {
+		t.add([]string{"CODE", "BALANCE", "SM_CODE"}, []string{info.Code, info.Balance, strings.Join(info.SmCode, ", ")})
+		rows := make([][]string, len(info.Address))
+		for i, addr := range info.Address {
+			rows[i] = []string{
+				addr.ShipperCode,
+				addr.ShipperName,
+				strings.Join(nonEmpty(addr.ShipperAddress1, addr.ShipperAddress2, addr.ShipperCity, addr.ShipperStateProvince, addr.ShipperPostalCode, addr.ShipperCountry), ", "),
+			}
+		}
+		t.add([]string{"SHIPPER_CODE", "SHIPPER_NAME", "ADDRESS"}, rows...)
+	})
+}
+
+func rateCalc(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "rate calc", "-file request.json")
+	file := fs.String("file", "", "请求文件（RateCalcRequest，JSON 或者 YAML 格式）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var req mazon.RateCalcRequest
+	if err := readRequest(*file, &req); err != nil {
+		return err
+	}
+	result, err := a.client.Services.Rate.Calc(ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.print(result, func(t *tables) {
+		t.add([]string{"SM_CODE", "ADDRESS_TYPE", "SHIPPING", "TOTAL"},
+			[]string{result.SmCode, result.AddressTypeText, result.Shipping().String(), result.Total().String()},
+		)
+		rows := make([][]string, len(result.ChargeDetail))
+		for i, detail := range result.ChargeDetail {
+			rows[i] = []string{detail.FeeTypeCode, detail.ChargeDesc, detail.Amount.String()}
+		}
+		t.add([]string{"FEE_TYPE", "DESCRIPTION", "AMOUNT"}, rows...)
+	})
+}
+
+func rateShop(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "rate shop", "-file request.json [-signature SSF] [-exclude A,B] [物流产品]...")
+	file := fs.String("file", "", "请求文件（RateCalcRequest，JSON 或者 YAML 格式，不需要填写 sm_code）")
+	signature := fs.String("signature", "", "需要的签名服务（ASS、SSF）")
+	exclude := fs.String("exclude", "", "不参与比价的物流产品，多个使用逗号分隔")
+	concurrency := fs.Int("concurrency", 4, "同时试算的数量")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	req := mazon.RateShopRequest{
+		SMCodes:     fs.Args(),
+		Policy:      mazon.RateShopPolicy{Signature: *signature, Exclude: nonEmpty(strings.Split(*exclude, ",")...)},
+		Concurrency: *concurrency,
+	}
+	if err := readRequest(*file, &req.RateCalcRequest); err != nil {
+		return err
+	}
+	result, err := a.client.Services.Rate.Shop(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	errs := make(map[string]string, len(result.Errors))
+	for smCode, e := range result.Errors {
+		errs[smCode] = e.Error()
+	}
+	v := struct {
+		Selected entity.RateCalcResult   `json:"selected"`
+		Quotes   []entity.RateCalcResult `json:"quotes"`
+		Errors   map[string]string       `json:"errors"`
+	}{result.Selected, result.Quotes, errs}
+	return a.print(v, func(t *tables) {
+		rows := make([][]string, 0, len(result.Quotes)+len(errs))
+		for _, quote := range result.Quotes {
+			selected := ""
+			if quote.SmCode == result.Selected.SmCode {
+				selected = "*"
+			}
+			rows = append(rows, []string{selected, quote.SmCode, quote.AddressTypeText, quote.Shipping().String(), quote.Total().String(), ""})
+		}
+		smCodes := slices.Sorted(maps.Keys(errs))
+		for _, smCode := range smCodes {
+			rows = append(rows, []string{"", smCode, "", "", "", errs[smCode]})
+		}
+		t.add([]string{"SELECTED", "SM_CODE", "ADDRESS_TYPE", "SHIPPING", "TOTAL", "ERROR"}, rows...)
+	})
+}
+
+func orderCreate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "order create", "-file request.yaml")
+	file := fs.String("file", "", "请求文件（CreateOrderRequest，JSON 或者 YAML 格式）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var req mazon.CreateOrderRequest
+	if err := readRequest(*file, &req); err != nil {
+		return err
+	}
+	result, err := a.client.Services.Order.Create(ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.print(result, func(t *tables) {
+		total, _ := result.TotalFee()
+		t.add([]string{"ORDER_CODE", "LABEL_STATUS", "TOTAL_FEE", "MERGE_LABEL"},
+			[]string{result.OrderCode, fmt.Sprint(result.LabelStatus), total.String(), result.MergeLabel},
+		)
+		t.add(labelHeader, labelRows(result.Labels)...)
+	})
+}
+
+func orderQuery(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "order query", "-order-code X | -reference-no X | -from \"2023-06-01 00:00:00\" -to \"2023-06-02 00:00:00\"")
+	orderCode, referenceNo := orderFlags(fs)
+	from := fs.String("from", "", "开始时间（格式：2023-06-01 00:00:00）")
+	to := fs.String("to", "", "结束时间（格式：2023-06-01 00:00:00）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	req := mazon.OrderQueryRequest{OrderCode: *orderCode, ReferenceNo: *referenceNo, DateFrom: *from, DateTo: *to}
+	switch {
+	case req.DateFrom != "" || req.DateTo != "":
+		req.Type = 1
+	case req.OrderCode != "" || req.ReferenceNo != "":
+		req.Type = 2
+	default:
+		return usagef("请指定 -order-code、-reference-no 或者 -from、-to")
+	}
+	orders, err := a.client.Services.Order.Query(ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.print(orders, func(t *tables) {
+		rows := make([][]string, len(orders))
+		for i, o := range orders {
+			rows[i] = []string{o.ReferenceNo, o.OrderCode, o.OrderStatus.String(), o.AddTime, o.Firstname, strings.Join(nonEmpty(o.City, o.State, o.Postcode, o.Country), ", ")}
+		}
+		t.add([]string{"REFERENCE_NO", "ORDER_CODE", "STATUS", "ADD_TIME", "NAME", "ADDRESS"}, rows...)
+	})
+}
+
+func orderCancel(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "order cancel", "-order-code X | -reference-no X")
+	orderCode, referenceNo := orderFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	status, err := a.client.Services.Order.Cancel(ctx, mazon.CancelOrderRequest{OrderCode: *orderCode, ReferenceNo: *referenceNo})
+	if err != nil {
+		return err
+	}
+	result := struct {
+		OrderCode   string             `json:"order_code,omitempty"`
+		ReferenceNo string             `json:"reference_no,omitempty"`
+		Status      entity.OrderStatus `json:"status"`
+		StatusCode  string             `json:"status_code"`
+	}{*orderCode, *referenceNo, status, status.Code()}
+	return a.print(result, func(t *tables) {
+		t.add([]string{"ORDER_CODE", "REFERENCE_NO", "STATUS"}, []string{*orderCode, *referenceNo, status.String()})
+	})
+}
+
+func labelDetail(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "label detail", "-order-code X | -reference-no X")
+	orderCode, referenceNo := orderFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	label, err := a.client.Services.ShippingLabel.Detail(ctx, mazon.ShippingLabelDetailRequest{OrderCode: *orderCode, ReferenceNo: *referenceNo})
+	if err != nil {
+		return err
+	}
+	return a.print(label, func(t *tables) {
+		total, _ := label.TotalFee()
+		t.add([]string{"REFERENCE_NO", "ORDER_CODE", "STATUS", "TOTAL_FEE", "ERROR"},
+			[]string{label.ReferenceNo, label.OrderCode, label.StatusText(), total.String(), label.LogisticsErr},
+		)
+		t.add(labelHeader, labelRows(label.Labels)...)
+	})
+}
+
+func labelQuery(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "label query", "<物流单号>...")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usagef("请指定物流单号")
+	}
+	labels, err := a.client.Services.ShippingLabel.Query(ctx, fs.Args()...)
+	if err != nil {
+		return err
+	}
+	return a.print(labels, func(t *tables) {
+		rows := make([][]string, 0, len(labels))
+		for _, label := range labels {
+			for _, l := range label.Labels {
+				rows = append(rows, []string{label.ReferenceNo, label.OrderCode, l.TrackingNumber, l.FileType, l.LabelUrl})
+			}
+		}
+		t.add([]string{"REFERENCE_NO", "ORDER_CODE", "TRACKING_NUMBER", "FILE_TYPE", "LABEL_URL"}, rows...)
+	})
+}
+
+func labelDownload(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "label download", "[-dir labels] -order-code X | -reference-no X | <物流单号>...")
+	dir := fs.String("dir", "labels", "保存目录")
+	orderCode, referenceNo := orderFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	downloader := mazon.NewLabelDownloader(a.client, mazon.NewLocalLabelStorage(*dir))
+	var files mazon.LabelFiles
+	switch {
+	case *orderCode != "" || *referenceNo != "":
+		label, err := a.client.Services.ShippingLabel.Detail(ctx, mazon.ShippingLabelDetailRequest{OrderCode: *orderCode, ReferenceNo: *referenceNo})
+		if err != nil {
+			return err
+		}
+		files, err = downloader.DownloadShippingLabel(ctx, label)
+		if err != nil {
+			return err
+		}
+	case fs.NArg() != 0:
+		labels, err := a.client.Services.ShippingLabel.Query(ctx, fs.Args()...)
+		if err != nil {
+			return err
+		}
+		files, err = downloader.DownloadLogisticsLabels(ctx, labels...)
+		if err != nil {
+			return err
+		}
+	default:
+		return usagef("请指定 -order-code、-reference-no 或者物流单号")
+	}
+	return a.print(files, func(t *tables) {
+		t.add(labelFileHeader, labelFileRows(files)...)
+	})
+}
+
+func scanFormCreate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "scanform create", "[-dir scanforms] <物流单号>...")
+	dir := fs.String("dir", "", "保存目录，指定后下载 ScanForm 文件")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usagef("请指定物流单号")
+	}
+	forms, err := a.client.Services.ScanForm.Create(ctx, fs.Args()...)
+	if err != nil {
+		return err
+	}
+	if *dir == "" {
+		return a.print(forms, func(t *tables) {
+			rows := make([][]string, len(forms))
+			for i, form := range forms {
+				rows[i] = []string{form.Url}
+			}
+			t.add([]string{"SCANFORM_URL"}, rows...)
+		})
+	}
+
+	files, err := mazon.NewLabelDownloader(a.client, mazon.NewLocalLabelStorage(*dir)).DownloadScanForms(ctx, forms...)
+	if err != nil {
+		return err
+	}
+	return a.print(files, func(t *tables) {
+		t.add(labelFileHeader, labelFileRows(files)...)
+	})
+}
+
+var labelHeader = []string{"TRACKING_NUMBER", "FILE_TYPE", "LABEL_URL"}
+
+func labelRows(labels []entity.Label) [][]string {
+	rows := make([][]string, len(labels))
+	for i, l := range labels {
+		rows[i] = []string{l.TrackingNumber, l.FileType, l.LabelUrl}
+	}
+	return rows
+}
+
+var labelFileHeader = []string{"KIND", "ORDER_CODE", "TRACKING_NUMBER", "FORMAT", "SIZE", "LOCATION"}
+
+func labelFileRows(files mazon.LabelFiles) [][]string {
+	rows := make([][]string, len(files))
+	for i, f := range files {
+		rows[i] = []string{string(f.Kind), f.OrderCode, f.TrackingNumber, f.Format, fmt.Sprint(f.Size), f.Location}
+	}
+	return rows
+}
+
+// nonEmpty 返回非空的值
+func nonEmpty(values ...string) []string {
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			s = append(s, v)
+		}
+	}
+	return s
+}
@@ -0,0 +1,239 @@
+// mazonctl 美正接口命令行工具，覆盖用户、运费、订单、面单和 ScanForm 接口
+//
+// 用法：
+//
+//	mazonctl [-config config.json] [-output json|table] <命令> [参数]
+//
+// 运行 mazonctl -h 查看所有命令。配置文件与 config/config.json 格式相同，结果以 JSON 或者表格输出。
+//
+// 退出码：0 成功，1 其他错误，2 命令或参数错误，3 请求参数验证失败，4 无效的 Token，5 请求错误，6 请求超时，7 美正内部错误
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/config"
+)
+
+// 退出码
+const (
+	exitOK         = 0 // 成功
+	exitError      = 1 // 其他错误
+	exitUsage      = 2 // 命令或参数错误
+	exitValidation = 3 // 请求参数验证失败
+	exitAuth       = 4 // 无效的 Token（请检查 AppKey、AppToken）
+	exitBadRequest = 5 // 请求错误
+	exitTimeout    = 6 // 请求超时
+	exitInternal   = 7 // 美正内部错误
+)
+
+// 输出格式
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// usageError 命令或参数错误，message 为空表示错误信息已经输出
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func usagef(format string, a ...any) error {
+	return &usageError{message: fmt.Sprintf(format, a...)}
+}
+
+const usage = `用法：mazonctl [-config config.json] [-output json|table] <命令> [参数]
+
+命令：
+  user info                                     用户信息
+  rate calc -file request.json                  运费试算（支持 JSON、YAML 文件）
+  rate shop -file request.json [物流产品]...    运费比价，默认使用所有开通的物流产品
+  order create -file request.yaml               创建订单（支持 JSON、YAML 文件）
+  order query -order-code X | -reference-no X   查询订单，也可以使用 -from、-to 按时间查询
+  order cancel -order-code X | -reference-no X  取消订单
+  label detail -order-code X | -reference-no X  面单详情
+  label query <物流单号>...                       查询面单
+  label download -dir labels -order-code X      下载面单，也可以指定 -reference-no 或者物流单号
+  scanform create <物流单号>...                   创建 ScanForm
+
+全局参数：
+`
+
+// app 命令执行环境
+type app struct {
+	client *mazon.Client
+	output string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command 子命令
+type command func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]map[string]command{
+	"user": {
+		"info": userInfo,
+	},
+	"rate": {
+		"calc": rateCalc,
+		"shop": rateShop,
+	},
+	"order": {
+		"create": orderCreate,
+		"query":  orderQuery,
+		"cancel": orderCancel,
+	},
+	"label": {
+		"detail":   labelDetail,
+		"query":    labelQuery,
+		"download": labelDownload,
+	},
+	"scanform": {
+		"create": scanFormCreate,
+	},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run 执行命令并返回退出码
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mazonctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	configFile := fs.String("config", "config.json", "配置文件（与 config/config.json 格式相同）")
+	output := fs.String("output", outputTable, "输出格式（json、table）")
+	tokenDir := fs.String("token-dir", "", "Token 存储目录，默认为系统临时目录")
+	verbose := fs.Bool("verbose", false, "输出请求日志")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	err := func() error {
+		if *output != outputJSON && *output != outputTable {
+			return usagef("无效的输出格式 %q", *output)
+		}
+		if fs.NArg() < 2 {
+			return usagef("缺少命令")
+		}
+		cmd, ok := commands[fs.Arg(0)][fs.Arg(1)]
+		if !ok {
+			return usagef("未知的命令 %q", fs.Arg(0)+" "+fs.Arg(1))
+		}
+
+		cfg, err := loadConfig(*configFile)
+		if err != nil {
+			return err
+		}
+		// 客户端默认使用 slog.Default() 输出每个请求的日志
+		level := slog.LevelWarn
+		if *verbose {
+			level = slog.LevelInfo
+		} else {
+			cfg.Debug = false
+		}
+		slog.SetDefault(slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level})))
+
+		a := &app{
+			client: mazon.NewClient(ctx, cfg, mazon.WithTokenStore(mazon.NewFileTokenStore(*tokenDir))),
+			output: *output,
+			stdout: stdout,
+			stderr: stderr,
+		}
+		return cmd(ctx, a, fs.Args()[2:])
+	}()
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	}
+
+	code := exitCode(err)
+	if code == exitUsage {
+		if msg := err.Error(); msg != "" {
+			fmt.Fprintf(stderr, "%s\n运行 mazonctl -h 查看帮助\n", msg)
+		}
+	} else {
+		printError(stderr, err)
+	}
+	return code
+}
+
+// loadConfig 读取配置文件
+func loadConfig(filename string) (cfg config.Config, err error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return cfg, fmt.Errorf("读取配置文件失败：%w", err)
+	}
+	if err = json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("解析配置文件 %s 失败：%w", filename, err)
+	}
+	if cfg.Endpoint() == "" {
+		return cfg, fmt.Errorf("无效的运行环境 %q", cfg.Environment)
+	}
+	return cfg, nil
+}
+
+// exitCode 根据错误类型返回退出码
+func exitCode(err error) int {
+	var usageErr *usageError
+	var validationErr *mazon.ValidationError
+	var apiErr *mazon.APIError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &validationErr):
+		return exitValidation
+	case errors.Is(err, mazon.ErrInvalidToken):
+		return exitAuth
+	case errors.Is(err, mazon.ErrTimeout):
+		return exitTimeout
+	case errors.Is(err, mazon.ErrInternal):
+		return exitInternal
+	case errors.Is(err, mazon.ErrBadRequest), errors.As(err, &apiErr):
+		return exitBadRequest
+	}
+	return exitError
+}
+
+// printError 输出错误信息，验证错误逐个输出字段
+func printError(w io.Writer, err error) {
+	var validationErr *mazon.ValidationError
+	if errors.As(err, &validationErr) {
+		fmt.Fprintln(w, "请求参数验证失败：")
+		for _, field := range validationErr.Fields {
+			fmt.Fprintf(w, "  %s: %s\n", field.JSONPath, field.Message)
+		}
+		return
+	}
+	var apiErr *mazon.APIError
+	if errors.As(err, &apiErr) && apiErr.Endpoint != "" {
+		fmt.Fprintf(w, "%s: %s\n", apiErr.Endpoint, err)
+		return
+	}
+	fmt.Fprintln(w, err)
+}
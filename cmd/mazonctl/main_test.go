@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/stretchr/testify/assert"
+)
+
+const orderYAML = `reference_no: CLI-ORDER-1
+sm_code: USPS GA13
+oa_firstname: ZZZ
+oa_telphone: 0731-12345678
+oa_country: US
+oa_state: CA
+oa_city: Ontario
+oa_postcode: "91761"
+oa_street_address1: 2078 E Francis Street
+is_more_box: 1
+shipper_code: S0004
+box_list:
+  - box_length: 1
+    box_width: 1
+    box_height: 1
+    box_actual_weight: 1
+`
+
+// newTestEnv 启动模拟服务并写入配置文件，返回执行命令的函数
+func newTestEnv(t *testing.T) (*mazontest.Server, func(args ...string) (int, string, string)) {
+	s := mazontest.NewServer()
+	t.Cleanup(s.Close)
+	s.AddOrder(mazontest.Order{
+		Order:  entity.Order{ReferenceNo: "TEST-ORDER", OrderCode: "EPB00120250912114236000021"},
+		SMCode: "USPS GA13",
+	}, "9234690397703300025653")
+
+	dir := t.TempDir()
+	b, _ := json.Marshal(s.Config())
+	configFile := filepath.Join(dir, "config.json")
+	assert.Nil(t, os.WriteFile(configFile, b, 0644))
+	return s, func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		args = append([]string{"-config", configFile, "-token-dir", dir}, args...)
+		code := run(context.Background(), args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	_, mazonctl := newTestEnv(t)
+	code, _, stderr := mazonctl()
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "缺少命令")
+
+	code, _, _ = mazonctl("order", "unknown")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = mazonctl("order", "query", "-unknown")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = mazonctl("label", "query")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, stderr = mazonctl("order", "query", "-h")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "-reference-no")
+}
+
+func TestRun_Commands(t *testing.T) {
+	s, mazonctl := newTestEnv(t)
+
+	code, stdout, _ := mazonctl("user", "info")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, mazontest.CustomerCode)
+	assert.Contains(t, stdout, mazontest.ShipperCode)
+
+	// 运费比价
+	rateFile := filepath.Join(t.TempDir(), "rate.yaml")
+	assert.Nil(t, os.WriteFile(rateFile, []byte(orderYAML), 0644))
+	code, stdout, _ = mazonctl("rate", "shop", "-file", rateFile, "-exclude", "UPS GROUND", "DHL", "USPS GA13", "UPS GROUND")
+	assert.Equal(t, exitOK, code)
+	assert.Regexp(t, `\*\s+USPS GA13`, stdout)
+	assert.Contains(t, stdout, "物流产品 DHL 不存在")
+	assert.NotContains(t, stdout, "UPS GROUND")
+
+	// 创建订单（YAML）
+	orderFile := filepath.Join(t.TempDir(), "order.yaml")
+	assert.Nil(t, os.WriteFile(orderFile, []byte(orderYAML), 0644))
+	code, stdout, _ = mazonctl("-output", "json", "order", "create", "-file", orderFile)
+	assert.Equal(t, exitOK, code)
+	var created entity.OrderCreateResult
+	assert.Nil(t, json.Unmarshal([]byte(stdout), &created))
+	assert.NotEmpty(t, created.OrderCode)
+	assert.Len(t, created.Labels, 1)
+
+	code, stdout, _ = mazonctl("order", "query", "-reference-no", "CLI-ORDER-1")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, created.OrderCode)
+
+	code, stdout, _ = mazonctl("label", "detail", "-order-code", created.OrderCode)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, created.Labels[0].TrackingNumber)
+
+	code, stdout, _ = mazonctl("label", "query", created.Labels[0].TrackingNumber)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, created.OrderCode)
+
+	dir := t.TempDir()
+	code, stdout, _ = mazonctl("label", "download", "-dir", dir, "-order-code", created.OrderCode)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "merge_label")
+	assert.FileExists(t, filepath.Join(dir, created.OrderCode, created.Labels[0].TrackingNumber+".pdf"))
+
+	code, stdout, _ = mazonctl("scanform", "create", created.Labels[0].TrackingNumber)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "/scanforms/")
+
+	code, stdout, _ = mazonctl("order", "cancel", "-order-code", created.OrderCode)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, entity.OrderCanceled.String())
+	o, _ := s.Order(created.OrderCode)
+	assert.Equal(t, entity.OrderCanceled, o.OrderStatus)
+}
+
+func TestRun_ExitCode(t *testing.T) {
+	s, mazonctl := newTestEnv(t)
+
+	// 请求参数验证失败
+	rateFile := filepath.Join(t.TempDir(), "rate.json")
+	assert.Nil(t, os.WriteFile(rateFile, []byte(`{"reference_no": "R1"}`), 0644))
+	code, _, stderr := mazonctl("rate", "calc", "-file", rateFile)
+	assert.Equal(t, exitValidation, code)
+	assert.Contains(t, stderr, "$.sm_code")
+
+	// 未知字段
+	assert.Nil(t, os.WriteFile(rateFile, []byte(`{"sm_cod": "USPS GA13"}`), 0644))
+	code, _, _ = mazonctl("rate", "calc", "-file", rateFile)
+	assert.Equal(t, exitError, code)
+
+	code, _, _ = mazonctl("order", "cancel", "-order-code", "NOT-EXISTS")
+	assert.Equal(t, exitBadRequest, code)
+
+	s.Inject(mazontest.Fault{Path: "/getUserInfo", Code: 500})
+	code, _, _ = mazonctl("user", "info")
+	assert.Equal(t, exitInternal, code)
+	s.ResetFaults()
+}
+
+func Test_exitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{usagef("缺少命令"), exitUsage},
+		{&mazon.ValidationError{}, exitValidation},
+		{&mazon.APIError{Code: mazon.InvalidToken}, exitAuth},
+		{&mazon.APIError{Code: mazon.BadRequestError}, exitBadRequest},
+		{&mazon.APIError{Code: 1001}, exitBadRequest},
+		{&mazon.APIError{Code: http.StatusRequestTimeout}, exitTimeout},
+		{&mazon.APIError{Code: http.StatusBadGateway, HTTPStatus: http.StatusBadGateway}, exitInternal},
+		{fmt.Errorf("create order: %w", &mazon.APIError{Code: mazon.InternalError}), exitInternal},
+		{errors.New("unknown"), exitError},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, exitCode(tt.err), "%v", tt.err)
+	}
+}
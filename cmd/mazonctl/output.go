@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// table 表格
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// tables 输出的表格，多个表格之间以空行分隔
+type tables []table
+
+func (t *tables) add(header []string, rows ...[]string) {
+	*t = append(*t, table{header: header, rows: rows})
+}
+
+// print 输出结果，JSON 格式直接输出 v，表格格式输出 fn 中添加的表格
+func (a *app) print(v any, fn func(t *tables)) error {
+	if a.output == outputJSON {
+		e := json.NewEncoder(a.stdout)
+		e.SetIndent("", "  ")
+		e.SetEscapeHTML(false)
+		return e.Encode(v)
+	}
+
+	var t tables
+	fn(&t)
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	for i, tbl := range t {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, strings.Join(tbl.header, "\t"))
+		for _, row := range tbl.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+	}
+	return w.Flush()
+}
@@ -1,9 +1,42 @@
 package config
 
+import "strings"
+
+// 运行环境
+const (
+	ProductionEnvironment = "production" // 生产环境
+	SandboxEnvironment    = "sandbox"    // 本地沙箱环境（需要在本地启动模拟服务，比如 mazontest）
+)
+
+// 各运行环境对应的接口地址
+var environments = map[string]string{
+	ProductionEnvironment: "https://api.mazonlabel.com/api/svc",
+	SandboxEnvironment:    "http://127.0.0.1:8090/api/svc",
+}
+
 type Config struct {
 	Debug         bool   `json:"debug"`          // 是否启用调试模式
 	Timeout       int    `json:"timeout"`        // HTTP 超时设定（单位：秒）
 	AppKey        string `json:"app_key"`        // App Key
 	AppToken      string `json:"app_token"`      // App Token
 	TokenDuration int    `json:"token_duration"` // Token 生效时长（单位：小时）
+	Environment   string `json:"environment"`    // 运行环境（production、sandbox），默认为 production
+	BaseURL       string `json:"base_url"`       // 接口地址，设置后将忽略 Environment 设置
+	Locale        string `json:"locale"`         // 错误信息语言（zh-CN、en-US），默认为 zh-CN
+}
+
+// Endpoint 返回接口地址
+//
+// 优先使用 BaseURL，其次根据 Environment 返回对应环境的地址，Environment 为空时使用生产环境地址。
+// 无效的 Environment 返回空字符串，避免请求被误发送到生产环境。
+func (c Config) Endpoint() string {
+	if u := strings.TrimSpace(c.BaseURL); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+
+	env := strings.ToLower(strings.TrimSpace(c.Environment))
+	if env == "" {
+		env = ProductionEnvironment
+	}
+	return environments[env]
 }
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Endpoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"default", Config{}, "https://api.mazonlabel.com/api/svc"},
+		{"production", Config{Environment: ProductionEnvironment}, "https://api.mazonlabel.com/api/svc"},
+		{"sandbox", Config{Environment: "Sandbox"}, "http://127.0.0.1:8090/api/svc"},
+		{"base url", Config{Environment: SandboxEnvironment, BaseURL: "http://localhost:9000/"}, "http://localhost:9000"},
+		{"unknown environment", Config{Environment: "staging"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.Endpoint())
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Package dimweight 根据物流产品的计费规则估算包裹的体积重和计费重量
+//
+// 在请求运费试算之前可以在本地估算计费重量，用于选择包装：
+//
+//	c := dimweight.New(map[string]dimweight.Rule{"UPS GROUND": dimweight.UPSGround})
+//	res, err := c.Rate(req)
+//	fmt.Println(res.BillableWeight, res.Boxes[0].Flags)
+package dimweight
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+var (
+	ErrNoRule                = errors.New("没有物流产品的计费规则")
+	ErrInvalidWeightUnitType = errors.New("无效的包裹单位类型")
+)
+
+// Flag 包裹标记
+type Flag string
+
+const (
+	AdditionalHandling Flag = "additional_handling" // 需要收取附加处理费
+	Oversize           Flag = "oversize"            // 超大包裹
+	Dimensional        Flag = "dimensional"         // 按体积重计费
+	ExceedsLimits      Flag = "exceeds_limits"      // 超过最大尺寸或者重量，不能寄送
+)
+
+// BoxResult 包裹计算结果，尺寸和重量的单位与请求的 WeightUnitType 一致
+type BoxResult struct {
+	ActualWeight      float64 // 实际重量
+	DimensionalWeight float64 // 体积重（不计算体积重时为 0）
+	BillableWeight    float64 // 计费重量
+	LengthPlusGirth   float64 // 长 + 周长
+	Flags             []Flag  // 标记
+}
+
+// Has 是否有指定的标记
+func (r BoxResult) Has(flag Flag) bool {
+	return slices.Contains(r.Flags, flag)
+}
+
+// Result 计算结果，重量的单位与请求的 WeightUnitType 一致
+type Result struct {
+	SMCode         string      // 物流产品
+	WeightUnitType int         // 包裹单位类型
+	Boxes          []BoxResult // 各包裹的结果
+	ActualWeight   float64     // 总实际重量
+	BillableWeight float64     // 总计费重量
+}
+
+// Has 是否有包裹有指定的标记
+func (r Result) Has(flag Flag) bool {
+	return slices.ContainsFunc(r.Boxes, func(box BoxResult) bool { return box.Has(flag) })
+}
+
+// dimensions 包裹尺寸（从长到短排序）和重量
+type dimensions struct {
+	length, width, height, weight float64
+}
+
+func (d dimensions) lengthPlusGirth() float64 {
+	return d.length + 2*(d.width+d.height)
+}
+
+func (d dimensions) volume() float64 {
+	return d.length * d.width * d.height
+}
+
+// Calculator 计费重量计算器
+type Calculator struct {
+	rules       map[string]Rule
+	defaultRule *Rule
+}
+
+// New 创建计算器，rules 为物流产品代码（不区分大小写）对应的计费规则
+func New(rules map[string]Rule) *Calculator {
+	c := &Calculator{rules: make(map[string]Rule, len(rules))}
+	for smCode, rule := range rules {
+		c.SetRule(smCode, rule)
+	}
+	return c
+}
+
+// SetRule 设置物流产品的计费规则
+func (c *Calculator) SetRule(smCode string, rule Rule) {
+	c.rules[strings.ToUpper(strings.TrimSpace(smCode))] = rule
+}
+
+// SetDefault 设置没有单独设置计费规则的物流产品使用的默认规则
+func (c *Calculator) SetDefault(rule Rule) {
+	c.defaultRule = &rule
+}
+
+// Rule 返回物流产品的计费规则
+func (c *Calculator) Rule(smCode string) (Rule, bool) {
+	if rule, ok := c.rules[strings.ToUpper(strings.TrimSpace(smCode))]; ok {
+		return rule, true
+	}
+	if c.defaultRule != nil {
+		return *c.defaultRule, true
+	}
+	return Rule{}, false
+}
+
+// Rate 计算运费试算请求中的包裹
+func (c *Calculator) Rate(req mazon.RateCalcRequest) (Result, error) {
+	return c.Calc(req.SMCode, req.WeightUnitType, req.BoxList...)
+}
+
+// Order 计算创建订单请求中的包裹
+func (c *Calculator) Order(req mazon.CreateOrderRequest) (Result, error) {
+	return c.Rate(mazon.NewRateCalcRequest(req))
+}
+
+// Calc 计算包裹的实际重量、体积重和计费重量，weightUnitType 为包裹的单位类型（0 表示默认的公制）
+func (c *Calculator) Calc(smCode string, weightUnitType int, boxes ...mazon.RateCalcOrderBox) (Result, error) {
+	rule, ok := c.Rule(smCode)
+	if !ok {
+		return Result{}, fmt.Errorf("%w %s", ErrNoRule, smCode)
+	}
+	if weightUnitType == 0 {
+		weightUnitType = entity.WeightUnitMetric
+	}
+	boxLength, boxWeight := entity.Centimeter, entity.Kilogram
+	switch weightUnitType {
+	case entity.WeightUnitMetric:
+	case entity.WeightUnitImperial:
+		boxLength, boxWeight = entity.Inch, entity.Pound
+	default:
+		return Result{}, fmt.Errorf("%w %d", ErrInvalidWeightUnitType, weightUnitType)
+	}
+	ruleLength, ruleWeight := rule.units()
+
+	res := Result{SMCode: smCode, WeightUnitType: weightUnitType, Boxes: make([]BoxResult, len(boxes))}
+	for i, box := range boxes {
+		sides := []float64{
+			entity.Length{Value: box.Length, Unit: boxLength}.In(ruleLength),
+			entity.Length{Value: box.Width, Unit: boxLength}.In(ruleLength),
+			entity.Length{Value: box.Height, Unit: boxLength}.In(ruleLength),
+		}
+		if rule.RoundDimensions {
+			for j, side := range sides {
+				sides[j] = ceil(side, 1)
+			}
+		}
+		slices.SortFunc(sides, func(a, b float64) int {
+			switch {
+			case a > b:
+				return -1
+			case a < b:
+				return 1
+			}
+			return 0
+		})
+		d := dimensions{
+			length: sides[0],
+			width:  sides[1],
+			height: sides[2],
+			weight: entity.Weight{Value: box.ActualWeight, Unit: boxWeight}.In(ruleWeight),
+		}
+
+		var flags []Flag
+		billable := d.weight
+		dimWeight := 0.0
+		if rule.DimDivisor > 0 && d.volume() > rule.DimMinVolume {
+			dimWeight = d.volume() / rule.DimDivisor
+			if dimWeight > billable {
+				billable = dimWeight
+				flags = append(flags, Dimensional)
+			}
+		}
+		if rule.AdditionalHandling.exceeds(d) {
+			flags = append(flags, AdditionalHandling)
+		}
+		if rule.Oversize.exceeds(d) {
+			flags = append(flags, Oversize)
+			billable = max(billable, rule.OversizeMinWeight)
+		}
+		if rule.Max.exceeds(d) {
+			flags = append(flags, ExceedsLimits)
+		}
+		billable = ceil(billable, rule.WeightIncrement)
+
+		// 转换为包裹的单位
+		toBoxWeight := func(v float64) float64 {
+			return round2(entity.Weight{Value: v, Unit: ruleWeight}.In(boxWeight))
+		}
+		res.Boxes[i] = BoxResult{
+			ActualWeight:      round2(box.ActualWeight),
+			DimensionalWeight: toBoxWeight(dimWeight),
+			BillableWeight:    toBoxWeight(billable),
+			LengthPlusGirth:   round2(entity.Length{Value: d.lengthPlusGirth(), Unit: ruleLength}.In(boxLength)),
+			Flags:             flags,
+		}
+		res.ActualWeight += res.Boxes[i].ActualWeight
+		res.BillableWeight += res.Boxes[i].BillableWeight
+	}
+	res.ActualWeight = round2(res.ActualWeight)
+	res.BillableWeight = round2(res.BillableWeight)
+	return res, nil
+}
+
+// ceil 按 increment 向上取整（忽略浮点误差），increment 小于等于 0 时不取整
+func ceil(v, increment float64) float64 {
+	if increment <= 0 {
+		return v
+	}
+	return math.Ceil(math.Round(v/increment*1e6)/1e6) * increment
+}
+
+// round2 四舍五入到两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,69 @@
+package dimweight
+
+import (
+	"testing"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculator_Calc(t *testing.T) {
+	c := New(map[string]Rule{
+		"UPS GROUND": UPSGround,
+		"usps ga13":  USPSGroundAdvantage,
+	})
+
+	tests := []struct {
+		name            string
+		smCode          string
+		weightUnitType  int
+		box             mazon.RateCalcOrderBox
+		billable        float64
+		dimWeight       float64
+		lengthPlusGirth float64
+		flags           []Flag
+	}{
+		{"dimensional", "UPS GROUND", entity.WeightUnitImperial, mazon.RateCalcOrderBox{Length: 20, Width: 20, Height: 20, ActualWeight: 10}, 58, 57.55, 100, []Flag{Dimensional}},
+		{"metric", "UPS GROUND", entity.WeightUnitMetric, mazon.RateCalcOrderBox{Length: 30, Width: 130, Height: 20, ActualWeight: 20}, 20.41, 16.29, 233.68, []Flag{AdditionalHandling}},
+		{"oversize", "UPS GROUND", entity.WeightUnitImperial, mazon.RateCalcOrderBox{Length: 100, Width: 10, Height: 10, ActualWeight: 5}, 90, 71.94, 140, []Flag{Dimensional, AdditionalHandling, Oversize}},
+		{"small", "USPS GA13", 0, mazon.RateCalcOrderBox{Length: 25, Width: 25, Height: 25, ActualWeight: 1}, 1.36, 0, 127, nil},
+		{"exceeds limits", "USPS GA13", entity.WeightUnitImperial, mazon.RateCalcOrderBox{Length: 10, Width: 10, Height: 10, ActualWeight: 80.5}, 81, 0, 50, []Flag{ExceedsLimits}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := c.Calc(tt.smCode, tt.weightUnitType, tt.box)
+			assert.Nil(t, err)
+			box := res.Boxes[0]
+			assert.Equal(t, tt.box.ActualWeight, box.ActualWeight)
+			assert.Equal(t, tt.billable, box.BillableWeight)
+			assert.Equal(t, tt.dimWeight, box.DimensionalWeight)
+			assert.Equal(t, tt.lengthPlusGirth, box.LengthPlusGirth)
+			assert.Equal(t, tt.flags, box.Flags)
+		})
+	}
+
+	_, err := c.Calc("FEDEX HOME", entity.WeightUnitImperial)
+	assert.ErrorIs(t, err, ErrNoRule)
+	c.SetDefault(FedExHome)
+	_, err = c.Calc("FEDEX HOME", 3)
+	assert.ErrorIs(t, err, ErrInvalidWeightUnitType)
+}
+
+func TestCalculator_Order(t *testing.T) {
+	c := New(map[string]Rule{"UPS GROUND": UPSGround})
+	req, err := mazon.NewShipmentBuilder("TEST").
+		SMCode("UPS GROUND").
+		Box(entity.Inches(20), entity.Inches(20), entity.Inches(20), entity.Pounds(10)).
+		Box(entity.Inches(10), entity.Inches(10), entity.Inches(10), entity.Pounds(60.2)).
+		CreateOrderRequest()
+	assert.Nil(t, err)
+	res, err := c.Order(req)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.WeightUnitImperial, res.WeightUnitType)
+	assert.Equal(t, 70.2, res.ActualWeight)
+	assert.Equal(t, 119.0, res.BillableWeight)
+	assert.True(t, res.Has(AdditionalHandling))
+	assert.True(t, res.Boxes[1].Has(AdditionalHandling))
+	assert.False(t, res.Has(Oversize))
+}
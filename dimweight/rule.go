@@ -0,0 +1,85 @@
+package dimweight
+
+import "github.com/hiscaler/mazon-go/entity"
+
+// Limits 尺寸和重量限制，值为 0 的项不限制
+type Limits struct {
+	Length          float64 `json:"length"`            // 最长边
+	Width           float64 `json:"width"`             // 第二长边
+	Weight          float64 `json:"weight"`            // 实际重量
+	LengthPlusGirth float64 `json:"length_plus_girth"` // 长 + 周长（最长边 + 2 × 另外两边之和）
+	Volume          float64 `json:"volume"`            // 体积
+}
+
+// exceeds 是否超过任意一项限制
+func (l Limits) exceeds(d dimensions) bool {
+	return over(d.length, l.Length) ||
+		over(d.width, l.Width) ||
+		over(d.weight, l.Weight) ||
+		over(d.lengthPlusGirth(), l.LengthPlusGirth) ||
+		over(d.volume(), l.Volume)
+}
+
+func over(v, limit float64) bool {
+	return limit > 0 && v > limit
+}
+
+// Rule 物流产品的计费规则
+//
+// 尺寸和重量使用 Units 指定的单位（英制为 inch/lb，公制为 cm/kg），计算时会将包裹转换为规则的单位。
+type Rule struct {
+	Units              int     `json:"units"`               // 规则使用的单位类型（entity.WeightUnitImperial、entity.WeightUnitMetric），默认为英制
+	DimDivisor         float64 `json:"dim_divisor"`         // 体积重除数，比如 139（立方英寸/磅）、5000（立方厘米/千克），为 0 时不计算体积重
+	DimMinVolume       float64 `json:"dim_min_volume"`      // 体积超过该值时才计算体积重（比如 USPS 为 1728 立方英寸），为 0 时总是计算
+	RoundDimensions    bool    `json:"round_dimensions"`    // 尺寸是否向上取整后再计算
+	WeightIncrement    float64 `json:"weight_increment"`    // 计费重量向上取整的单位，比如 1（磅），为 0 时不取整
+	AdditionalHandling Limits  `json:"additional_handling"` // 超过任意一项时需要收取附加处理费
+	Oversize           Limits  `json:"oversize"`            // 超过任意一项时为超大包裹
+	OversizeMinWeight  float64 `json:"oversize_min_weight"` // 超大包裹的最低计费重量
+	Max                Limits  `json:"max"`                 // 超过任意一项时不能寄送
+}
+
+// units 返回规则使用的长度和重量单位
+func (r Rule) units() (entity.LengthUnit, entity.WeightUnit) {
+	if r.Units == entity.WeightUnitMetric {
+		return entity.Centimeter, entity.Kilogram
+	}
+	return entity.Inch, entity.Pound
+}
+
+// 常用物流产品的计费规则（英制），实际规则请以物流商和美正的公布为准
+var (
+	// UPSGround UPS 地面服务
+	UPSGround = Rule{
+		Units:              entity.WeightUnitImperial,
+		DimDivisor:         139,
+		RoundDimensions:    true,
+		WeightIncrement:    1,
+		AdditionalHandling: Limits{Length: 48, Width: 30, Weight: 50, LengthPlusGirth: 105},
+		Oversize:           Limits{Length: 96, LengthPlusGirth: 130},
+		OversizeMinWeight:  90,
+		Max:                Limits{Length: 108, Weight: 150, LengthPlusGirth: 165},
+	}
+	// FedExHome FedEx Home Delivery
+	FedExHome = Rule{
+		Units:              entity.WeightUnitImperial,
+		DimDivisor:         139,
+		RoundDimensions:    true,
+		WeightIncrement:    1,
+		AdditionalHandling: Limits{Length: 48, Width: 30, Weight: 50, LengthPlusGirth: 105},
+		Oversize:           Limits{Length: 96, LengthPlusGirth: 130},
+		OversizeMinWeight:  90,
+		Max:                Limits{Length: 108, Weight: 150, LengthPlusGirth: 165},
+	}
+	// USPSGroundAdvantage USPS Ground Advantage
+	USPSGroundAdvantage = Rule{
+		Units:              entity.WeightUnitImperial,
+		DimDivisor:         166,
+		DimMinVolume:       1728,
+		RoundDimensions:    true,
+		WeightIncrement:    1,
+		AdditionalHandling: Limits{Length: 22, Volume: 3456},
+		Oversize:           Limits{LengthPlusGirth: 108},
+		Max:                Limits{Weight: 70, LengthPlusGirth: 130},
+	}
+)
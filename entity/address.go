@@ -0,0 +1,81 @@
+package entity
+
+import "strings"
+
+// Address 通用地址（收件人、发件人、退件地址使用同一个模型，通过转换方法转换为各接口需要的格式）
+type Address struct {
+	Name       string `json:"name"`              // 姓名
+	Company    string `json:"company,omitempty"` // 公司
+	Phone      string `json:"phone,omitempty"`   // 电话
+	Country    string `json:"country"`           // 国家（国家二字码）
+	State      string `json:"state"`             // 州/省
+	City       string `json:"city"`              // 城市
+	PostalCode string `json:"postal_code"`       // 邮编（包括扩展邮编，比如 75115-2500）
+	Line1      string `json:"line1"`             // 地址 1
+	Line2      string `json:"line2,omitempty"`   // 地址 2
+	Code       string `json:"code,omitempty"`    // 地址编码（发件人编码）
+}
+
+// ShipperAddress 转换为发件人信息
+func (a Address) ShipperAddress() ShipperAddress {
+	return ShipperAddress{
+		ShipperCode:          a.Code,
+		ShipperName:          a.Name,
+		ShipperCompany:       a.Company,
+		ShipperTelPhone:      a.Phone,
+		ShipperCountry:       a.Country,
+		ShipperStateProvince: a.State,
+		ShipperCity:          a.City,
+		ShipperPostalCode:    a.PostalCode,
+		ShipperAddress1:      a.Line1,
+		ShipperAddress2:      a.Line2,
+	}
+}
+
+// ReturnAddress 转换为退件信息（姓名按最后一个空格拆分为名字和姓氏，退件地址没有公司和国家）
+func (a Address) ReturnAddress() ReturnAddress {
+	firstName, lastName := strings.TrimSpace(a.Name), ""
+	if i := strings.LastIndexByte(firstName, ' '); i != -1 {
+		firstName, lastName = strings.TrimSpace(firstName[:i]), firstName[i+1:]
+	}
+	return ReturnAddress{
+		StreetAddress:    a.Line1,
+		SecondaryAddress: a.Line2,
+		ZipCodeAndPlus4:  a.PostalCode,
+		City:             a.City,
+		State:            a.State,
+		FirstName:        firstName,
+		LastName:         lastName,
+		Phone:            a.Phone,
+	}
+}
+
+// Address 转换为通用地址
+func (m ShipperAddress) Address() Address {
+	return Address{
+		Name:       m.ShipperName,
+		Company:    m.ShipperCompany,
+		Phone:      m.ShipperTelPhone,
+		Country:    m.ShipperCountry,
+		State:      m.ShipperStateProvince,
+		City:       m.ShipperCity,
+		PostalCode: m.ShipperPostalCode,
+		Line1:      m.ShipperAddress1,
+		Line2:      m.ShipperAddress2,
+		Code:       m.ShipperCode,
+	}
+}
+
+// Address 转换为通用地址（退件地址只支持美国地址，国家固定为 US）
+func (m ReturnAddress) Address() Address {
+	return Address{
+		Name:       strings.TrimSpace(m.FirstName + " " + m.LastName),
+		Phone:      m.Phone,
+		Country:    "US",
+		State:      m.State,
+		City:       m.City,
+		PostalCode: m.ZipCodeAndPlus4,
+		Line1:      m.StreetAddress,
+		Line2:      m.SecondaryAddress,
+	}
+}
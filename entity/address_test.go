@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress(t *testing.T) {
+	a := Address{
+		Name:       "Mary Ann Smith",
+		Company:    "Mazon",
+		Phone:      "9095550100",
+		Country:    "US",
+		State:      "TX",
+		City:       "Dallas",
+		PostalCode: "75115-2500",
+		Line1:      "100 Main St",
+		Line2:      "Apt 2",
+		Code:       "S0004",
+	}
+	assert.Equal(t, a, a.ShipperAddress().Address())
+
+	r := a.ReturnAddress()
+	assert.Equal(t, "Mary Ann", r.FirstName)
+	assert.Equal(t, "Smith", r.LastName)
+	assert.Equal(t, "75115-2500", r.ZipCodeAndPlus4)
+	back := r.Address()
+	assert.Equal(t, "Mary Ann Smith", back.Name)
+	assert.Empty(t, back.Company)
+	assert.Equal(t, "US", back.Country)
+
+	assert.Equal(t, ReturnAddress{FirstName: "Cher"}, Address{Name: " Cher "}.ReturnAddress())
+}
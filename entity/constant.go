@@ -1,6 +1,19 @@
 package entity
 
+// 订单状态
+//
+// 零值为 OrderStatusUnknown（接口没有返回订单状态），接口文档中没有草稿状态对应的数字，使用 -1 表示。
 const (
-	OrderCanceling = 5 // 订单取消中
-	OrderCanceled  = 6 // 订单已取消
+	OrderStatusUnknown OrderStatus = 0  // 未知
+	OrderDraft         OrderStatus = -1 // 草稿
+	OrderSubmitted     OrderStatus = 1  // 已提交
+	OrderForecasted    OrderStatus = 2  // 已预报
+	OrderCanceling     OrderStatus = 5  // 订单取消中
+	OrderCanceled      OrderStatus = 6  // 订单已取消
+)
+
+// 包裹单位类型（WeightUnitType）
+const (
+	WeightUnitImperial = 1 // 英制（INCH/LBS）
+	WeightUnitMetric   = 2 // 公制（CM/KG），默认
 )
@@ -8,3 +8,13 @@ type OrderCreateResult struct {
 	Labels      []Label     `json:"labels"`       // 为空时代表没有生产物流信息，需要异步获取
 	MergeLabel  string      `json:"merge_label"`  // 合并面单
 }
+
+// TotalFee 总费用
+func (r OrderCreateResult) TotalFee() (Money, error) {
+	return SumFees(r.Fee)
+}
+
+// FeesByTrackingNumber 各物流单号的费用合计
+func (r OrderCreateResult) FeesByTrackingNumber() (map[string]Money, error) {
+	return SumFeesByTrackingNumber(r.FeeDetail)
+}
@@ -2,10 +2,15 @@ package entity
 
 // Fee 费用信息
 type Fee struct {
-	FtCode       string `json:"ft_code"`       // 费用英文名称
-	Amount       string `json:"amount"`        // 金额
-	CurrencyCode string `json:"currency_code"` // 币种
-	FtName       string `json:"ft_name"`       // 费用中文名称
+	FtCode       string  `json:"ft_code"`       // 费用英文名称
+	Amount       Decimal `json:"amount"`        // 金额
+	CurrencyCode string  `json:"currency_code"` // 币种
+	FtName       string  `json:"ft_name"`       // 费用中文名称
+}
+
+// Money 费用金额（包含币种）
+func (f Fee) Money() Money {
+	return Money{Amount: f.Amount, Currency: f.CurrencyCode}
 }
 
 type FeeDetail struct {
@@ -13,3 +18,29 @@ type FeeDetail struct {
 	TrackingNumber string `json:"tracking_number"` // 物流单号（为空时代表没有生成物流单号，需要异步获取）
 	BoxCode        string `json:"box_code"`        // 箱号
 }
+
+// SumFees 费用合计
+func SumFees(fees []Fee) (Money, error) {
+	values := make([]Money, len(fees))
+	for i, fee := range fees {
+		values[i] = fee.Money()
+	}
+	return SumMoney(values...)
+}
+
+// SumFeesByTrackingNumber 按照物流单号合计费用明细（没有物流单号的费用以箱号合计）
+func SumFeesByTrackingNumber(details []FeeDetail) (map[string]Money, error) {
+	totals := make(map[string]Money)
+	for _, detail := range details {
+		key := detail.TrackingNumber
+		if key == "" {
+			key = detail.BoxCode
+		}
+		total, err := totals[key].Add(detail.Money())
+		if err != nil {
+			return nil, err
+		}
+		totals[key] = total
+	}
+	return totals, nil
+}
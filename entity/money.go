@@ -0,0 +1,278 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// decimalScale Decimal 保留的小数位数
+const decimalScale = 6
+
+var decimalFactor = int64(math.Pow10(decimalScale))
+
+var (
+	ErrInvalidDecimal   = errors.New("无效的金额")
+	ErrCurrencyMismatch = errors.New("币种不一致")
+)
+
+// Decimal 定点小数，精确到 6 位小数，用于金额计算以避免浮点数误差
+//
+// JSON 解析时支持字符串（"5.00"）和数字（5.00），序列化时输出至少保留两位小数的字符串
+type Decimal struct {
+	units int64 // 以 10^-6 为单位的值
+}
+
+// NewDecimal 根据整数部分和小数位数创建，比如 NewDecimal(1234, 2) 表示 12.34
+func NewDecimal(value int64, exp int) Decimal {
+	if exp > decimalScale {
+		return Decimal{units: roundDiv(value, int64(math.Pow10(exp-decimalScale)))}
+	}
+	return Decimal{units: value * int64(math.Pow10(decimalScale-exp))}
+}
+
+// DecimalFromFloat 根据浮点数创建（四舍五入到 6 位小数）
+func DecimalFromFloat(f float64) Decimal {
+	return Decimal{units: int64(math.Round(f * float64(decimalFactor)))}
+}
+
+// ParseDecimal 解析金额字符串，比如 12.34、-0.5、1,234.56，超过 6 位的小数四舍五入
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	if s == "" {
+		return Decimal{}, nil
+	}
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+		}
+		return DecimalFromFloat(f), nil
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" || strings.Trim(intPart+fracPart, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+	}
+
+	var units int64
+	if intPart != "" {
+		n, err := strconv.ParseInt(intPart, 10, 64)
+		if err != nil || n > math.MaxInt64/decimalFactor {
+			return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+		}
+		units = n * decimalFactor
+	}
+	roundUp := false
+	if len(fracPart) > decimalScale {
+		roundUp = fracPart[decimalScale] >= '5'
+		fracPart = fracPart[:decimalScale]
+	}
+	if fracPart != "" {
+		n, _ := strconv.ParseInt(fracPart+strings.Repeat("0", decimalScale-len(fracPart)), 10, 64)
+		units += n
+	}
+	if roundUp {
+		units++
+	}
+	if negative {
+		units = -units
+	}
+	return Decimal{units: units}, nil
+}
+
+// MustParseDecimal 解析金额字符串，解析失败时 panic
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// roundDiv 四舍五入的整数除法
+func roundDiv(a, b int64) int64 {
+	q, r := a/b, a%b
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= b {
+		if a < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{units: d.units + other.units}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return Decimal{units: d.units - other.units}
+}
+
+// Mul 乘以整数，比如 单价 × 数量
+func (d Decimal) Mul(n int64) Decimal {
+	return Decimal{units: d.units * n}
+}
+
+// MulDecimal 乘以小数（结果四舍五入到 6 位小数）
+func (d Decimal) MulDecimal(other Decimal) Decimal {
+	// 拆分以避免溢出
+	hi, lo := other.units/decimalFactor, other.units%decimalFactor
+	return Decimal{units: d.units*hi + roundDiv(d.units*lo, decimalFactor)}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units}
+}
+
+// Round 四舍五入到指定的小数位数
+func (d Decimal) Round(places int) Decimal {
+	if places >= decimalScale {
+		return d
+	}
+	factor := int64(math.Pow10(decimalScale - places))
+	return Decimal{units: roundDiv(d.units, factor) * factor}
+}
+
+// Cmp 比较大小，小于、等于、大于 other 时分别返回 -1、0、1
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+func (d Decimal) IsNegative() bool {
+	return d.units < 0
+}
+
+// Float64 转换为浮点数（仅用于展示，不要用于计算）
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / float64(decimalFactor)
+}
+
+// StringFixed 保留指定的小数位数（四舍五入）
+func (d Decimal) StringFixed(places int) string {
+	places = min(max(places, 0), decimalScale)
+	units := d.Round(places).units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	s := fmt.Sprintf("%s%d", sign, units/decimalFactor)
+	if places > 0 {
+		frac := fmt.Sprintf("%0*d", decimalScale, units%decimalFactor)
+		s += "." + frac[:places]
+	}
+	return s
+}
+
+// String 至少保留两位小数，去掉多余的 0，比如 5.00、1.235
+func (d Decimal) String() string {
+	s := d.StringFixed(decimalScale)
+	s = strings.TrimRight(s, "0")
+	if i := strings.IndexByte(s, '.'); len(s)-i-1 < 2 {
+		s += strings.Repeat("0", 2-(len(s)-i-1))
+	}
+	return s
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Money 金额
+type Money struct {
+	Amount   Decimal `json:"amount"`   // 金额
+	Currency string  `json:"currency"` // 币种
+}
+
+// Add 相加，币种不一致时返回 ErrCurrencyMismatch，币种为空时视为与另一方相同
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := mergeCurrency(m.Currency, other.Currency)
+	if err != nil {
+		return m, err
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: currency}, nil
+}
+
+// Sub 相减，币种规则同 Add
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(Money{Amount: other.Amount.Neg(), Currency: other.Currency})
+}
+
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount.String()
+	}
+	return m.Amount.String() + " " + m.Currency
+}
+
+func mergeCurrency(a, b string) (string, error) {
+	a, b = strings.ToUpper(strings.TrimSpace(a)), strings.ToUpper(strings.TrimSpace(b))
+	switch {
+	case a == "":
+		return b, nil
+	case b == "" || a == b:
+		return a, nil
+	}
+	return "", fmt.Errorf("%w: %s, %s", ErrCurrencyMismatch, a, b)
+}
+
+// SumMoney 金额合计
+func SumMoney(values ...Money) (Money, error) {
+	var total Money
+	var err error
+	for _, v := range values {
+		if total, err = total.Add(v); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
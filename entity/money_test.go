@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", "0.00"},
+		{"5", "5.00"},
+		{"5.1", "5.10"},
+		{"0.125", "0.125"},
+		{"-3.50", "-3.50"},
+		{"1,234.56", "1234.56"},
+		{".5", "0.50"},
+		{"1.23456789", "1.234568"},
+		{"1e2", "100.00"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.s)
+		assert.Nil(t, err, tt.s)
+		assert.Equal(t, tt.want, d.String(), tt.s)
+	}
+
+	for _, s := range []string{"abc", "1.2.3", "-", "."} {
+		_, err := ParseDecimal(s)
+		assert.True(t, errors.Is(err, ErrInvalidDecimal), s)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	// 0.1 + 0.2 使用浮点数计算时不等于 0.3
+	sum := MustParseDecimal("0.1").Add(MustParseDecimal("0.2"))
+	assert.Equal(t, 0, sum.Cmp(MustParseDecimal("0.3")))
+	assert.Equal(t, "2.47", MustParseDecimal("2.465").StringFixed(2))
+	assert.Equal(t, "-2.47", MustParseDecimal("-2.465").StringFixed(2))
+	assert.Equal(t, "3", MustParseDecimal("2.5").StringFixed(0))
+	assert.Equal(t, "7.50", MustParseDecimal("2.50").Mul(3).String())
+	assert.Equal(t, "3.75", MustParseDecimal("2.50").MulDecimal(MustParseDecimal("1.5")).String())
+	assert.Equal(t, "12.34", NewDecimal(1234, 2).String())
+	assert.Equal(t, "0.000001", NewDecimal(5, 7).String())
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var fee Fee
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount":"5.10","currency_code":"USD"}`), &fee))
+	assert.Equal(t, "5.10 USD", fee.Money().String())
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount":5.2}`), &fee))
+	assert.Equal(t, "5.20", fee.Amount.String())
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount":null}`), &fee))
+	assert.True(t, fee.Amount.IsZero())
+
+	b, err := json.Marshal(Fee{Amount: MustParseDecimal("5")})
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"amount":"5.00"`)
+}
+
+func TestMoney_Totals(t *testing.T) {
+	var result OrderCreateResult
+	err := json.Unmarshal([]byte(`{
+		"fee": [{"amount":"5.10","currency_code":"USD"},{"amount":"0.20","currency_code":"USD"}],
+		"fee_detail": [
+			{"amount":"2.55","currency_code":"USD","tracking_number":"TN1"},
+			{"amount":"0.10","currency_code":"USD","tracking_number":"TN1"},
+			{"amount":"2.65","currency_code":"USD","tracking_number":"TN2"}
+		]
+	}`), &result)
+	assert.Nil(t, err)
+
+	total, err := result.TotalFee()
+	assert.Nil(t, err)
+	assert.Equal(t, "5.30 USD", total.String())
+	fees, err := result.FeesByTrackingNumber()
+	assert.Nil(t, err)
+	assert.Equal(t, "2.65 USD", fees["TN1"].String())
+	assert.Equal(t, "2.65 USD", fees["TN2"].String())
+
+	_, err = SumMoney(Money{Amount: MustParseDecimal("1"), Currency: "USD"}, Money{Amount: MustParseDecimal("1"), Currency: "CNY"})
+	assert.True(t, errors.Is(err, ErrCurrencyMismatch))
+
+	rate := RateCalcResult{
+		CurrencyCode: "USD",
+		TotalCharge:  MustParseDecimal("10.30"),
+		ChargeDetail: []ChargeDetail{{Amount: MustParseDecimal("7.30")}, {Amount: MustParseDecimal("3")}},
+	}
+	assert.Equal(t, rate.Total(), rate.ChargeDetailTotal())
+}
@@ -0,0 +1,267 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OrderStatus 订单状态
+//
+// JSON 解析时支持数字（2）、数字字符串（"2"）以及状态代码（"forecasted"）或者状态名称（"已预报"），序列化时输出数字。
+// null、空字符串、无法识别的状态代码或者没有返回状态时为 OrderStatusUnknown（未知的数字保留原值），
+// 解析时不会因为接口新增的状态而报错，可以通过 IsValid 判断是否为已知的状态，未知状态不能取消，也不能变更为其他状态。
+type OrderStatus int
+
+type orderStatusName struct {
+	code string // 状态代码
+	name string // 状态名称
+}
+
+var orderStatusNames = map[OrderStatus]orderStatusName{
+	OrderDraft:      {"draft", "草稿"},
+	OrderSubmitted:  {"submitted", "已提交"},
+	OrderForecasted: {"forecasted", "已预报"},
+	OrderCanceling:  {"canceling", "取消中"},
+	OrderCanceled:   {"canceled", "已取消"},
+}
+
+// orderStatusTransitions 允许的状态变化
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderDraft:      {OrderSubmitted, OrderForecasted, OrderCanceling, OrderCanceled},
+	OrderSubmitted:  {OrderForecasted, OrderCanceling, OrderCanceled},
+	OrderForecasted: {OrderCanceling, OrderCanceled},
+	OrderCanceling:  {OrderCanceled},
+}
+
+// ParseOrderStatus 根据数字、状态代码或者状态名称解析订单状态
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return OrderStatus(n), nil
+	}
+	if s == "" {
+		return OrderStatusUnknown, nil
+	}
+	for status, name := range orderStatusNames {
+		if strings.EqualFold(s, name.code) || s == name.name {
+			return status, nil
+		}
+	}
+	return OrderStatusUnknown, fmt.Errorf("无效的订单状态 %q", s)
+}
+
+// Code 状态代码，比如 forecasted
+func (s OrderStatus) Code() string {
+	if name, ok := orderStatusNames[s]; ok {
+		return name.code
+	}
+	return "unknown"
+}
+
+// String 状态名称，比如 已预报
+func (s OrderStatus) String() string {
+	if name, ok := orderStatusNames[s]; ok {
+		return name.name
+	}
+	if s == OrderStatusUnknown {
+		return "未知状态"
+	}
+	return fmt.Sprintf("未知状态(%d)", int(s))
+}
+
+// IsValid 是否为已知的状态
+func (s OrderStatus) IsValid() bool {
+	_, ok := orderStatusNames[s]
+	return ok
+}
+
+// IsFinal 是否为最终状态（不会再发生变化）
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderCanceled
+}
+
+// CanCancel 是否可以取消，在订单草稿、已预报、已提交状态时可以进行取消订单操作
+func (s OrderStatus) CanCancel() bool {
+	return s == OrderDraft || s == OrderSubmitted || s == OrderForecasted
+}
+
+// CanTransitionTo 是否可以变更为 next 状态
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, status := range orderStatusTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+func (s OrderStatus) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(s))), nil
+}
+
+func (s *OrderStatus) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		*s = OrderStatusUnknown
+		return nil
+	}
+
+	if b[0] == '"' {
+		var str string
+		if err := json.Unmarshal(b, &str); err != nil {
+			return err
+		}
+		// 无法识别的状态代码不作为错误处理
+		*s, _ = ParseOrderStatus(str)
+		return nil
+	}
+
+	var n int
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*s = OrderStatus(n)
+	return nil
+}
+
+// unmarshalStatusText 解析字符串状态，支持状态值（不区分大小写）或者状态名称，null 和空字符串返回空字符串，
+// 无法识别的状态保留原值（去除首尾空格）
+func unmarshalStatusText[T ~string](b []byte, names map[T]string) (T, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		return "", nil
+	}
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return "", err
+	}
+	if status, ok := parseStatusText(str, names); ok {
+		return status, nil
+	}
+	return T(strings.TrimSpace(str)), nil
+}
+
+// parseStatusText 在 names 中查找状态值或者状态名称
+func parseStatusText[T ~string](s string, names map[T]string) (T, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", true
+	}
+	for status, name := range names {
+		if strings.EqualFold(s, string(status)) || s == name {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// OrderSubStatus 订单子状态，为空时表示没有子状态
+//
+// JSON 解析时无法识别的子状态保留原值，可以通过 IsValid 判断是否为已知的子状态。
+type OrderSubStatus string
+
+// 订单子状态
+const (
+	OrderSubStatusWaiting    OrderSubStatus = "Waiting"    // 等待中
+	OrderSubStatusProcessing OrderSubStatus = "Processing" // 处理中
+	OrderSubStatusSuccess    OrderSubStatus = "Success"    // 成功
+	OrderSubStatusFailed     OrderSubStatus = "Failed"     // 失败
+)
+
+var orderSubStatusNames = map[OrderSubStatus]string{
+	OrderSubStatusWaiting:    "等待中",
+	OrderSubStatusProcessing: "处理中",
+	OrderSubStatusSuccess:    "成功",
+	OrderSubStatusFailed:     "失败",
+}
+
+// ParseOrderSubStatus 根据状态值（不区分大小写）或者状态名称解析订单子状态
+func ParseOrderSubStatus(s string) (OrderSubStatus, error) {
+	if status, ok := parseStatusText(s, orderSubStatusNames); ok {
+		return status, nil
+	}
+	return "", fmt.Errorf("无效的订单子状态 %q", s)
+}
+
+func (s OrderSubStatus) String() string {
+	return string(s)
+}
+
+// Name 状态名称，比如 等待中
+func (s OrderSubStatus) Name() string {
+	if name, ok := orderSubStatusNames[s]; ok {
+		return name
+	}
+	return string(s)
+}
+
+// IsValid 是否为已知的子状态（或者没有子状态）
+func (s OrderSubStatus) IsValid() bool {
+	_, ok := orderSubStatusNames[s]
+	return ok || s == ""
+}
+
+func (s *OrderSubStatus) UnmarshalJSON(b []byte) error {
+	status, err := unmarshalStatusText(b, orderSubStatusNames)
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// OrderWaitingStatus 订单等待状态（子状态为 Waiting 时等待的操作），为空时表示没有等待
+//
+// JSON 解析时无法识别的等待状态保留原值，可以通过 IsValid 判断是否为已知的等待状态。
+type OrderWaitingStatus string
+
+// 订单等待状态
+const (
+	OrderWaitingSync     OrderWaitingStatus = "Sync"     // 等待同步到物流商
+	OrderWaitingForecast OrderWaitingStatus = "Forecast" // 等待预报
+	OrderWaitingLabel    OrderWaitingStatus = "Label"    // 等待生成面单
+)
+
+var orderWaitingStatusNames = map[OrderWaitingStatus]string{
+	OrderWaitingSync:     "等待同步",
+	OrderWaitingForecast: "等待预报",
+	OrderWaitingLabel:    "等待面单",
+}
+
+// ParseOrderWaitingStatus 根据状态值（不区分大小写）或者状态名称解析订单等待状态
+func ParseOrderWaitingStatus(s string) (OrderWaitingStatus, error) {
+	if status, ok := parseStatusText(s, orderWaitingStatusNames); ok {
+		return status, nil
+	}
+	return "", fmt.Errorf("无效的订单等待状态 %q", s)
+}
+
+func (s OrderWaitingStatus) String() string {
+	return string(s)
+}
+
+// Name 状态名称，比如 等待同步
+func (s OrderWaitingStatus) Name() string {
+	if name, ok := orderWaitingStatusNames[s]; ok {
+		return name
+	}
+	return string(s)
+}
+
+// IsValid 是否为已知的等待状态（或者没有等待）
+func (s OrderWaitingStatus) IsValid() bool {
+	_, ok := orderWaitingStatusNames[s]
+	return ok || s == ""
+}
+
+func (s *OrderWaitingStatus) UnmarshalJSON(b []byte) error {
+	status, err := unmarshalStatusText(b, orderWaitingStatusNames)
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
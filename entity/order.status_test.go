@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderStatus_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		want OrderStatus
+	}{
+		{`{"order_status":2}`, OrderForecasted},
+		{`{"order_status":"2"}`, OrderForecasted},
+		{`{"order_status":"canceled"}`, OrderCanceled},
+		{`{"order_status":"取消中"}`, OrderCanceling},
+		{`{"order_status":"draft"}`, OrderDraft},
+		{`{"order_status":-1}`, OrderDraft},
+		{`{"order_status":""}`, OrderStatusUnknown},
+		{`{"order_status":null}`, OrderStatusUnknown},
+		{`{}`, OrderStatusUnknown},
+	}
+	for _, tt := range tests {
+		var order Order
+		assert.Nil(t, json.Unmarshal([]byte(tt.json), &order), tt.json)
+		assert.Equal(t, tt.want, order.OrderStatus, tt.json)
+	}
+
+	// 接口新增的状态不影响解析
+	var order Order
+	assert.Nil(t, json.Unmarshal([]byte(`{"order_status":"shipped","order_code":"EPB001"}`), &order))
+	assert.Equal(t, OrderStatusUnknown, order.OrderStatus)
+	assert.Equal(t, "EPB001", order.OrderCode)
+	assert.False(t, order.OrderStatus.CanCancel(), "未知状态不能取消")
+	assert.Nil(t, json.Unmarshal([]byte(`{"order_status":9}`), &order))
+	assert.Equal(t, OrderStatus(9), order.OrderStatus)
+	assert.False(t, order.OrderStatus.IsValid())
+	_, err := ParseOrderStatus("shipped")
+	assert.NotNil(t, err)
+
+	b, err := json.Marshal(Order{OrderStatus: OrderSubmitted})
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"order_status":1`)
+}
+
+func TestOrderStatus(t *testing.T) {
+	assert.Equal(t, "已预报", OrderForecasted.String())
+	assert.Equal(t, "forecasted", OrderForecasted.Code())
+	assert.Equal(t, "未知状态(9)", OrderStatus(9).String())
+	assert.Equal(t, "未知状态", OrderStatusUnknown.String())
+	assert.False(t, OrderStatusUnknown.IsValid())
+	assert.False(t, OrderStatusUnknown.CanCancel())
+	assert.False(t, OrderStatusUnknown.CanTransitionTo(OrderCanceled))
+	assert.True(t, OrderDraft.CanCancel())
+	assert.True(t, OrderSubmitted.CanCancel())
+	assert.False(t, OrderCanceling.CanCancel())
+	assert.True(t, OrderCanceling.CanTransitionTo(OrderCanceled))
+	assert.False(t, OrderCanceled.CanTransitionTo(OrderSubmitted))
+	assert.Equal(t, "已预报（Waiting，Sync）", ShippingLabel{OrderStatus: OrderForecasted, OrderSubStatus: OrderSubStatusWaiting, OrderWaitingStatus: OrderWaitingSync}.StatusText())
+}
+
+func TestOrderSubStatus(t *testing.T) {
+	var label ShippingLabel
+	assert.Nil(t, json.Unmarshal([]byte(`{"order_sub_status":"waiting","order_waiting_status":"等待同步"}`), &label))
+	assert.Equal(t, OrderSubStatusWaiting, label.OrderSubStatus)
+	assert.Equal(t, OrderWaitingSync, label.OrderWaitingStatus)
+	assert.Equal(t, "等待中", label.OrderSubStatus.Name())
+	assert.Equal(t, "等待同步", label.OrderWaitingStatus.Name())
+
+	label = ShippingLabel{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"order_sub_status":"","order_waiting_status":null}`), &label))
+	assert.Equal(t, OrderSubStatus(""), label.OrderSubStatus)
+	assert.True(t, label.OrderSubStatus.IsValid())
+	assert.True(t, label.OrderWaitingStatus.IsValid())
+
+	// 无法识别的状态保留原值
+	assert.Nil(t, json.Unmarshal([]byte(`{"order_sub_status":" Shipped ","order_waiting_status":"Pickup"}`), &label))
+	assert.Equal(t, OrderSubStatus("Shipped"), label.OrderSubStatus)
+	assert.Equal(t, OrderWaitingStatus("Pickup"), label.OrderWaitingStatus)
+	assert.False(t, label.OrderSubStatus.IsValid())
+	assert.False(t, label.OrderWaitingStatus.IsValid())
+	assert.Equal(t, "Shipped", label.OrderSubStatus.Name())
+	_, err := ParseOrderWaitingStatus("Pickup")
+	assert.NotNil(t, err)
+}
@@ -1,16 +1,38 @@
 package entity
 
 type RateCalcResult struct {
-	SmCode          string `json:"sm_code"`           // 物流产品
-	AddressTypeText string `json:"address_type_text"` // 地址类型描述
-	AddressType     string `json:"address_type"`      // 地址类型
-	CurrencyCode    string `json:"currency_code"`     // 币种
-	ShippingCharge  string `json:"shipping_charge"`   // 基础运费
-	TotalCharge     string `json:"total_charge"`      // 总金额
-	ChargeDetail    []struct {
-		FtCode      string `json:"ft_code"`       // 费用英文名称
-		FeeTypeCode string `json:"fee_type_code"` // 费用编码
-		ChargeDesc  string `json:"charge_desc"`   // 费用描述
-		Amount      string `json:"amount"`        // 费用描述
-	} `json:"charge_detail"` // 费用明细
+	SmCode          string         `json:"sm_code"`           // 物流产品
+	AddressTypeText string         `json:"address_type_text"` // 地址类型描述
+	AddressType     int            `json:"address_type"`      // 地址类型
+	CurrencyCode    string         `json:"currency_code"`     // 币种
+	ShippingCharge  Decimal        `json:"shipping_charge"`   // 基础运费
+	TotalCharge     Decimal        `json:"total_charge"`      // 总金额
+	ChargeDetail    []ChargeDetail `json:"charge_detail"`     // 费用明细
+}
+
+// ChargeDetail 费用明细
+type ChargeDetail struct {
+	FtCode      string  `json:"ft_code"`       // 费用英文名称
+	FeeTypeCode string  `json:"fee_type_code"` // 费用编码
+	ChargeDesc  string  `json:"charge_desc"`   // 费用描述
+	Amount      Decimal `json:"amount"`        // 金额
+}
+
+// Shipping 基础运费
+func (r RateCalcResult) Shipping() Money {
+	return Money{Amount: r.ShippingCharge, Currency: r.CurrencyCode}
+}
+
+// Total 总金额
+func (r RateCalcResult) Total() Money {
+	return Money{Amount: r.TotalCharge, Currency: r.CurrencyCode}
+}
+
+// ChargeDetailTotal 费用明细合计，可以用于核对总金额
+func (r RateCalcResult) ChargeDetailTotal() Money {
+	total := Money{Currency: r.CurrencyCode}
+	for _, detail := range r.ChargeDetail {
+		total.Amount = total.Amount.Add(detail.Amount)
+	}
+	return total
 }
@@ -1,21 +1,50 @@
 package entity
 
-import "gopkg.in/guregu/null.v4"
+import (
+	"strings"
+
+	"gopkg.in/guregu/null.v4"
+)
 
 // ShippingLabel 面单信息
 type ShippingLabel struct {
-	ReferenceNo        string      `json:"reference_no"`       // 参考号
-	OrderCode          string      `json:"order_code"`         // 订单号
-	OrderAddressType   string      `json:"order_address_type"` // 地址类型（Commercial 商业 Residential 住宅）
-	OrderStatus        int         `json:"order_status"`       // 订单状态（1 已提交、2 已预报）
-	OrderSubStatus     string      `json:"order_sub_status"`
-	OrderWaitingStatus string      `json:"order_waiting_status"`
-	SyncServiceStatus  string      `json:"sync_service_status"`
-	LogisticsErr       string      `json:"logistics_err"` // 错误信息
-	Labels             []Label     `json:"labels"`        // 面单信息
-	MergeLabel         string      `json:"merge_label"`   // 合并面单 URL
-	Fee                []Fee       `json:"fee"`           // 总费用信息
-	FeeDetail          []FeeDetail `json:"fee_detail"`    // 参考号
+	ReferenceNo        string             `json:"reference_no"`         // 参考号
+	OrderCode          string             `json:"order_code"`           // 订单号
+	OrderAddressType   string             `json:"order_address_type"`   // 地址类型（Commercial 商业 Residential 住宅）
+	OrderStatus        OrderStatus        `json:"order_status"`         // 订单状态（1 已提交、2 已预报）
+	OrderSubStatus     OrderSubStatus     `json:"order_sub_status"`     // 订单子状态
+	OrderWaitingStatus OrderWaitingStatus `json:"order_waiting_status"` // 订单等待状态
+	SyncServiceStatus  string             `json:"sync_service_status"`
+	LogisticsErr       string             `json:"logistics_err"` // 错误信息
+	Labels             []Label            `json:"labels"`        // 面单信息
+	MergeLabel         string             `json:"merge_label"`   // 合并面单 URL
+	Fee                []Fee              `json:"fee"`           // 总费用信息
+	FeeDetail          []FeeDetail        `json:"fee_detail"`    // 参考号
+}
+
+// StatusText 订单状态描述，包含子状态和等待状态，比如 已预报（xxx，yyy）
+func (l ShippingLabel) StatusText() string {
+	s := l.OrderStatus.String()
+	extras := make([]string, 0, 2)
+	for _, v := range []string{l.OrderSubStatus.String(), l.OrderWaitingStatus.String()} {
+		if v != "" {
+			extras = append(extras, v)
+		}
+	}
+	if len(extras) != 0 {
+		s += "（" + strings.Join(extras, "，") + "）"
+	}
+	return s
+}
+
+// TotalFee 总费用
+func (l ShippingLabel) TotalFee() (Money, error) {
+	return SumFees(l.Fee)
+}
+
+// FeesByTrackingNumber 各物流单号的费用合计
+func (l ShippingLabel) FeesByTrackingNumber() (map[string]Money, error) {
+	return SumFeesByTrackingNumber(l.FeeDetail)
 }
 
 // Label 面单
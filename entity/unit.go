@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrUnknownLengthUnit = errors.New("未知的长度单位")
+	ErrUnknownWeightUnit = errors.New("未知的重量单位")
+)
+
+// LengthUnit 长度单位
+type LengthUnit string
+
+const (
+	Centimeter LengthUnit = "cm" // 厘米
+	Millimeter LengthUnit = "mm" // 毫米
+	Inch       LengthUnit = "in" // 英寸
+)
+
+// 每个单位对应的厘米数
+var centimetersPer = map[LengthUnit]float64{
+	Centimeter: 1,
+	Millimeter: 0.1,
+	Inch:       2.54,
+}
+
+// IsValid 是否为已知的长度单位（空字符串为未知单位）
+func (u LengthUnit) IsValid() bool {
+	_, ok := centimetersPer[u]
+	return ok
+}
+
+// Length 带单位的长度
+type Length struct {
+	Value float64
+	Unit  LengthUnit
+}
+
+// Centimeters 以厘米为单位的长度
+func Centimeters(v float64) Length {
+	return Length{Value: v, Unit: Centimeter}
+}
+
+// Millimeters 以毫米为单位的长度
+func Millimeters(v float64) Length {
+	return Length{Value: v, Unit: Millimeter}
+}
+
+// Inches 以英寸为单位的长度
+func Inches(v float64) Length {
+	return Length{Value: v, Unit: Inch}
+}
+
+// In 转换为指定单位的值（不进行舍入），未知单位（包括零值的空单位）返回 NaN，需要先使用 LengthUnit.IsValid 检查
+func (l Length) In(unit LengthUnit) float64 {
+	from, ok1 := centimetersPer[l.Unit]
+	to, ok2 := centimetersPer[unit]
+	if !ok1 || !ok2 {
+		return math.NaN()
+	}
+	if l.Unit == unit {
+		return l.Value
+	}
+	return l.Value * from / to
+}
+
+// Imperial 是否为英制单位
+func (l Length) Imperial() bool {
+	return l.Unit == Inch
+}
+
+func (l Length) String() string {
+	return fmt.Sprintf("%.2f %s", l.Value, l.Unit)
+}
+
+// WeightUnit 重量单位
+type WeightUnit string
+
+const (
+	Kilogram WeightUnit = "kg" // 千克
+	Gram     WeightUnit = "g"  // 克
+	Pound    WeightUnit = "lb" // 磅
+	Ounce    WeightUnit = "oz" // 盎司
+)
+
+// 每个单位对应的千克数
+var kilogramsPer = map[WeightUnit]float64{
+	Kilogram: 1,
+	Gram:     0.001,
+	Pound:    0.45359237,
+	Ounce:    0.45359237 / 16,
+}
+
+// IsValid 是否为已知的重量单位（空字符串为未知单位）
+func (u WeightUnit) IsValid() bool {
+	_, ok := kilogramsPer[u]
+	return ok
+}
+
+// Weight 带单位的重量
+type Weight struct {
+	Value float64
+	Unit  WeightUnit
+}
+
+// Kilograms 以千克为单位的重量
+func Kilograms(v float64) Weight {
+	return Weight{Value: v, Unit: Kilogram}
+}
+
+// Grams 以克为单位的重量
+func Grams(v float64) Weight {
+	return Weight{Value: v, Unit: Gram}
+}
+
+// Pounds 以磅为单位的重量
+func Pounds(v float64) Weight {
+	return Weight{Value: v, Unit: Pound}
+}
+
+// Ounces 以盎司为单位的重量
+func Ounces(v float64) Weight {
+	return Weight{Value: v, Unit: Ounce}
+}
+
+// In 转换为指定单位的值（不进行舍入），未知单位（包括零值的空单位）返回 NaN，需要先使用 WeightUnit.IsValid 检查
+func (w Weight) In(unit WeightUnit) float64 {
+	from, ok1 := kilogramsPer[w.Unit]
+	to, ok2 := kilogramsPer[unit]
+	if !ok1 || !ok2 {
+		return math.NaN()
+	}
+	if w.Unit == unit {
+		return w.Value
+	}
+	return w.Value * from / to
+}
+
+// Imperial 是否为英制单位
+func (w Weight) Imperial() bool {
+	return w.Unit == Pound || w.Unit == Ounce
+}
+
+func (w Weight) String() string {
+	return fmt.Sprintf("%.2f %s", w.Value, w.Unit)
+}
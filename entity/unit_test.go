@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLength_In(t *testing.T) {
+	assert.Equal(t, 10.0, Inches(10).In(Inch))
+	assert.InDelta(t, 25.4, Inches(10).In(Centimeter), 1e-9)
+	assert.InDelta(t, 3.937007874, Centimeters(10).In(Inch), 1e-9)
+	assert.InDelta(t, 12.5, Millimeters(125).In(Centimeter), 1e-9)
+	assert.True(t, math.IsNaN(Length{Value: 1, Unit: "ft"}.In(Centimeter)))
+	assert.True(t, math.IsNaN(Length{Value: 1}.In(Centimeter)))
+	assert.True(t, Inch.IsValid())
+	assert.False(t, LengthUnit("").IsValid())
+	assert.False(t, LengthUnit("ft").IsValid())
+	assert.True(t, Inches(1).Imperial())
+	assert.Equal(t, "10.00 cm", Centimeters(10).String())
+}
+
+func TestWeight_In(t *testing.T) {
+	assert.InDelta(t, 0.45359237, Pounds(1).In(Kilogram), 1e-12)
+	assert.InDelta(t, 2.20462262, Kilograms(1).In(Pound), 1e-8)
+	assert.InDelta(t, 1, Ounces(16).In(Pound), 1e-12)
+	assert.InDelta(t, 0.5, Grams(500).In(Kilogram), 1e-12)
+	assert.True(t, Ounces(1).Imperial())
+	assert.False(t, Grams(1).Imperial())
+	assert.True(t, math.IsNaN(Kilograms(1).In("stone")))
+	assert.True(t, math.IsNaN(Weight{Value: 1}.In(Kilogram)))
+	assert.True(t, Ounce.IsValid())
+	assert.False(t, WeightUnit("").IsValid())
+}
@@ -1,5 +1,9 @@
 package entity
 
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
 // UserInfo 用户信息
 type UserInfo struct {
 	Code    string           `json:"code"`
@@ -22,6 +26,35 @@ type ShipperAddress struct {
 	ShipperAddress2      string `json:"shipper_address2,omitempty"` // 发件人地址2,长度不得超过35位
 }
 
+func (m ShipperAddress) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.ShipperName,
+			validation.Required.Error("发件人姓名不能为空"),
+			validation.Length(3, 35).Error("发件人姓名长度必须在 {{.min}} ~ {{.max}} 个字符"),
+		),
+		validation.Field(&m.ShipperCompany, validation.When(m.ShipperCompany != "", validation.Length(1, 35).Error("发件人公司不能超过 {{.max}} 个字符"))),
+		validation.Field(&m.ShipperTelPhone,
+			validation.Required.Error("发件人电话不能为空"),
+			validation.Length(10, 15).Error("发件人电话长度必须在 {{.min}} ~ {{.max}} 个字符"),
+		),
+		validation.Field(&m.ShipperCountry, validation.Required.Error("发件人国家不能为空")),
+		validation.Field(&m.ShipperStateProvince, validation.Required.Error("发件人州不能为空")),
+		validation.Field(&m.ShipperCity,
+			validation.Required.Error("发件人城市不能为空"),
+			validation.Length(1, 30).Error("发件人城市不能超过 {{.max}} 个字符"),
+		),
+		validation.Field(&m.ShipperPostalCode,
+			validation.Required.Error("发件人邮编不能为空"),
+			validation.Length(5, 10).Error("发件人邮编长度必须在 {{.min}} ~ {{.max}} 个字符"),
+		),
+		validation.Field(&m.ShipperAddress1,
+			validation.Required.Error("发件人地址1不能为空"),
+			validation.Length(1, 35).Error("发件人地址1长度不能超过 {{.max}} 个字符"),
+		),
+		validation.Field(&m.ShipperAddress2, validation.When(m.ShipperAddress2 != "", validation.Length(1, 35).Error("发件人地址2长度不能超过 {{.max}} 个字符"))),
+	)
+}
+
 // ReturnAddress 退件信息
 type ReturnAddress struct {
 	StreetAddress    string `json:"street_address"`      // 街道地址
@@ -0,0 +1,92 @@
+package mazon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// 错误类型，可以使用 errors.Is 判断
+var (
+	ErrInvalidToken = errors.New("无效的 Token") // 无效的 Token
+	ErrBadRequest   = errors.New("请求错误")      // 请求错误
+	ErrInternal     = errors.New("内部错误")      // 内部错误，数据库异常或者网关错误
+	ErrTimeout      = errors.New("请求超时")      // 请求超时
+)
+
+// APIError 接口返回的错误
+type APIError struct {
+	Code       int    // 错误码（接口返回的 code，或者 HTTP 状态码）
+	Message    string // 错误信息
+	HTTPStatus int    // HTTP 状态码
+	Endpoint   string // 请求的接口名称，比如 /createOrder
+	RawBody    []byte // 原始响应内容
+	Err        error  // 原始错误（比如网络超时）
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInvalidToken:
+		return e.Code == InvalidToken
+	case ErrBadRequest:
+		return e.Code == BadRequestError
+	case ErrInternal:
+		return e.Code == InternalError || e.HTTPStatus >= http.StatusInternalServerError && e.HTTPStatus != http.StatusGatewayTimeout
+	case ErrTimeout:
+		return e.Code == http.StatusRequestTimeout || e.HTTPStatus == http.StatusGatewayTimeout
+	}
+	return false
+}
+
+// FieldError 字段验证错误
+type FieldError struct {
+	Field    string // 字段名称，嵌套的字段使用 . 连接，比如 box_list.0.box_length
+	JSONPath string // 字段在请求 JSON 中的路径，比如 $.box_list[0].box_length
+	Code     string // 错误代码，比如 validation_required
+	Message  string // 错误信息
+}
+
+// ValidationError 请求参数验证错误
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// ValidateRequest 验证请求参数，验证失败时返回 *ValidationError
+//
+// 可以在提交之前对 CreateOrderRequest、RateCalcRequest 等请求进行验证，错误信息语言可以通过 WithLocale 设置。
+func ValidateRequest(ctx context.Context, req validation.Validatable) error {
+	if err := req.Validate(); err != nil {
+		return invalidInput(localeOf(ctx, nil), err)
+	}
+	return nil
+}
+
+// Field 返回指定字段（Field 或者 JSONPath）的验证错误
+func (e *ValidationError) Field(name string) (FieldError, bool) {
+	for _, field := range e.Fields {
+		if field.Field == name || field.JSONPath == name {
+			return field, true
+		}
+	}
+	return FieldError{}, false
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,139 @@
+package mazon
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	cfg := s.Config()
+	cfg.Timeout = 1
+	c := NewClient(ctx, cfg, WithTokenStore(NewMemoryTokenStore()))
+
+	tests := []struct {
+		name   string
+		fault  mazontest.Fault
+		target error
+	}{
+		{"bad request", mazontest.Fault{Code: BadRequestError, Message: "参数错误"}, ErrBadRequest},
+		{"internal error", mazontest.Fault{Code: InternalError}, ErrInternal},
+		{"bad gateway", mazontest.Fault{HTTPStatus: http.StatusBadGateway, Message: "bad gateway"}, ErrInternal},
+		{"timeout", mazontest.Fault{Latency: 1500 * time.Millisecond}, ErrTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fault.Path = "/getUserInfo"
+			tt.fault.Times = 1
+			s.Inject(tt.fault)
+			_, err := c.Services.User.Information(ctx)
+			assert.True(t, errors.Is(err, tt.target), "%v", err)
+			var apiErr *APIError
+			if assert.True(t, errors.As(err, &apiErr)) {
+				assert.Equal(t, "/getUserInfo", apiErr.Endpoint)
+			}
+		})
+	}
+
+	// 查询订单超时
+	s.Inject(mazontest.Fault{Path: "/getOrderInfo", Latency: 1500 * time.Millisecond, Times: 1})
+	_, err := c.Services.Order.Query(ctx, OrderQueryRequest{Type: 2, ReferenceNo: "TEST-ORDER"})
+	assert.ErrorIs(t, err, ErrTimeout)
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "/getOrderInfo", apiErr.Endpoint)
+	}
+
+	// 预报失败（接口返回的 code 为 200）
+	s.ForecastError = "address not found"
+	_, err = c.Services.Order.Create(ctx, CreateOrderRequest{
+		ReferenceNO:      "TEST-FORECAST-FAILED",
+		SMCode:           "USPS GA13",
+		OAFirstname:      "ZZZ",
+		OATelephone:      "0731-12345678",
+		OACountry:        "US",
+		OAState:          "CA",
+		OACity:           "Ontario",
+		OAPostcode:       "91761",
+		OAStreetAddress1: "2078 E Francis Street",
+		BoxList:          []OrderBox{{Height: 1, Length: 1, Width: 1, ActualWeight: 1}},
+		IsMoreBox:        1,
+		ShipperCode:      mazontest.ShipperCode,
+	})
+	assert.ErrorIs(t, err, ErrForecastFailed)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "/createOrder", apiErr.Endpoint)
+		assert.Equal(t, "address not found", apiErr.Message)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	boxes := make([]OrderBox, 11)
+	for i := range boxes {
+		boxes[i] = OrderBox{Length: 1, Width: 1, Height: 1, ActualWeight: 1}
+	}
+	boxes[2].ActualWeight = 0
+	boxes[10].Length = 0
+	_, err := client.Services.Order.Create(ctx, CreateOrderRequest{
+		SMCode:         "USPS GA13",
+		BoxList:        boxes,
+		ShipperAddress: &entity.ShipperAddress{ShipperName: "ZZZ", ShipperStateProvince: "ca", ShipperCountry: "CA"},
+	})
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		paths := make([]string, len(validationErr.Fields))
+		for i, field := range validationErr.Fields {
+			paths[i] = field.JSONPath
+		}
+		assert.Contains(t, paths, "$.reference_no")
+		assert.NotContains(t, paths, "$.sm_code")
+		assert.Less(t, slices.Index(paths, "$.box_list[2].box_actual_weight"), slices.Index(paths, "$.box_list[10].box_length"))
+
+		field, ok := validationErr.Field("box_list.2.box_actual_weight")
+		if assert.True(t, ok) {
+			assert.Equal(t, "$.box_list[2].box_actual_weight", field.JSONPath)
+			assert.Equal(t, "validation_required", field.Code)
+		}
+		_, ok = validationErr.Field("$.shipper_address.shipper_city")
+		assert.True(t, ok)
+		_, ok = validationErr.Field("shipper_address.shipper_name")
+		assert.False(t, ok)
+		// 不限制发件人的国家和州的格式（可以通过 WithAddressNormalizer 规范化）
+		_, ok = validationErr.Field("shipper_address.shipper_country")
+		assert.False(t, ok)
+		_, ok = validationErr.Field("shipper_address.shipper_state_province")
+		assert.False(t, ok)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	req := RateCalcRequest{
+		ReferenceNO:      "TEST-VALIDATE",
+		SMCode:           "USPS GA13",
+		OAFirstname:      "ZZZ",
+		OATelephone:      "0731-12345678",
+		OACountry:        "US",
+		OAState:          "CA",
+		OACity:           "Ontario",
+		OAPostcode:       "91761",
+		OAStreetAddress1: "2078 E Francis Street",
+		BoxList:          []RateCalcOrderBox{{Length: 1, Width: 1, Height: 1, ActualWeight: 1}},
+		ShipperCode:      "S0004",
+	}
+	assert.Nil(t, ValidateRequest(ctx, req))
+
+	req.BoxList[0].Width = 0
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(ValidateRequest(ctx, req), &validationErr)) {
+		assert.Equal(t, "$.box_list[0].box_width", validationErr.Fields[0].JSONPath)
+	}
+}
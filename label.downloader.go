@@ -0,0 +1,350 @@
+package mazon
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hiscaler/mazon-go/config"
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+var (
+	ErrEmptyLabelFile        = errors.New("面单文件为空")
+	ErrLabelFormatMismatch   = errors.New("面单文件格式与面单类型不一致")
+	ErrInvalidLabelFileName  = errors.New("无效的面单文件名称")
+	ErrLabelFileSizeMismatch = errors.New("面单文件大小与响应头不一致")
+)
+
+// 面单文件格式
+const (
+	LabelFormatPDF = "PDF"
+	LabelFormatZPL = "ZPL"
+	LabelFormatPNG = "PNG"
+)
+
+// LabelFileKind 面单文件类型
+type LabelFileKind string
+
+const (
+	LabelFileLabel      LabelFileKind = "label"       // 面单
+	LabelFileMergeLabel LabelFileKind = "merge_label" // 合并面单
+	LabelFileScanForm   LabelFileKind = "scan_form"   // ScanForm
+)
+
+// LabelFile 已下载的面单文件信息
+type LabelFile struct {
+	Kind           LabelFileKind `json:"kind"`            // 文件类型
+	OrderCode      string        `json:"order_code"`      // 订单号
+	ReferenceNo    string        `json:"reference_no"`    // 参考号
+	TrackingNumber string        `json:"tracking_number"` // 物流单号（合并面单和 ScanForm 为空）
+	SMCode         string        `json:"sm_code"`         // 物流产品代码（面单接口不返回，需要通过 LabelFiles.SetSMCode 设置）
+	URL            string        `json:"url"`             // 下载地址
+	Format         string        `json:"format"`          // 文件格式（PDF、ZPL、PNG）
+	Name           string        `json:"name"`            // 存储名称
+	Location       string        `json:"location"`        // 存储位置
+	Size           int64         `json:"size"`            // 文件大小（字节）
+	SHA256         string        `json:"sha256"`          // SHA-256 校验值
+	DownloadedAt   time.Time     `json:"downloaded_at"`   // 下载时间
+}
+
+// LabelFiles 面单文件列表
+type LabelFiles []LabelFile
+
+// ByTrackingNumber 以物流单号为键返回面单文件（不包含合并面单和 ScanForm）
+func (files LabelFiles) ByTrackingNumber() map[string]LabelFile {
+	m := make(map[string]LabelFile, len(files))
+	for _, file := range files {
+		if file.Kind == LabelFileLabel && file.TrackingNumber != "" {
+			m[file.TrackingNumber] = file
+		}
+	}
+	return m
+}
+
+// SetSMCode 设置面单文件的物流产品代码，smCodes 的键可以为物流单号、订单号或者参考号（依次匹配），
+// 比如以参考号为键的下单时的物流产品代码
+func (files LabelFiles) SetSMCode(smCodes map[string]string) LabelFiles {
+	for i, file := range files {
+		for _, key := range []string{file.TrackingNumber, file.OrderCode, file.ReferenceNo} {
+			if smCode, ok := smCodes[key]; ok && key != "" {
+				files[i].SMCode = smCode
+				break
+			}
+		}
+	}
+	return files
+}
+
+// LabelStorage 面单文件存储接口
+type LabelStorage interface {
+	// Save 保存文件，name 为使用 / 分隔的相对路径，返回文件的存储位置
+	Save(ctx context.Context, name string, data []byte) (location string, err error)
+}
+
+var _ LabelStorage = (*LocalLabelStorage)(nil)
+
+// LocalLabelStorage 本地文件系统存储
+type LocalLabelStorage struct {
+	dir string // 存储目录
+}
+
+func NewLocalLabelStorage(dir string) *LocalLabelStorage {
+	return &LocalLabelStorage{dir: dir}
+}
+
+func (s *LocalLabelStorage) Save(_ context.Context, name string, data []byte) (string, error) {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))[1:]
+	if name == "" || name == "." {
+		return "", ErrInvalidLabelFileName
+	}
+
+	filename := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", err
+	}
+	// 先写入临时文件再重命名，避免产生不完整的文件
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".label-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return "", err
+	}
+	return filepath.Abs(filename)
+}
+
+// LabelFileNamer 生成面单文件的存储名称，无法生成时返回错误（文件不会保存）
+type LabelFileNamer func(file LabelFile) (string, error)
+
+// DefaultLabelFileName 默认的存储名称
+//
+//	面单：{订单号}/{物流单号}.pdf
+//	合并面单：{订单号}/{订单号}-merged.pdf
+//	ScanForm：scanforms/{URL 中的文件名}
+//
+// 订单号为空时使用参考号，面单没有物流单号时依次使用订单号、参考号作为文件名，都为空时返回 ErrInvalidLabelFileName。
+func DefaultLabelFileName(file LabelFile) (string, error) {
+	ext := strings.ToLower(file.Format)
+	if ext == "" {
+		ext = "bin"
+	}
+	if file.Kind == LabelFileScanForm {
+		name := "scanform"
+		if u, err := url.Parse(file.URL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+			name = strings.TrimSuffix(path.Base(u.Path), path.Ext(u.Path))
+		}
+		return path.Join("scanforms", name+"."+ext), nil
+	}
+
+	dir := cmp.Or(file.OrderCode, file.ReferenceNo)
+	if file.Kind == LabelFileMergeLabel {
+		if dir == "" {
+			return "", fmt.Errorf("%w: 合并面单缺少订单号和参考号", ErrInvalidLabelFileName)
+		}
+		return path.Join(dir, dir+"-merged."+ext), nil
+	}
+	name := cmp.Or(file.TrackingNumber, file.OrderCode, file.ReferenceNo)
+	if name == "" {
+		return "", fmt.Errorf("%w: 面单缺少物流单号、订单号和参考号", ErrInvalidLabelFileName)
+	}
+	return path.Join(dir, name+"."+ext), nil
+}
+
+// LabelDownloader 面单文件下载器，下载面单、合并面单和 ScanForm 文件并保存到存储中
+type LabelDownloader struct {
+	config     *config.Config
+	httpClient *resty.Client
+	storage    LabelStorage
+	namer      LabelFileNamer
+}
+
+// NewLabelDownloader 创建面单文件下载器，使用客户端的 HTTP 设置（Transport、超时等）进行下载
+func NewLabelDownloader(client *Client, storage LabelStorage) *LabelDownloader {
+	httpClient := resty.NewWithClient(client.httpClient.GetClient()).
+		SetLogger(client.logger).
+		SetHeader("User-Agent", userAgent).
+		SetRetryCount(2).
+		SetRetryWaitTime(2 * time.Second).
+		SetRetryMaxWaitTime(10 * time.Second).
+		AddRetryCondition(func(response *resty.Response, err error) bool {
+			return err != nil || response.StatusCode() >= http.StatusInternalServerError
+		})
+	return &LabelDownloader{
+		config:     client.config,
+		httpClient: httpClient,
+		storage:    storage,
+		namer:      DefaultLabelFileName,
+	}
+}
+
+// SetNamer 设置存储名称的生成规则
+func (d *LabelDownloader) SetNamer(namer LabelFileNamer) *LabelDownloader {
+	d.namer = namer
+	return d
+}
+
+// DetectLabelFormat 根据文件内容检测面单格式，无法识别时返回空字符串
+func DetectLabelFormat(data []byte) string {
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return LabelFormatPDF
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return LabelFormatPNG
+	case bytes.HasPrefix(data, []byte("^XA")), bytes.HasPrefix(data, []byte("~")) && bytes.Contains(data, []byte("^XA")):
+		return LabelFormatZPL
+	}
+	return ""
+}
+
+// Download 下载文件，file 中需要设置 Kind、URL，其他信息（订单号、物流单号、面单类型）用于生成存储名称
+func (d *LabelDownloader) Download(ctx context.Context, file LabelFile) (LabelFile, error) {
+	locale := localeOf(ctx, d.config)
+	resp, err := d.httpClient.R().
+		SetContext(ctx).
+		Get(file.URL)
+	if err != nil {
+		if isTimeout(err) {
+			return file, &APIError{Code: http.StatusRequestTimeout, Message: translate(locale, "请求超时"), Endpoint: file.URL, Err: err}
+		}
+		return file, err
+	}
+	if resp.IsError() {
+		return file, &APIError{
+			Code:       resp.StatusCode(),
+			Message:    http.StatusText(resp.StatusCode()),
+			HTTPStatus: resp.StatusCode(),
+			Endpoint:   file.URL,
+			RawBody:    resp.Body(),
+		}
+	}
+
+	data := resp.Body()
+	if len(data) == 0 {
+		return file, fmt.Errorf("%w: %s", localize(locale, ErrEmptyLabelFile), file.URL)
+	}
+	if resp.RawResponse != nil && resp.RawResponse.ContentLength > 0 && resp.RawResponse.ContentLength != int64(len(data)) {
+		return file, fmt.Errorf("%w: %s", localize(locale, ErrLabelFileSizeMismatch), file.URL)
+	}
+
+	// 以文件内容为准，面单类型仅用于校验
+	expected := strings.ToUpper(strings.TrimSpace(file.Format))
+	detected := DetectLabelFormat(data)
+	if expected != "" && detected != "" && expected != detected {
+		return file, fmt.Errorf("%w: %s, %s != %s", localize(locale, ErrLabelFormatMismatch), file.URL, expected, detected)
+	}
+	if detected == "" && expected != "" && strings.HasPrefix(http.DetectContentType(data), "text/html") {
+		// 通常为错误页面
+		return file, fmt.Errorf("%w: %s, %s != HTML", localize(locale, ErrLabelFormatMismatch), file.URL, expected)
+	}
+	if detected != "" {
+		file.Format = detected
+	} else {
+		file.Format = expected
+	}
+
+	sum := sha256.Sum256(data)
+	file.SHA256 = hex.EncodeToString(sum[:])
+	file.Size = int64(len(data))
+	file.DownloadedAt = time.Now()
+	// 翻译 DefaultLabelFileName、LocalLabelStorage 返回的错误
+	if file.Name, err = d.namer(file); err != nil {
+		return file, localize(locale, err)
+	}
+	if file.Location, err = d.storage.Save(ctx, file.Name, data); err != nil {
+		return file, localize(locale, err)
+	}
+	return file, nil
+}
+
+// download 依次下载文件，遇到错误时返回已下载的文件和错误
+func (d *LabelDownloader) download(ctx context.Context, files []LabelFile) (LabelFiles, error) {
+	downloaded := make(LabelFiles, 0, len(files))
+	for _, file := range files {
+		if file.URL == "" {
+			continue
+		}
+		f, err := d.Download(ctx, file)
+		if err != nil {
+			return downloaded, err
+		}
+		downloaded = append(downloaded, f)
+	}
+	return downloaded, nil
+}
+
+// DownloadShippingLabel 下载面单（ShippingLabel.Detail 的返回结果）中的所有面单和合并面单
+func (d *LabelDownloader) DownloadShippingLabel(ctx context.Context, label entity.ShippingLabel) (LabelFiles, error) {
+	files := make([]LabelFile, 0, len(label.Labels)+1)
+	for _, l := range label.Labels {
+		files = append(files, LabelFile{
+			Kind:           LabelFileLabel,
+			OrderCode:      label.OrderCode,
+			ReferenceNo:    label.ReferenceNo,
+			TrackingNumber: l.TrackingNumber,
+			URL:            l.LabelUrl,
+			Format:         l.FileType,
+		})
+	}
+	files = append(files, LabelFile{
+		Kind:        LabelFileMergeLabel,
+		OrderCode:   label.OrderCode,
+		ReferenceNo: label.ReferenceNo,
+		URL:         label.MergeLabel,
+	})
+	return d.download(ctx, files)
+}
+
+// DownloadLogisticsLabels 下载面单信息（ShippingLabel.Query 的返回结果）中的所有面单和合并面单
+func (d *LabelDownloader) DownloadLogisticsLabels(ctx context.Context, labels ...entity.LogisticsLabel) (LabelFiles, error) {
+	files := make([]LabelFile, 0)
+	for _, label := range labels {
+		for _, l := range label.Labels {
+			files = append(files, LabelFile{
+				Kind:           LabelFileLabel,
+				OrderCode:      label.OrderCode,
+				ReferenceNo:    label.ReferenceNo,
+				TrackingNumber: l.TrackingNumber,
+				URL:            l.LabelUrl,
+				Format:         l.FileType,
+			})
+		}
+		files = append(files, LabelFile{
+			Kind:        LabelFileMergeLabel,
+			OrderCode:   label.OrderCode,
+			ReferenceNo: label.ReferenceNo,
+			URL:         label.MergeLabel,
+		})
+	}
+	return d.download(ctx, files)
+}
+
+// DownloadScanForms 下载 ScanForm 文件
+func (d *LabelDownloader) DownloadScanForms(ctx context.Context, forms ...entity.ScanForm) (LabelFiles, error) {
+	files := make([]LabelFile, len(forms))
+	for i, form := range forms {
+		files[i] = LabelFile{Kind: LabelFileScanForm, URL: form.Url, Format: LabelFormatPDF}
+	}
+	return d.download(ctx, files)
+}
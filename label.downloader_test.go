@@ -0,0 +1,120 @@
+package mazon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLabelFormat(t *testing.T) {
+	assert.Equal(t, LabelFormatPDF, DetectLabelFormat(mazontest.LabelPDF("1")))
+	assert.Equal(t, LabelFormatZPL, DetectLabelFormat(mazontest.LabelZPL("1")))
+	assert.Equal(t, LabelFormatZPL, DetectLabelFormat([]byte("~SD20\n^XA^XZ")))
+	assert.Equal(t, LabelFormatPNG, DetectLabelFormat([]byte("\x89PNG\r\n\x1a\n...")))
+	assert.Equal(t, "", DetectLabelFormat([]byte("<html></html>")))
+}
+
+func TestLabelDownloader(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	s.AddOrder(mazontest.Order{
+		Order:  entity.Order{ReferenceNo: "TEST-DOWNLOAD", OrderCode: "EPB00120250912114236000099"},
+		SMCode: "USPS GA13",
+	}, "9234690397703300000001", "9234690397703300000002")
+	c := NewClient(ctx, s.Config(), WithTokenStore(NewMemoryTokenStore()))
+	dir := t.TempDir()
+	d := NewLabelDownloader(c, NewLocalLabelStorage(dir))
+
+	label, err := c.Services.ShippingLabel.Detail(ctx, ShippingLabelDetailRequest{OrderCode: "EPB00120250912114236000099"})
+	assert.Nil(t, err)
+	files, err := d.DownloadShippingLabel(ctx, label)
+	assert.Nil(t, err)
+	assert.Len(t, files, 3)
+	assert.Equal(t, LabelFileMergeLabel, files[2].Kind)
+	assert.Equal(t, "EPB00120250912114236000099/EPB00120250912114236000099-merged.pdf", files[2].Name)
+
+	file, ok := files.ByTrackingNumber()["9234690397703300000002"]
+	assert.True(t, ok)
+	assert.Equal(t, LabelFormatPDF, file.Format)
+	assert.Equal(t, filepath.Join(dir, "EPB00120250912114236000099", "9234690397703300000002.pdf"), file.Location)
+	b, err := os.ReadFile(file.Location)
+	assert.Nil(t, err)
+	sum := sha256.Sum256(b)
+	assert.Equal(t, hex.EncodeToString(sum[:]), file.SHA256)
+	assert.Equal(t, int64(len(b)), file.Size)
+
+	// 自定义存储名称
+	d.SetNamer(func(file LabelFile) (string, error) { return "labels/" + file.TrackingNumber + ".pdf", nil })
+	labels, err := c.Services.ShippingLabel.Query(ctx, "9234690397703300000001")
+	assert.Nil(t, err)
+	files, err = d.DownloadLogisticsLabels(ctx, labels...)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "labels", "9234690397703300000001.pdf"), files[0].Location)
+	d.SetNamer(DefaultLabelFileName)
+
+	// 面单类型与文件内容不一致
+	s.SetFile("/labels/9234690397703300000001.pdf", []byte("<html><body>error</body></html>"))
+	_, err = d.Download(ctx, LabelFile{Kind: LabelFileLabel, URL: s.URL + "/labels/9234690397703300000001.pdf", Format: "PDF"})
+	assert.True(t, errors.Is(err, ErrLabelFormatMismatch))
+	s.SetFile("/labels/9234690397703300000001.pdf", []byte{})
+	_, err = d.Download(ctx, LabelFile{Kind: LabelFileLabel, URL: s.URL + "/labels/9234690397703300000001.pdf", Format: "PDF"})
+	assert.True(t, errors.Is(err, ErrEmptyLabelFile))
+
+	// 文件不存在
+	_, err = d.Download(ctx, LabelFile{Kind: LabelFileLabel, URL: s.URL + "/labels/none.pdf"})
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 404, apiErr.HTTPStatus)
+
+	// ScanForm
+	forms, err := c.Services.ScanForm.Create(ctx, "9234690397703300000001")
+	assert.Nil(t, err)
+	files, err = d.DownloadScanForms(ctx, forms...)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, LabelFileScanForm, files[0].Kind)
+	assert.Equal(t, LabelFormatPDF, files[0].Format)
+}
+
+func TestDefaultLabelFileName(t *testing.T) {
+	tests := []struct {
+		file LabelFile
+		want string
+	}{
+		{LabelFile{Kind: LabelFileLabel, OrderCode: "O1", ReferenceNo: "R1", TrackingNumber: "T1", Format: "PDF"}, "O1/T1.pdf"},
+		{LabelFile{Kind: LabelFileLabel, ReferenceNo: "R1", TrackingNumber: "T1", Format: "ZPL"}, "R1/T1.zpl"},
+		{LabelFile{Kind: LabelFileLabel, OrderCode: "O1", ReferenceNo: "R1", Format: "PDF"}, "O1/O1.pdf"},
+		{LabelFile{Kind: LabelFileLabel, ReferenceNo: "R1", Format: "PDF"}, "R1/R1.pdf"},
+		{LabelFile{Kind: LabelFileLabel, TrackingNumber: "T1"}, "T1.bin"},
+		{LabelFile{Kind: LabelFileMergeLabel, ReferenceNo: "R1", Format: "PDF"}, "R1/R1-merged.pdf"},
+		{LabelFile{Kind: LabelFileScanForm, URL: "https://example.com/forms/a.pdf", Format: "PDF"}, "scanforms/a.pdf"},
+	}
+	for _, tt := range tests {
+		name, err := DefaultLabelFileName(tt.file)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, name)
+	}
+
+	for _, kind := range []LabelFileKind{LabelFileLabel, LabelFileMergeLabel} {
+		_, err := DefaultLabelFileName(LabelFile{Kind: kind, Format: "PDF"})
+		assert.ErrorIs(t, err, ErrInvalidLabelFileName, kind)
+	}
+}
+
+func TestLocalLabelStorage_Save(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalLabelStorage(dir)
+	location, err := storage.Save(ctx, "../../a/b.pdf", []byte("%PDF-"))
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "a", "b.pdf"), location)
+
+	_, err = storage.Save(ctx, "..", []byte("%PDF-"))
+	assert.True(t, errors.Is(err, ErrInvalidLabelFileName))
+}
@@ -0,0 +1,394 @@
+package labelpdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+var objectHeader = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+(\d+)[\x00\t\n\f\r ]+obj`)
+
+// document 解析后的 PDF 文档
+//
+// 为了兼容交叉引用表损坏的文件，不读取交叉引用表，而是顺序扫描文件中的所有对象，同一个对象号以文件中最后的定义为准（兼容增量更新），
+// 对象流中的对象以对象流在文件中的位置为准。不区分对象的版本号（generation），间接引用总是指向对象号最后的定义。
+type document struct {
+	objects map[int]any
+	offsets map[int]int // 对象定义在文件中的位置（对象流中的对象为对象流的位置）
+	root    dict
+}
+
+func parseDocument(data []byte) (*document, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\f\r "), []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: 缺少 PDF 文件头", errSyntax)
+	}
+
+	d := &document{objects: make(map[int]any), offsets: make(map[int]int)}
+	pos := 0
+	for pos < len(data) {
+		loc := objectHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		if start > 0 && isRegular(data[start-1]) || end < len(data) && isRegular(data[end]) {
+			pos = end
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		p := &parser{data: data, pos: end}
+		v, err := p.parse()
+		if err != nil {
+			pos = end
+			continue
+		}
+		d.objects[num] = v
+		d.offsets[num] = start
+		pos = p.pos
+	}
+	if err := d.loadObjectStreams(); err != nil {
+		return nil, err
+	}
+
+	// 根对象：trailer 或者交叉引用流中的 Root，都没有时查找 Catalog
+	for _, loc := range regexp.MustCompile(`trailer[\x00\t\n\f\r ]*<<`).FindAllIndex(data, -1) {
+		p := &parser{data: data, pos: loc[1] - 2}
+		if trailer, err := p.parse(); err == nil {
+			if t, ok := trailer.(dict); ok {
+				if root, ok := d.resolve(t["Root"]).(dict); ok {
+					d.root = root
+				}
+			}
+		}
+	}
+	if d.root == nil {
+		for _, num := range d.sortedNumbers() {
+			if s, ok := d.objects[num].(*stream); ok && s.dict["Type"] == name("XRef") {
+				if root, ok := d.resolve(s.dict["Root"]).(dict); ok {
+					d.root = root
+				}
+			}
+		}
+	}
+	if d.root == nil {
+		for _, num := range d.sortedNumbers() {
+			if obj, ok := d.objects[num].(dict); ok && obj["Type"] == name("Catalog") {
+				d.root = obj
+				break
+			}
+		}
+	}
+	if d.root == nil {
+		return nil, fmt.Errorf("%w: 没有找到文档根对象", errSyntax)
+	}
+	return d, nil
+}
+
+func (d *document) sortedNumbers() []int {
+	numbers := make([]int, 0, len(d.objects))
+	for num := range d.objects {
+		numbers = append(numbers, num)
+	}
+	slices.Sort(numbers)
+	return numbers
+}
+
+// loadObjectStreams 读取对象流（PDF 1.5）中的对象，文件中位于对象流之后的定义优先
+func (d *document) loadObjectStreams() error {
+	for _, num := range d.sortedNumbers() {
+		s, ok := d.objects[num].(*stream)
+		if !ok || s.dict["Type"] != name("ObjStm") {
+			continue
+		}
+		data, err := d.decode(s)
+		if err != nil {
+			return err
+		}
+		n, _ := d.resolve(s.dict["N"]).(int64)
+		first, _ := d.resolve(s.dict["First"]).(int64)
+		if first < 0 || int(first) > len(data) {
+			return fmt.Errorf("%w: 无效的对象流 %d", errSyntax, num)
+		}
+		header := &parser{data: data[:first]}
+		for i := int64(0); i < n; i++ {
+			objNum, err1 := header.parse()
+			offset, err2 := header.parse()
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("%w: 无效的对象流 %d", errSyntax, num)
+			}
+			objNumber, ok1 := objNum.(int64)
+			objOffset, ok2 := offset.(int64)
+			if !ok1 || !ok2 || first+objOffset > int64(len(data)) {
+				return fmt.Errorf("%w: 无效的对象流 %d", errSyntax, num)
+			}
+			if offset, exists := d.offsets[int(objNumber)]; exists && offset > d.offsets[num] {
+				continue
+			}
+			p := &parser{data: data, pos: int(first + objOffset)}
+			v, err := p.parse()
+			if err != nil {
+				return err
+			}
+			d.objects[int(objNumber)] = v
+			d.offsets[int(objNumber)] = d.offsets[num]
+		}
+	}
+	return nil
+}
+
+// resolve 返回间接引用指向的对象
+func (d *document) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		r, ok := v.(ref)
+		if !ok {
+			return v
+		}
+		v = d.objects[r.num]
+	}
+	return nil
+}
+
+// decode 解码流数据，仅支持 FlateDecode（包括 PNG 预测函数）
+func (d *document) decode(s *stream) ([]byte, error) {
+	var filters, params array
+	switch v := d.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = array{v}
+		params = array{d.resolve(s.dict["DecodeParms"])}
+	case array:
+		filters = v
+		params, _ = d.resolve(s.dict["DecodeParms"]).(array)
+	}
+
+	data := s.data
+	for i, filter := range filters {
+		if d.resolve(filter) != name("FlateDecode") {
+			return nil, fmt.Errorf("%w: 不支持的解码方式 %v", errSyntax, filter)
+		}
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := io.ReadAll(r)
+		if err != nil && len(decoded) == 0 {
+			return nil, err
+		}
+		data = decoded
+		if i < len(params) {
+			if param, ok := d.resolve(params[i]).(dict); ok {
+				if data, err = d.unpredict(data, param); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return data, nil
+}
+
+// unpredict 还原 PNG 预测函数
+func (d *document) unpredict(data []byte, param dict) ([]byte, error) {
+	predictor, _ := d.resolve(param["Predictor"]).(int64)
+	if predictor < 10 {
+		return data, nil
+	}
+	intParam := func(key name, def int64) int {
+		if v, ok := d.resolve(param[key]).(int64); ok {
+			return int(v)
+		}
+		return int(def)
+	}
+	bpp := max(intParam("Colors", 1)*intParam("BitsPerComponent", 8)/8, 1)
+	rowLen := (intParam("Columns", 1)*intParam("Colors", 1)*intParam("BitsPerComponent", 8) + 7) / 8
+	if rowLen <= 0 || len(data)%(rowLen+1) != 0 {
+		return nil, fmt.Errorf("%w: 无效的预测函数参数", errSyntax)
+	}
+
+	out := make([]byte, 0, len(data)/(rowLen+1)*rowLen)
+	prev := make([]byte, rowLen)
+	for i := 0; i < len(data); i += rowLen + 1 {
+		typ, row := data[i], slices.Clone(data[i+1:i+1+rowLen])
+		for j := range row {
+			var left, upLeft byte
+			if j >= bpp {
+				left, upLeft = row[j-bpp], prev[j-bpp]
+			}
+			up := prev[j]
+			switch typ {
+			case 1:
+				row[j] += left
+			case 2:
+				row[j] += up
+			case 3:
+				row[j] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[j] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// page 页面（已合并继承的属性）
+type page struct {
+	resources any
+	box       rect  // 可见区域（CropBox 与 MediaBox 的交集）
+	rotate    int   // 顺时针旋转角度（0、90、180、270）
+	contents  []ref // 内容流
+}
+
+// pages 按顺序返回所有页面
+func (d *document) pages() ([]page, error) {
+	var pages []page
+	visited := make(map[any]bool)
+	var walk func(node dict, inherited dict, depth int) error
+	walk = func(node dict, inherited dict, depth int) error {
+		if depth > 64 {
+			return fmt.Errorf("%w: 页面树层级过深", errSyntax)
+		}
+		attrs := dict{}
+		for k, v := range inherited {
+			attrs[k] = v
+		}
+		for _, k := range []name{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if v, ok := node[k]; ok {
+				attrs[k] = v
+			}
+		}
+
+		if node["Type"] == name("Page") || node["Kids"] == nil {
+			pages = append(pages, d.page(node, attrs))
+			return nil
+		}
+		kids, _ := d.resolve(node["Kids"]).(array)
+		for _, kid := range kids {
+			if r, ok := kid.(ref); ok {
+				if visited[r] {
+					continue
+				}
+				visited[r] = true
+			}
+			if child, ok := d.resolve(kid).(dict); ok {
+				if err := walk(child, attrs, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	root, ok := d.resolve(d.root["Pages"]).(dict)
+	if !ok {
+		return nil, fmt.Errorf("%w: 没有找到页面", errSyntax)
+	}
+	if err := walk(root, dict{}, 0); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+func (d *document) page(node dict, attrs dict) page {
+	pg := page{resources: attrs["Resources"]}
+	pg.box = d.rect(attrs["MediaBox"])
+	if pg.box.empty() {
+		pg.box = rect{0, 0, 612, 792}
+	}
+	if crop := d.rect(attrs["CropBox"]); !crop.empty() {
+		pg.box = pg.box.intersect(crop)
+	}
+	if rotate, ok := d.resolve(attrs["Rotate"]).(int64); ok {
+		pg.rotate = int((rotate%360 + 360) % 360 / 90 * 90)
+	}
+
+	switch v := node["Contents"].(type) {
+	case ref:
+		if arr, ok := d.resolve(v).(array); ok {
+			for _, item := range arr {
+				if r, ok := item.(ref); ok {
+					pg.contents = append(pg.contents, r)
+				}
+			}
+		} else {
+			pg.contents = append(pg.contents, v)
+		}
+	case array:
+		for _, item := range v {
+			if r, ok := item.(ref); ok {
+				pg.contents = append(pg.contents, r)
+			}
+		}
+	}
+	return pg
+}
+
+func (d *document) number(v any) (float64, bool) {
+	switch n := d.resolve(v).(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func (d *document) rect(v any) rect {
+	arr, ok := d.resolve(v).(array)
+	if !ok || len(arr) != 4 {
+		return rect{}
+	}
+	var values [4]float64
+	for i, item := range arr {
+		if values[i], ok = d.number(item); !ok {
+			return rect{}
+		}
+	}
+	return rect{
+		min(values[0], values[2]), min(values[1], values[3]),
+		max(values[0], values[2]), max(values[1], values[3]),
+	}
+}
+
+// rect 矩形区域（左下角和右上角坐标）
+type rect struct {
+	x0, y0, x1, y1 float64
+}
+
+func (r rect) width() float64 {
+	return r.x1 - r.x0
+}
+
+func (r rect) height() float64 {
+	return r.y1 - r.y0
+}
+
+func (r rect) empty() bool {
+	return r.width() <= 0 || r.height() <= 0
+}
+
+func (r rect) intersect(other rect) rect {
+	return rect{max(r.x0, other.x0), max(r.y0, other.y0), min(r.x1, other.x1), min(r.y1, other.y1)}
+}
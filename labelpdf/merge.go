@@ -0,0 +1,207 @@
+// Package labelpdf 将多个 PDF 面单合并为一个可以直接打印的文档
+//
+// 美正接口返回的合并面单（merge_label）只包含一个订单的面单，批量拣货时需要将多个订单的面单合并后一次打印：
+//
+//	// files 为 mazon.LabelDownloader 下载的面单文件，需要裁剪 UPS 面单时设置物流产品代码（比如以参考号为键）
+//	labels, err := labelpdf.FromLabelFiles(files.SetSMCode(map[string]string{"REF-1": "UPS GROUND"}))
+//	b, err := labelpdf.Merge(labels, labelpdf.Options{
+//		CropUPS: true,
+//		SortKey: func(label labelpdf.Label) string { return label.ReferenceNo },
+//	})
+//
+// 所有页面都会缩放（必要时旋转）到 4x6 英寸。
+package labelpdf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hiscaler/mazon-go"
+)
+
+// 4x6 英寸（单位：point）
+const (
+	Width4x6  = 288
+	Height4x6 = 432
+)
+
+var (
+	ErrNoLabels   = errors.New("没有需要合并的面单")
+	ErrInvalidPDF = errors.New("无效的 PDF 面单")
+)
+
+// Label 需要合并的面单
+type Label struct {
+	TrackingNumber string // 物流单号
+	ReferenceNo    string // 参考号
+	OrderCode      string // 订单号
+	SMCode         string // 物流产品代码，以 UPS 开头时视为 UPS 面单
+	SortKey        string // 排序键（比如 SKU），Options.SortKey 为空时使用
+	Data           []byte // PDF 文件内容
+}
+
+func (l Label) isUPS() bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(l.SMCode)), "UPS")
+}
+
+// Options 合并设置
+type Options struct {
+	Width  float64 // 页面宽度（单位：point），默认 4 英寸
+	Height float64 // 页面高度（单位：point），默认 6 英寸
+	// CropUPS 是否裁剪 UPS 面单（同 CreateOrderRequest.HasUpsLabelCropped），
+	// 仅对未裁剪的 UPS 面单（Letter、A4 等大尺寸页面）生效，保留页面上半部分的面单区域
+	CropUPS bool
+	// SortKey 返回面单的排序键，比如 SKU、参考号，为空时使用 Label.SortKey，
+	// 排序键相同的面单保持原顺序，同一面单的多个页面不会被拆开
+	SortKey func(label Label) string
+}
+
+// FromLabelFiles 读取下载到本地的面单文件（mazon.LocalLabelStorage），忽略合并面单、ScanForm 和非 PDF 格式的面单，
+// 面单的物流产品代码为 LabelFile.SMCode（通过 mazon.LabelFiles.SetSMCode 设置）
+func FromLabelFiles(files mazon.LabelFiles) ([]Label, error) {
+	labels := make([]Label, 0, len(files))
+	for _, file := range files {
+		if file.Kind != mazon.LabelFileLabel || file.Format != mazon.LabelFormatPDF {
+			continue
+		}
+		b, err := os.ReadFile(file.Location)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, Label{
+			TrackingNumber: file.TrackingNumber,
+			ReferenceNo:    file.ReferenceNo,
+			OrderCode:      file.OrderCode,
+			SMCode:         file.SMCode,
+			Data:           b,
+		})
+	}
+	return labels, nil
+}
+
+// Merge 合并面单，返回合并后的 PDF 文件内容
+func Merge(labels []Label, opts Options) ([]byte, error) {
+	if len(labels) == 0 {
+		return nil, ErrNoLabels
+	}
+	if opts.Width <= 0 {
+		opts.Width = Width4x6
+	}
+	if opts.Height <= 0 {
+		opts.Height = Height4x6
+	}
+
+	labels = slices.Clone(labels)
+	key := opts.SortKey
+	if key == nil {
+		key = func(label Label) string { return label.SortKey }
+	}
+	slices.SortStableFunc(labels, func(a, b Label) int {
+		return strings.Compare(key(a), key(b))
+	})
+
+	w := newWriter()
+	catalog, pagesRef := w.reserve(), w.reserve()
+	kids := array{}
+	for _, label := range labels {
+		doc, err := parseDocument(label.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s, %s", ErrInvalidPDF, label.TrackingNumber, err.Error())
+		}
+		pages, err := doc.pages()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s, %s", ErrInvalidPDF, label.TrackingNumber, err.Error())
+		}
+		for _, pg := range pages {
+			box := pg.box
+			if opts.CropUPS && label.isUPS() {
+				box = cropUPS(box, pg.rotate)
+			}
+			m := fit(box, pg.rotate, opts.Width, opts.Height)
+			prefix := fmt.Sprintf("q %s %s %s %s %s %s cm %s %s %s %s re W n\n",
+				formatNumber(m[0]), formatNumber(m[1]), formatNumber(m[2]), formatNumber(m[3]), formatNumber(m[4]), formatNumber(m[5]),
+				formatNumber(box.x0), formatNumber(box.y0), formatNumber(box.width()), formatNumber(box.height()),
+			)
+			contents := array{w.add(&stream{dict: dict{}, data: []byte(prefix)})}
+			for _, r := range pg.contents {
+				contents = append(contents, w.copy(doc, r))
+			}
+			contents = append(contents, w.add(&stream{dict: dict{}, data: []byte("\nQ\n")}))
+
+			resources := w.copy(doc, pg.resources)
+			if resources == nil {
+				resources = dict{}
+			}
+			kids = append(kids, w.add(dict{
+				"Type":      name("Page"),
+				"Parent":    pagesRef,
+				"MediaBox":  array{int64(0), int64(0), opts.Width, opts.Height},
+				"Resources": resources,
+				"Contents":  contents,
+			}))
+		}
+	}
+	w.set(pagesRef, dict{"Type": name("Pages"), "Kids": kids, "Count": int64(len(kids))})
+	w.set(catalog, dict{"Type": name("Catalog"), "Pages": pagesRef})
+	return w.bytes(catalog), nil
+}
+
+// cropUPS 裁剪未裁剪的 UPS 面单，保留显示时的上半部分，已经是 4x6 左右尺寸的页面不处理
+func cropUPS(box rect, rotate int) rect {
+	if box.width()*box.height() < Width4x6*Height4x6*1.5 {
+		return box
+	}
+	switch rotate {
+	case 90: // 显示时的上方为页面左侧
+		box.x1 = box.x0 + box.width()/2
+	case 180:
+		box.y1 = box.y0 + box.height()/2
+	case 270:
+		box.x0 = box.x1 - box.width()/2
+	default:
+		box.y0 = box.y1 - box.height()/2
+	}
+	return box
+}
+
+// matrix 变换矩阵 [a b c d e f]
+type matrix [6]float64
+
+// multiply 先应用 m 再应用 n
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2], m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2], m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4], m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// fit 返回将 box 按页面旋转角度显示后缩放并居中到 width x height 页面的变换矩阵，方向不一致时旋转 90 度
+func fit(box rect, rotate int, width, height float64) matrix {
+	w, h := box.width(), box.height()
+	if rotate == 90 || rotate == 270 {
+		w, h = h, w
+	}
+	if (w > h) != (width > height) && math.Abs(w-h) > 1 {
+		rotate = (rotate + 90) % 360
+		w, h = h, w
+	}
+
+	m := matrix{1, 0, 0, 1, -box.x0, -box.y0}
+	bw, bh := box.width(), box.height()
+	switch rotate {
+	case 90:
+		m = m.multiply(matrix{0, -1, 1, 0, 0, bw})
+	case 180:
+		m = m.multiply(matrix{-1, 0, 0, -1, bw, bh})
+	case 270:
+		m = m.multiply(matrix{0, 1, -1, 0, bh, 0})
+	}
+	scale := min(width/w, height/h)
+	m = m.multiply(matrix{scale, 0, 0, scale, (width - w*scale) / 2, (height - h*scale) / 2})
+	return m
+}
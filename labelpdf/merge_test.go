@@ -0,0 +1,202 @@
+package labelpdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/stretchr/testify/assert"
+)
+
+// compressedPDF 生成使用对象流和交叉引用流（PDF 1.5）保存的 Letter 尺寸面单
+func compressedPDF(text string) []byte {
+	compress := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		_, _ = zw.Write(b)
+		_ = zw.Close()
+		return buf.Bytes()
+	}
+
+	content := compress([]byte(fmt.Sprintf("BT /F1 24 Tf 72 700 Td (%s) Tj ET", text)))
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	var header, body bytes.Buffer
+	for i, obj := range objects {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	objStm := compress(append(header.Bytes(), body.Bytes()...))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+	fmt.Fprintf(&b, "5 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream\nendobj\n", len(content), content)
+	fmt.Fprintf(&b, "6 0 obj\n<< /Type /ObjStm /N %d /First %d /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream\nendobj\n", len(objects), header.Len(), len(objStm), objStm)
+	b.WriteString("7 0 obj\n<< /Type /XRef /Root 1 0 R /Size 8 /Length 0 >>\nstream\n\nendstream\nendobj\n")
+	b.WriteString("startxref\n0\n%%EOF\n")
+	return b.Bytes()
+}
+
+func landscapePDF() []byte {
+	b := mazontest.LabelPDF("LANDSCAPE")
+	return bytes.Replace(b, []byte("/MediaBox [0 0 288 432]"), []byte("/MediaBox [0 0 432 288]"), 1)
+}
+
+func TestMerge(t *testing.T) {
+	labels := []Label{
+		{TrackingNumber: "1", ReferenceNo: "C", Data: mazontest.LabelPDF("PAGE-1", "PAGE-2")},
+		{TrackingNumber: "2", ReferenceNo: "A", SMCode: "UPS GROUND", Data: compressedPDF("UPS-LABEL")},
+		{TrackingNumber: "3", ReferenceNo: "B", Data: landscapePDF()},
+	}
+	b, err := Merge(labels, Options{
+		CropUPS: true,
+		SortKey: func(label Label) string { return label.ReferenceNo },
+	})
+	assert.Nil(t, err)
+
+	doc, err := parseDocument(b)
+	assert.Nil(t, err)
+	pages, err := doc.pages()
+	assert.Nil(t, err)
+	assert.Len(t, pages, 4)
+	texts := make([]string, len(pages))
+	for i, pg := range pages {
+		assert.Equal(t, rect{0, 0, Width4x6, Height4x6}, pg.box)
+		assert.Len(t, pg.contents, 3)
+		s := doc.resolve(pg.contents[1]).(*stream)
+		data, err := doc.decode(s)
+		assert.Nil(t, err)
+		texts[i] = string(data)
+		font, ok := doc.resolve(doc.resolve(doc.resolve(pg.resources).(dict)["Font"]).(dict)["F1"]).(dict)
+		assert.True(t, ok)
+		assert.Equal(t, name("Helvetica"), font["BaseFont"])
+	}
+	// 按参考号排序，同一面单的页面保持顺序
+	assert.Contains(t, texts[0], "UPS-LABEL")
+	assert.Contains(t, texts[1], "LANDSCAPE")
+	assert.Contains(t, texts[2], "PAGE-1")
+	assert.Contains(t, texts[3], "PAGE-2")
+
+	// UPS 面单裁剪为上半部分（612x396）并旋转 90 度
+	prefix := string(doc.resolve(pages[0].contents[0]).(*stream).data)
+	assert.Contains(t, prefix, " re W n")
+	assert.Contains(t, prefix, "0 396 612 396 re")
+	assert.Contains(t, prefix, "q 0 -0.7059 0.7059 0")
+
+	// 不裁剪时整页缩放
+	b, err = Merge(labels[1:2], Options{})
+	assert.Nil(t, err)
+	doc, _ = parseDocument(b)
+	pages, _ = doc.pages()
+	assert.Contains(t, string(doc.resolve(pages[0].contents[0]).(*stream).data), "0 0 612 792 re")
+}
+
+func TestFromLabelFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := mazon.LabelFiles{
+		{Kind: mazon.LabelFileLabel, ReferenceNo: "A", TrackingNumber: "1", Format: mazon.LabelFormatPDF, Location: filepath.Join(dir, "1.pdf")},
+		{Kind: mazon.LabelFileLabel, ReferenceNo: "B", TrackingNumber: "2", Format: mazon.LabelFormatPDF, Location: filepath.Join(dir, "2.pdf")},
+		{Kind: mazon.LabelFileMergeLabel, ReferenceNo: "A", Format: mazon.LabelFormatPDF, Location: filepath.Join(dir, "merged.pdf")},
+	}
+	assert.Nil(t, os.WriteFile(files[0].Location, compressedPDF("UPS-LABEL"), 0644))
+	assert.Nil(t, os.WriteFile(files[1].Location, compressedPDF("USPS-LABEL"), 0644))
+
+	labels, err := FromLabelFiles(files.SetSMCode(map[string]string{"A": "UPS GROUND", "2": "USPS GA13"}))
+	assert.Nil(t, err)
+	assert.Len(t, labels, 2)
+	assert.Equal(t, "UPS GROUND", labels[0].SMCode)
+	assert.Equal(t, "USPS GA13", labels[1].SMCode)
+
+	b, err := Merge(labels, Options{CropUPS: true})
+	assert.Nil(t, err)
+	doc, err := parseDocument(b)
+	assert.Nil(t, err)
+	pages, err := doc.pages()
+	assert.Nil(t, err)
+	assert.Len(t, pages, 2)
+	// 只裁剪 UPS 面单
+	assert.Contains(t, string(doc.resolve(pages[0].contents[0]).(*stream).data), "0 396 612 396 re")
+	assert.Contains(t, string(doc.resolve(pages[1].contents[0]).(*stream).data), "0 0 612 792 re")
+}
+
+// incrementalPDF 生成增量更新的面单，update 为追加的更新内容（修改 2 号对象的页面尺寸）
+func incrementalPDF(update func(b *bytes.Buffer, pages string)) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+	b.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	b.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>\nendobj\n")
+	b.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R >>\nendobj\n")
+	b.WriteString("trailer\n<< /Root 1 0 R /Size 4 >>\nstartxref\n0\n%%EOF\n")
+	update(&b, "<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 288 432] >>")
+	return b.Bytes()
+}
+
+func TestParseDocument_IncrementalUpdate(t *testing.T) {
+	objStm := func(b *bytes.Buffer, num int, obj string) {
+		var data bytes.Buffer
+		zw := zlib.NewWriter(&data)
+		_, _ = fmt.Fprintf(zw, "2 0 %s", obj)
+		_ = zw.Close()
+		fmt.Fprintf(b, "%d 0 obj\n<< /Type /ObjStm /N 1 /First 4 /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream\nendobj\n", num, data.Len(), data.Bytes())
+	}
+	tests := []struct {
+		name   string
+		update func(b *bytes.Buffer, pages string)
+		want   rect
+	}{
+		{"direct object", func(b *bytes.Buffer, pages string) {
+			fmt.Fprintf(b, "2 1 obj\n%s\nendobj\n", pages)
+		}, rect{0, 0, 288, 432}},
+		// 增量更新中对象流里的对象覆盖之前的直接对象
+		{"object stream", func(b *bytes.Buffer, pages string) {
+			objStm(b, 4, pages)
+		}, rect{0, 0, 288, 432}},
+		// 对象流之后的直接对象优先
+		{"object stream before direct object", func(b *bytes.Buffer, pages string) {
+			objStm(b, 4, pages)
+			b.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 100 100] >>\nendobj\n")
+		}, rect{0, 0, 100, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDocument(incrementalPDF(tt.update))
+			assert.Nil(t, err)
+			pages, err := doc.pages()
+			assert.Nil(t, err)
+			if assert.Len(t, pages, 1) {
+				assert.Equal(t, tt.want, pages[0].box)
+			}
+		})
+	}
+}
+
+func TestMerge_Errors(t *testing.T) {
+	_, err := Merge(nil, Options{})
+	assert.True(t, errors.Is(err, ErrNoLabels))
+
+	_, err = Merge([]Label{{TrackingNumber: "1", Data: []byte("<html></html>")}}, Options{})
+	assert.True(t, errors.Is(err, ErrInvalidPDF))
+}
+
+func TestFit(t *testing.T) {
+	// 同尺寸页面不缩放
+	assert.Equal(t, matrix{1, 0, 0, 1, 0, 0}, fit(rect{0, 0, 288, 432}, 0, 288, 432))
+	// Letter 页面按比例缩小并居中
+	m := fit(rect{0, 0, 612, 792}, 0, 288, 432)
+	assert.InDelta(t, 288.0/612, m[0], 1e-9)
+	assert.InDelta(t, 0, m[4], 1e-9)
+	assert.InDelta(t, (432-792*288.0/612)/2, m[5], 1e-9)
+	// 横向页面旋转 90 度
+	m = fit(rect{0, 0, 432, 288}, 0, 288, 432)
+	assert.Equal(t, matrix{0, -1, 1, 0, 0, 432}, m)
+}
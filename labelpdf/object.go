@@ -0,0 +1,326 @@
+package labelpdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PDF 对象
+type (
+	name      string
+	pdfString []byte
+	array     []any
+	dict      map[name]any
+	ref       struct{ num, gen int }
+	stream    struct {
+		dict dict
+		data []byte // 原始（未解码）数据
+	}
+)
+
+var errSyntax = errors.New("PDF 语法错误")
+
+func isWhite(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func isRegular(c byte) bool {
+	return !isWhite(c) && !isDelimiter(c)
+}
+
+// parser PDF 对象解析器
+type parser struct {
+	data []byte
+	pos  int
+}
+
+// skip 跳过空白和注释
+func (p *parser) skip() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isWhite(c) {
+			p.pos++
+		} else if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return bytes.HasPrefix(p.data[p.pos:], []byte(s))
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s（位置 %d）", errSyntax, fmt.Sprintf(format, args...), p.pos)
+}
+
+// keyword 读取关键字（或数字）
+func (p *parser) keyword() string {
+	start := p.pos
+	for p.pos < len(p.data) && isRegular(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+func (p *parser) parse() (any, error) {
+	p.skip()
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("意外的文件结尾")
+	}
+
+	c := p.data[p.pos]
+	switch {
+	case c == '/':
+		return p.name(), nil
+	case c == '(':
+		return p.literalString()
+	case c == '<' && p.hasPrefix("<<"):
+		return p.dictOrStream()
+	case c == '<':
+		return p.hexString()
+	case c == '[':
+		p.pos++
+		arr := array{}
+		for {
+			p.skip()
+			if p.pos >= len(p.data) {
+				return nil, p.errorf("数组未结束")
+			}
+			if p.data[p.pos] == ']' {
+				p.pos++
+				return arr, nil
+			}
+			v, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	case c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9':
+		return p.number()
+	}
+
+	switch kw := p.keyword(); kw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return nil, p.errorf("无效的关键字 %q", kw)
+	}
+}
+
+func (p *parser) name() name {
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.data) && isRegular(p.data[p.pos]) {
+		p.pos++
+	}
+	raw := p.data[start:p.pos]
+	if bytes.IndexByte(raw, '#') < 0 {
+		return name(raw)
+	}
+	b := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if v, err := strconv.ParseUint(string(raw[i+1:i+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				i += 2
+				continue
+			}
+		}
+		b = append(b, raw[i])
+	}
+	return name(b)
+}
+
+func (p *parser) number() (any, error) {
+	s := p.keyword()
+	if bytes.ContainsAny([]byte(s), ".") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, p.errorf("无效的数字 %q", s)
+		}
+		return f, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, p.errorf("无效的数字 %q", s)
+		}
+		return f, nil
+	}
+
+	// 间接引用：num gen R
+	if n >= 0 {
+		pos := p.pos
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			if gen, err := strconv.Atoi(p.keyword()); err == nil {
+				p.skip()
+				if p.pos < len(p.data) && p.data[p.pos] == 'R' && (p.pos+1 == len(p.data) || !isRegular(p.data[p.pos+1])) {
+					p.pos++
+					return ref{num: int(n), gen: gen}, nil
+				}
+			}
+		}
+		p.pos = pos
+	}
+	return n, nil
+}
+
+func (p *parser) literalString() (any, error) {
+	p.pos++
+	var b []byte
+	depth := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return pdfString(b), nil
+			}
+		case '\\':
+			if p.pos >= len(p.data) {
+				return nil, p.errorf("字符串未结束")
+			}
+			c = p.data[p.pos]
+			p.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return nil, p.errorf("字符串未结束")
+}
+
+func (p *parser) hexString() (any, error) {
+	p.pos++
+	var digits []byte
+	for p.pos < len(p.data) && p.data[p.pos] != '>' {
+		if c := p.data[p.pos]; !isWhite(c) {
+			digits = append(digits, c)
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("十六进制字符串未结束")
+	}
+	p.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	b := make([]byte, len(digits)/2)
+	for i := range b {
+		v, err := strconv.ParseUint(string(digits[i*2:i*2+2]), 16, 8)
+		if err != nil {
+			return nil, p.errorf("无效的十六进制字符串")
+		}
+		b[i] = byte(v)
+	}
+	return pdfString(b), nil
+}
+
+func (p *parser) dictOrStream() (any, error) {
+	p.pos += 2
+	d := dict{}
+	for {
+		p.skip()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("字典未结束")
+		}
+		if p.hasPrefix(">>") {
+			p.pos += 2
+			break
+		}
+		if p.data[p.pos] != '/' {
+			return nil, p.errorf("字典的键必须为名称")
+		}
+		key := p.name()
+		v, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		d[key] = v
+	}
+
+	pos := p.pos
+	p.skip()
+	if !p.hasPrefix("stream") {
+		p.pos = pos
+		return d, nil
+	}
+	p.pos += len("stream")
+	if p.hasPrefix("\r\n") {
+		p.pos += 2
+	} else if p.hasPrefix("\n") || p.hasPrefix("\r") {
+		p.pos++
+	}
+	start := p.pos
+
+	// 优先使用 Length（直接对象），否则查找 endstream
+	if length, ok := d["Length"].(int64); ok && length >= 0 && start+int(length) <= len(p.data) {
+		p.pos = start + int(length)
+		p.skip()
+		if p.hasPrefix("endstream") {
+			p.pos += len("endstream")
+			return &stream{dict: d, data: p.data[start : start+int(length)]}, nil
+		}
+	}
+	i := bytes.Index(p.data[start:], []byte("endstream"))
+	if i < 0 {
+		return nil, p.errorf("流未结束")
+	}
+	end := start + i
+	if end > start && p.data[end-1] == '\n' {
+		end--
+	}
+	if end > start && p.data[end-1] == '\r' {
+		end--
+	}
+	p.pos = start + i + len("endstream")
+	return &stream{dict: d, data: p.data[start:end]}, nil
+}
@@ -0,0 +1,185 @@
+package labelpdf
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// writer PDF 写入器，对象编号从 1 开始
+type writer struct {
+	objects []any
+	copied  map[*document]map[int]ref
+}
+
+func newWriter() *writer {
+	return &writer{copied: make(map[*document]map[int]ref)}
+}
+
+// reserve 预留对象编号
+func (w *writer) reserve() ref {
+	w.objects = append(w.objects, nil)
+	return ref{num: len(w.objects)}
+}
+
+func (w *writer) set(r ref, v any) {
+	w.objects[r.num-1] = v
+}
+
+func (w *writer) add(v any) ref {
+	r := w.reserve()
+	w.set(r, v)
+	return r
+}
+
+// copy 将源文档中的对象（包括引用的所有对象）复制到新文档中
+func (w *writer) copy(d *document, v any) any {
+	switch v := v.(type) {
+	case ref:
+		copied, ok := w.copied[d]
+		if !ok {
+			copied = make(map[int]ref)
+			w.copied[d] = copied
+		}
+		if r, ok := copied[v.num]; ok {
+			return r
+		}
+		obj, ok := d.objects[v.num]
+		if !ok {
+			return nil
+		}
+		r := w.reserve()
+		copied[v.num] = r
+		w.set(r, w.copy(d, obj))
+		return r
+	case dict:
+		return w.copyDict(d, v)
+	case array:
+		arr := make(array, len(v))
+		for i, item := range v {
+			arr[i] = w.copy(d, item)
+		}
+		return arr
+	case *stream:
+		return &stream{dict: w.copyDict(d, v.dict), data: v.data}
+	}
+	return v
+}
+
+func (w *writer) copyDict(d *document, v dict) dict {
+	copied := make(dict, len(v))
+	for k, item := range v {
+		// 不复制页面树
+		if k == "Parent" || k == "P" && v["Type"] == name("Annot") {
+			continue
+		}
+		copied[k] = w.copy(d, item)
+	}
+	return copied
+}
+
+func (w *writer) bytes(root ref) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(w.objects))
+	for i, obj := range w.objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n", i+1)
+		writeObject(&b, obj)
+		b.WriteString("\nendobj\n")
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f\r\n", len(w.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.objects)+1, root.num, xref)
+	return b.Bytes()
+}
+
+func writeObject(b *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int:
+		b.WriteString(strconv.Itoa(v))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		b.WriteString(formatNumber(v))
+	case name:
+		b.WriteByte('/')
+		for i := 0; i < len(v); i++ {
+			c := v[i]
+			if c < '!' || c > '~' || c == '#' || isDelimiter(c) {
+				fmt.Fprintf(b, "#%02X", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	case pdfString:
+		fmt.Fprintf(b, "<%X>", []byte(v))
+	case ref:
+		fmt.Fprintf(b, "%d 0 R", v.num)
+	case array:
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			writeObject(b, item)
+		}
+		b.WriteByte(']')
+	case dict:
+		keys := make([]name, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		b.WriteString("<<")
+		for _, k := range keys {
+			writeObject(b, k)
+			b.WriteByte(' ')
+			writeObject(b, v[k])
+		}
+		b.WriteString(">>")
+	case *stream:
+		d := make(dict, len(v.dict))
+		for k, item := range v.dict {
+			d[k] = item
+		}
+		d["Length"] = int64(len(v.data))
+		writeObject(b, d)
+		b.WriteString("\nstream\n")
+		b.Write(v.data)
+		b.WriteString("\nendstream")
+	default:
+		panic(fmt.Sprintf("labelpdf: unsupported object %T", v))
+	}
+}
+
+// formatNumber 格式化数字，最多保留 4 位小数
+func formatNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 4, 64)
+	s = trimZeros(s)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func trimZeros(s string) string {
+	if !bytes.ContainsRune([]byte(s), '.') {
+		return s
+	}
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,308 @@
+package mazon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited 等待请求频率或者并发限制时 context 被取消（或者等待时间会超过 context 的截止时间），请求没有发送
+var ErrRateLimited = errors.New("请求因频率限制被取消")
+
+// RateLimit 请求频率和并发限制
+type RateLimit struct {
+	PerSecond   float64 // 每秒最多请求数（令牌桶的填充速度），小于等于 0 时不限制
+	Burst       int     // 令牌桶容量（允许的突发请求数），小于等于 0 时为 1
+	MaxInFlight int     // 最多同时进行的请求数，小于等于 0 时不限制
+}
+
+// LimitStats 等待统计
+type LimitStats struct {
+	Requests int64         // 请求数
+	Waited   int64         // 需要等待的请求数
+	Canceled int64         // 等待时被取消的请求数
+	WaitTime time.Duration // 总等待时间
+	MaxWait  time.Duration // 最长等待时间
+	InFlight int64         // 正在进行中的请求数
+}
+
+// AvgWait 平均等待时间
+func (s LimitStats) AvgWait() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.WaitTime / time.Duration(s.Requests)
+}
+
+// limitState 单个限制（全局或者接口）
+type limitState struct {
+	rate  *rate.Limiter // 为 nil 时不限制频率
+	slots chan struct{} // 为 nil 时不限制并发
+	mu    sync.Mutex
+	stats LimitStats
+}
+
+func newLimitState(l RateLimit) *limitState {
+	lim := &limitState{}
+	if l.PerSecond > 0 {
+		lim.rate = rate.NewLimiter(rate.Limit(l.PerSecond), max(l.Burst, 1))
+	}
+	if l.MaxInFlight > 0 {
+		lim.slots = make(chan struct{}, l.MaxInFlight)
+	}
+	return lim
+}
+
+// acquire 占用并发名额
+func (l *limitState) acquire(ctx context.Context) error {
+	if l.slots == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *limitState) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// wait 等待令牌
+func (l *limitState) wait(ctx context.Context) error {
+	if l.rate == nil {
+		return nil
+	}
+	if err := l.rate.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// 等待时间会超过截止时间
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// record 记录等待结果
+func (l *limitState) record(wait time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Requests++
+	if wait > time.Millisecond {
+		l.stats.Waited++
+	}
+	l.stats.WaitTime += wait
+	l.stats.MaxWait = max(l.stats.MaxWait, wait)
+	if err != nil {
+		l.stats.Canceled++
+	} else {
+		l.stats.InFlight++
+	}
+}
+
+func (l *limitState) done() {
+	l.mu.Lock()
+	l.stats.InFlight--
+	l.mu.Unlock()
+	l.release()
+}
+
+func (l *limitState) snapshot() LimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Limiter 请求限制器，包括所有接口共用的全局限制和单个接口的限制（比如更严格地限制 /createOrder）
+//
+// 使用同一个 Limiter 的客户端共用限制，按 AppKey 共用限制时使用 WithRateLimit（或者 SharedLimiter）：
+//
+//	limiter := NewLimiter(RateLimit{PerSecond: 10, Burst: 5, MaxInFlight: 4}).
+//		SetEndpoint("/createOrder", RateLimit{PerSecond: 2})
+//	client := NewClient(ctx, cfg, WithLimiter(limiter))
+type Limiter struct {
+	global    *limitState
+	mu        sync.RWMutex
+	endpoints map[string]*limitState
+	onWait    func(endpoint string, wait time.Duration)
+}
+
+// NewLimiter 创建限制器，limit 为使用该限制器的所有请求共用的全局限制
+func NewLimiter(limit RateLimit) *Limiter {
+	return &Limiter{global: newLimitState(limit), endpoints: make(map[string]*limitState)}
+}
+
+// sharedLimiters 按 AppKey 共用的限制器
+var (
+	sharedLimitersMu sync.Mutex
+	sharedLimiters   = make(map[string]*Limiter)
+)
+
+// SharedLimiter 返回 AppKey 共用的限制器，同一个进程中相同 AppKey 的客户端使用同一个 Limiter，
+// 只在第一次调用时使用 limit 创建，之后的调用忽略 limit，比如：
+//
+//	SharedLimiter(cfg.AppKey, RateLimit{PerSecond: 10}).SetEndpoint("/createOrder", RateLimit{PerSecond: 2})
+func SharedLimiter(appKey string, limit RateLimit) *Limiter {
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+	l, ok := sharedLimiters[appKey]
+	if !ok {
+		l = NewLimiter(limit)
+		sharedLimiters[appKey] = l
+	}
+	return l
+}
+
+// SetEndpoint 设置接口的限制（在全局限制的基础上），endpoint 为接口路径，比如 /createOrder，也会匹配下级路径（比如 /labels 匹配 /labels/xxx.pdf）
+func (l *Limiter) SetEndpoint(endpoint string, limit RateLimit) *Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.endpoints["/"+strings.Trim(endpoint, "/")] = newLimitState(limit)
+	return l
+}
+
+// OnWait 设置每个请求获得发送许可（或者被取消）后的回调，可以用于导出等待时间指标
+func (l *Limiter) OnWait(fn func(endpoint string, wait time.Duration)) *Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onWait = fn
+	return l
+}
+
+// Stats 返回使用该限制器的所有请求的等待统计
+func (l *Limiter) Stats() LimitStats {
+	return l.global.snapshot()
+}
+
+// EndpointStats 返回设置了限制的接口的等待统计
+func (l *Limiter) EndpointStats(endpoint string) (LimitStats, bool) {
+	l.mu.RLock()
+	lim, ok := l.endpoints["/"+strings.Trim(endpoint, "/")]
+	l.mu.RUnlock()
+	if !ok {
+		return LimitStats{}, false
+	}
+	return lim.snapshot(), true
+}
+
+// endpoint 返回匹配接口路径最长的接口限制
+func (l *Limiter) endpoint(p string) *limitState {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var (
+		matched string
+		lim     *limitState
+	)
+	for endpoint, v := range l.endpoints {
+		if (p == endpoint || strings.HasPrefix(p, endpoint+"/")) && len(endpoint) > len(matched) {
+			matched, lim = endpoint, v
+		}
+	}
+	return lim
+}
+
+// Wait 等待直到可以发送请求，请求结束后需要调用返回的 release
+//
+// 先通过接口限制（占用并发名额、等待令牌）再通过全局限制，避免等待接口限制时占用全局的名额，
+// ctx 取消或者等待时间会超过 ctx 的截止时间时返回 ErrRateLimited。
+func (l *Limiter) Wait(ctx context.Context, endpoint string) (release func(), err error) {
+	limits := []*limitState{l.global}
+	if lim := l.endpoint("/" + strings.Trim(endpoint, "/")); lim != nil {
+		limits = []*limitState{lim, l.global}
+	}
+
+	start := time.Now()
+	acquired := 0
+	for _, lim := range limits {
+		if err = lim.acquire(ctx); err != nil {
+			break
+		}
+		acquired++
+		if err = lim.wait(ctx); err != nil {
+			break
+		}
+	}
+	wait := time.Since(start)
+	for _, lim := range limits {
+		lim.record(wait, err)
+	}
+	l.mu.RLock()
+	onWait := l.onWait
+	l.mu.RUnlock()
+	if onWait != nil {
+		onWait(endpoint, wait)
+	}
+
+	if err != nil {
+		for _, lim := range limits[:acquired] {
+			lim.release()
+		}
+		return nil, fmt.Errorf("%w: %w", localize(localeOf(ctx, nil), ErrRateLimited), err)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for _, lim := range limits {
+				lim.done()
+			}
+		})
+	}, nil
+}
+
+// limitTransport 在发送请求之前等待限制器，只限制发送到接口地址的请求
+type limitTransport struct {
+	next     http.RoundTripper
+	limiter  *Limiter
+	host     string // 接口地址的主机
+	basePath string // 接口地址的路径，比如 /api/svc
+}
+
+func newLimitTransport(next http.RoundTripper, limiter *Limiter, baseURL string) *limitTransport {
+	t := &limitTransport{next: next, limiter: limiter}
+	if u, err := url.Parse(baseURL); err == nil {
+		t.host = u.Host
+		t.basePath = strings.TrimRight(u.Path, "/")
+	}
+	return t
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.next.RoundTrip(req)
+	}
+	release, err := t.limiter.Wait(req.Context(), strings.TrimPrefix(req.URL.Path, t.basePath))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	// 读取完响应内容后才结束请求
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
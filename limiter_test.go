@@ -0,0 +1,156 @@
+package mazon
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/mazon-go/config"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Wait(t *testing.T) {
+	// 频率限制
+	l := NewLimiter(RateLimit{PerSecond: 20, Burst: 1})
+	start := time.Now()
+	for range 3 {
+		release, err := l.Wait(ctx, "/rates")
+		assert.Nil(t, err)
+		release()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	stats := l.Stats()
+	assert.Equal(t, int64(3), stats.Requests)
+	assert.Equal(t, int64(2), stats.Waited)
+	assert.Equal(t, int64(0), stats.InFlight)
+	assert.Greater(t, stats.MaxWait, 30*time.Millisecond)
+	assert.Equal(t, stats.WaitTime/3, stats.AvgWait())
+
+	// 等待时间会超过截止时间
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err := l.Wait(timeoutCtx, "/rates")
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(1), l.Stats().Canceled)
+
+	// 并发限制
+	l = NewLimiter(RateLimit{MaxInFlight: 1})
+	release, err := l.Wait(ctx, "/createOrder")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), l.Stats().InFlight)
+	canceledCtx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = l.Wait(canceledCtx, "/createOrder")
+	assert.ErrorIs(t, err, context.Canceled)
+	release()
+	release() // 重复调用没有影响
+	release, err = l.Wait(ctx, "/createOrder")
+	assert.Nil(t, err)
+	release()
+	assert.Equal(t, int64(0), l.Stats().InFlight)
+}
+
+func TestLimiter_SetEndpoint(t *testing.T) {
+	var waits atomic.Int64
+	l := NewLimiter(RateLimit{}).
+		SetEndpoint("labels", RateLimit{MaxInFlight: 1}).
+		SetEndpoint("/labels/zpl", RateLimit{}).
+		OnWait(func(endpoint string, wait time.Duration) { waits.Add(1) })
+
+	release, err := l.Wait(ctx, "/labels/a.pdf")
+	assert.Nil(t, err)
+	stats, ok := l.EndpointStats("/labels")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), stats.InFlight)
+
+	// 匹配最长的接口路径
+	zplRelease, err := l.Wait(ctx, "/labels/zpl/a.zpl")
+	assert.Nil(t, err)
+	zplRelease()
+	release()
+	stats, _ = l.EndpointStats("/labels")
+	assert.Equal(t, int64(1), stats.Requests)
+	assert.Equal(t, int64(0), stats.InFlight)
+	assert.Equal(t, int64(2), l.Stats().Requests)
+	assert.Equal(t, int64(2), waits.Load())
+
+	_, ok = l.EndpointStats("/rates")
+	assert.False(t, ok)
+}
+
+func TestLimiter_WaitOrder(t *testing.T) {
+	l := NewLimiter(RateLimit{MaxInFlight: 1}).
+		SetEndpoint("/createOrder", RateLimit{PerSecond: 5, Burst: 1})
+	release, err := l.Wait(ctx, "/createOrder")
+	assert.Nil(t, err)
+	release()
+
+	// 等待接口限制时不占用全局的并发名额
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		release, err := l.Wait(ctx, "/createOrder")
+		assert.Nil(t, err)
+		release()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	release, err = l.Wait(ctx, "/rates")
+	assert.Nil(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	release()
+	<-done
+	assert.Equal(t, int64(3), l.Stats().Requests)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	cfg := config.Config{AppKey: "shared-limit-key"}
+	c1 := NewClient(ctx, cfg, WithTokenStore(NewMemoryTokenStore()), WithRateLimit(RateLimit{PerSecond: 10}))
+	c2 := NewClient(ctx, cfg, WithTokenStore(NewMemoryTokenStore()), WithRateLimit(RateLimit{PerSecond: 1}))
+	assert.Same(t, c1.limiter, c2.limiter)
+	assert.Same(t, c1.limiter, SharedLimiter("shared-limit-key", RateLimit{}))
+
+	cfg.AppKey = "other-limit-key"
+	c3 := NewClient(ctx, cfg, WithTokenStore(NewMemoryTokenStore()), WithRateLimit(RateLimit{PerSecond: 10}))
+	assert.NotSame(t, c1.limiter, c3.limiter)
+}
+
+func TestWithLimiter(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	limiter := NewLimiter(RateLimit{PerSecond: 1000, Burst: 10, MaxInFlight: 2}).
+		SetEndpoint("/rates", RateLimit{PerSecond: 10, Burst: 1}).
+		SetEndpoint("/getToken", RateLimit{MaxInFlight: 1})
+	c := NewClient(ctx, s.Config(), WithTokenStore(NewMemoryTokenStore()), WithLimiter(limiter))
+
+	req := cacheRateRequest()
+	start := time.Now()
+	for range 3 {
+		_, err := c.Services.Rate.Calc(ctx, req)
+		assert.Nil(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 180*time.Millisecond)
+	stats, _ := limiter.EndpointStats("/rates")
+	assert.Equal(t, int64(3), stats.Requests)
+	assert.Equal(t, int64(2), stats.Waited)
+	assert.Equal(t, int64(0), limiter.Stats().InFlight)
+
+	// 获取 Token 也计入限制
+	stats, _ = limiter.EndpointStats("/getToken")
+	assert.Equal(t, int64(1), stats.Requests)
+	assert.Equal(t, int64(4), limiter.Stats().Requests)
+
+	// 等待时取消的请求不会发送，也不会重试
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err := c.Services.Rate.Calc(timeoutCtx, req)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.NotErrorIs(t, err, ErrTimeout)
+	assert.False(t, isAmbiguousError(err))
+	assert.Equal(t, 3, s.Requests("/rates"))
+	stats, _ = limiter.EndpointStats("/rates")
+	assert.Equal(t, int64(1), stats.Canceled)
+}
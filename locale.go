@@ -0,0 +1,195 @@
+package mazon
+
+import (
+	"context"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/mazon-go/config"
+)
+
+// Locale 错误信息语言
+type Locale string
+
+const (
+	LocaleZhCN Locale = "zh-CN" // 简体中文（默认）
+	LocaleEnUS Locale = "en-US" // 英文
+)
+
+// ParseLocale 解析语言标识，支持 zh、zh-CN、zh_CN、en、en-US、en_US 等格式，无法识别时返回 LocaleZhCN
+func ParseLocale(s string) Locale {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "en" || strings.HasPrefix(s, "en-") || strings.HasPrefix(s, "en_") {
+		return LocaleEnUS
+	}
+	return LocaleZhCN
+}
+
+type localeKey struct{}
+
+// WithLocale 设置本次请求使用的错误信息语言，优先级高于 config.Config 中的 Locale 设置
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// localeOf 返回当前请求使用的语言
+func localeOf(ctx context.Context, cfg *config.Config) Locale {
+	if ctx != nil {
+		if locale, ok := ctx.Value(localeKey{}).(Locale); ok {
+			return locale
+		}
+	}
+	if cfg != nil {
+		return ParseLocale(cfg.Locale)
+	}
+	return LocaleZhCN
+}
+
+// messages 消息翻译，以简体中文消息（模板）为键
+var messages = map[Locale]map[string]string{
+	LocaleEnUS: {
+		// 接口错误
+		"无效的 Token":    "Invalid token",
+		"内部错误，请联系美正客服": "Internal error, please contact Mazon customer service",
+		"未知错误":         "Unknown error",
+		"请求超时":         "Request timeout",
+		"无效的跟踪号":       "Invalid tracking number",
+		"预报失败":         "Forecast failed",
+		"请求因频率限制被取消":   "Request canceled by rate limit",
+		"没有可用的物流产品报价":  "No rate quote available",
+		"币种不一致":        "Currency mismatch",
+
+		// 幂等创建订单
+		"无法确定订单是否已经创建":       "Unable to determine whether the order was created",
+		"%s（参考号：%s）：%s":      "%s (reference number: %s): %s",
+		"%w（根据参考号查询订单失败：%w）": "%w (failed to look up order by reference number: %w)",
+
+		// 面单下载
+		"面单文件为空":                      "Label file is empty",
+		"面单文件格式与面单类型不一致":              "Label file format does not match the label type",
+		"无效的面单文件名称":                   "Invalid label file name",
+		"面单文件大小与响应头不一致":               "Label file size does not match the response header",
+		"无效的面单文件名称: 合并面单缺少订单号和参考号":    "Invalid label file name: merged label has no order code or reference number",
+		"无效的面单文件名称: 面单缺少物流单号、订单号和参考号": "Invalid label file name: label has no tracking number, order code or reference number",
+
+		// 请求构建器
+		"包裹 %d: %w": "box %d: %w",
+		"未知的长度单位":   "Unknown length unit",
+		"未知的重量单位":   "Unknown weight unit",
+
+		// 包裹
+		"长不能为空":                  "Length is required",
+		"长不能小于 {{.min}}":         "Length must be no less than {{.min}}",
+		"长不能大于 {{.max}}":         "Length must be no greater than {{.max}}",
+		"宽不能为空":                  "Width is required",
+		"宽不能小于 {{.min}}":         "Width must be no less than {{.min}}",
+		"宽不能大于 {{.max}}":         "Width must be no greater than {{.max}}",
+		"高不能为空":                  "Height is required",
+		"高不能小于 {{.min}}":         "Height must be no less than {{.min}}",
+		"高不能大于 {{.max}}":         "Height must be no greater than {{.max}}",
+		"重量不能为空":                 "Weight is required",
+		"重量不能小于 {{.min}}":        "Weight must be no less than {{.min}}",
+		"重量不能大于 {{.max}}":        "Weight must be no greater than {{.max}}",
+		"SKU 不能超过 {{.max}} 个字符":  "SKU must not exceed {{.max}} characters",
+		"中文名称不能超过 {{.max}} 个字符":  "Chinese name must not exceed {{.max}} characters",
+		"英文名称不能超过 {{.max}} 个字符":  "English name must not exceed {{.max}} characters",
+		"申报单位不能超过 {{.max}} 个字符":  "Declaring company must not exceed {{.max}} characters",
+		"申报数量不能小于 {{.min}}":      "Declared quantity must be no less than {{.min}}",
+		"申报价格不能小于 {{.min}}":      "Declared price must be no less than {{.min}}",
+		"申报重量不能小于 {{.min}}":      "Declared weight must be no less than {{.min}}",
+		"配货信息不能超过 {{.max}} 个字符":  "Picking information must not exceed {{.max}} characters",
+		"海关编码不能超过 {{.max}} 个字符":  "Customs code must not exceed {{.max}} characters",
+		"销售链接不能超过 {{.max}} 个字符":  "Sales URL must not exceed {{.max}} characters",
+		"中文材质不能超过 {{.max}} 个字符":  "Chinese material must not exceed {{.max}} characters",
+		"英文材质不能超过 {{.max}} 个字符":  "English material must not exceed {{.max}} characters",
+		"生产国家不能超过 {{.max}} 个字符":  "Country of origin must not exceed {{.max}} characters",
+		"备注不能超过 {{.max}} 个字符":    "Remark must not exceed {{.max}} characters",
+		"包裹信息不能为空":               "Box list is required",
+		"无效的包裹单位类型参数 {{.value}}": "Invalid weight unit type {{.value}}",
+		"包裹单位类型参数错误":             "Invalid weight unit type",
+
+		// 订单、收件人
+		"订单参考号不能为空":                          "Reference number is required",
+		"订单参考号不能超过 {{.max}} 个字符":             "Reference number must not exceed {{.max}} characters",
+		"物流产品代码不能为空":                         "Shipping product code is required",
+		"收件人不能为空":                            "Recipient name is required",
+		"收件人长度必须在 {{.min}} ~ {{.max}} 个字符":   "Recipient name must be between {{.min}} and {{.max}} characters",
+		"收件人公司不能超过 {{.max}} 个字符":             "Recipient company must not exceed {{.max}} characters",
+		"收件人地址1不能为空":                         "Recipient address line 1 is required",
+		"收件人地址1长度不能超过 {{.max}} 个字符":          "Recipient address line 1 must not exceed {{.max}} characters",
+		"收件人地址2长度不能超过 {{.max}} 个字符":          "Recipient address line 2 must not exceed {{.max}} characters",
+		"收件人邮编不能为空":                          "Recipient postal code is required",
+		"收件人州不能为空":                           "Recipient state is required",
+		"收件人城市不能为空":                          "Recipient city is required",
+		"收件人国家（国家二字码）不能为空":                   "Recipient country (two-letter code) is required",
+		"收件人电话不能为空":                          "Recipient phone is required",
+		"收件人电话长度必须在 {{.min}} ~ {{.max}} 个字符": "Recipient phone must be between {{.min}} and {{.max}} characters",
+		"无效的签名服务 {{.value}}":                 "Invalid signature service {{.value}}",
+		"签名服务参数错误":                           "Invalid signature service",
+		"发件人信息和编码必须填写一个":                     "Either shipper address or shipper code is required",
+		"类型参数错误":                             "Invalid query type",
+		"开始时间格式错误":                           "Invalid start time format",
+		"结束时间格式错误":                           "Invalid end time format",
+		"订单号不能为空":                            "Order code is required",
+		"参考号不能为空":                            "Reference number is required",
+
+		// 发件人
+		"发件人姓名不能为空":                          "Shipper name is required",
+		"发件人姓名长度必须在 {{.min}} ~ {{.max}} 个字符": "Shipper name must be between {{.min}} and {{.max}} characters",
+		"发件人公司不能超过 {{.max}} 个字符":             "Shipper company must not exceed {{.max}} characters",
+		"发件人电话不能为空":                          "Shipper phone is required",
+		"发件人电话长度必须在 {{.min}} ~ {{.max}} 个字符": "Shipper phone must be between {{.min}} and {{.max}} characters",
+		"发件人国家不能为空":                          "Shipper country is required",
+		"发件人州不能为空":                           "Shipper state is required",
+		"发件人城市不能为空":                          "Shipper city is required",
+		"发件人城市不能超过 {{.max}} 个字符":             "Shipper city must not exceed {{.max}} characters",
+		"发件人邮编不能为空":                          "Shipper postal code is required",
+		"发件人邮编长度必须在 {{.min}} ~ {{.max}} 个字符": "Shipper postal code must be between {{.min}} and {{.max}} characters",
+		"发件人地址1不能为空":                         "Shipper address line 1 is required",
+		"发件人地址1长度不能超过 {{.max}} 个字符":          "Shipper address line 1 must not exceed {{.max}} characters",
+		"发件人地址2长度不能超过 {{.max}} 个字符":          "Shipper address line 2 must not exceed {{.max}} characters",
+
+		// 美国地址规范化（usaddress）
+		"无效的邮编 {{.value}}":       "Invalid ZIP code {{.value}}",
+		"无效的州 {{.value}}":        "Invalid state {{.value}}",
+		"地址超过 {{.max}} 个字符，无法拆分": "Address exceeds {{.max}} characters and cannot be split",
+	},
+}
+
+// translate 翻译消息（模板），没有对应的翻译时返回原消息
+func translate(locale Locale, message string) string {
+	if m, ok := messages[locale]; ok {
+		if s, ok := m[message]; ok {
+			return s
+		}
+	}
+	return message
+}
+
+// translateErrorObject 翻译验证错误
+func translateErrorObject(locale Locale, e validation.ErrorObject) validation.ErrorObject {
+	return e.SetMessage(translate(locale, e.Message())).(validation.ErrorObject)
+}
+
+// localizedError 翻译后的错误，可以使用 errors.Is 判断原错误
+type localizedError struct {
+	err     error
+	message string
+}
+
+func (e *localizedError) Error() string {
+	return e.message
+}
+
+func (e *localizedError) Unwrap() error {
+	return e.err
+}
+
+// localize 翻译错误信息（主要用于 ErrInvalidTrackingNumber 等预定义的错误）
+func localize(locale Locale, err error) error {
+	message := translate(locale, err.Error())
+	if message == err.Error() {
+		return err
+	}
+	return &localizedError{err: err, message: message}
+}
@@ -0,0 +1,107 @@
+package mazon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocale(t *testing.T) {
+	assert.Equal(t, LocaleZhCN, ParseLocale(""))
+	assert.Equal(t, LocaleZhCN, ParseLocale("zh_CN"))
+	assert.Equal(t, LocaleEnUS, ParseLocale("en"))
+	assert.Equal(t, LocaleEnUS, ParseLocale("EN-us"))
+	assert.Equal(t, LocaleEnUS, ParseLocale("en_GB"))
+}
+
+// 所有的验证错误信息都需要有对应的翻译
+func TestMessages(t *testing.T) {
+	files, _ := filepath.Glob("*.go")
+	entityFiles, _ := filepath.Glob("entity/*.go")
+	re := regexp.MustCompile(`(?:\.Error|NewError\("\d+",) ?\("([^"]+)"`)
+	for _, file := range append(files, entityFiles...) {
+		b, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		for _, match := range re.FindAllStringSubmatch(string(b), -1) {
+			_, ok := messages[LocaleEnUS][match[1]]
+			assert.True(t, ok, "%s: %s", file, match[1])
+		}
+	}
+}
+
+// 返回给调用方的预定义错误都需要有对应的翻译
+func TestMessages_Errors(t *testing.T) {
+	for _, err := range []error{
+		ErrInvalidTrackingNumber, ErrForecastFailed, ErrRateLimited, ErrNoRateQuote, ErrOrderOutcomeUnknown,
+		ErrEmptyLabelFile, ErrLabelFormatMismatch, ErrInvalidLabelFileName, ErrLabelFileSizeMismatch,
+		entity.ErrUnknownLengthUnit, entity.ErrUnknownWeightUnit, entity.ErrCurrencyMismatch,
+	} {
+		_, ok := messages[LocaleEnUS][err.Error()]
+		assert.True(t, ok, err.Error())
+	}
+}
+
+func TestLocale(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	cfg := s.Config()
+	cfg.Locale = "en-US"
+	c := NewClient(ctx, cfg, WithTokenStore(NewMemoryTokenStore()))
+
+	_, err := c.Services.Order.Cancel(ctx, CancelOrderRequest{})
+	assert.Equal(t, "Order code is required; Reference number is required", err.Error())
+
+	// context 中的语言设置优先
+	_, err = c.Services.Order.Cancel(WithLocale(ctx, LocaleZhCN), CancelOrderRequest{})
+	assert.Equal(t, "订单号不能为空; 参考号不能为空", err.Error())
+
+	_, err = c.Services.ShippingLabel.Query(ctx, " ")
+	assert.True(t, errors.Is(err, ErrInvalidTrackingNumber))
+	assert.Equal(t, "Invalid tracking number", err.Error())
+
+	s.Inject(mazontest.Fault{Path: "/getUserInfo", Code: InternalError, Times: 1})
+	_, err = c.Services.User.Information(ctx)
+	assert.True(t, errors.Is(err, ErrInternal))
+	assert.Equal(t, "500: Internal error, please contact Mazon customer service", err.Error())
+
+	s.Inject(mazontest.Fault{Path: "/getUserInfo", Code: InternalError, Times: 1})
+	_, err = c.Services.User.Information(WithLocale(ctx, LocaleZhCN))
+	assert.Equal(t, "500: 内部错误，请联系美正客服", err.Error())
+
+	// 预定义的错误
+	_, err = c.Services.Rate.Shop(ctx, RateShopRequest{RateCalcRequest: cacheRateRequest(), SMCodes: []string{"DHL"}})
+	assert.ErrorIs(t, err, ErrNoRateQuote)
+	assert.True(t, strings.HasPrefix(err.Error(), "No rate quote available: DHL: "), err.Error())
+
+	s.Inject(mazontest.Fault{Path: "/createOrder", Code: InternalError, Times: 1})
+	_, err = c.Services.Order.CreateIdempotent(ctx, NewCreateOrderRequest(cacheRateRequest()), IdempotentOptions{LookupAttempts: 1})
+	assert.ErrorIs(t, err, ErrOrderOutcomeUnknown)
+	assert.True(t, strings.HasPrefix(err.Error(), "Unable to determine whether the order was created (reference number: TEST-RATE-CACHE): "), err.Error())
+
+	_, err = NewShipmentBuilder("LOCALE").Locale(LocaleEnUS).AddBox(ShipmentBox{}).CreateOrderRequest()
+	assert.ErrorIs(t, err, entity.ErrUnknownLengthUnit)
+	assert.Equal(t, `box 1: Unknown length unit ""`, err.Error())
+
+	_, err = DefaultLabelFileName(LabelFile{Kind: LabelFileMergeLabel})
+	err = localize(LocaleEnUS, err)
+	assert.ErrorIs(t, err, ErrInvalidLabelFileName)
+	assert.Equal(t, "Invalid label file name: merged label has no order code or reference number", err.Error())
+
+	limiter := NewLimiter(RateLimit{MaxInFlight: 1})
+	release, err := limiter.Wait(ctx, "/rates")
+	assert.Nil(t, err)
+	defer release()
+	canceledCtx, cancel := context.WithCancel(WithLocale(ctx, LocaleEnUS))
+	cancel()
+	_, err = limiter.Wait(canceledCtx, "/rates")
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, "Request canceled by rate limit: context canceled", err.Error())
+}
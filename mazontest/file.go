@@ -0,0 +1,133 @@
+package mazontest
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+)
+
+// SetFile 设置面单文件内容，用于模拟文件损坏、格式错误等情况，path 为 /labels/xxx.pdf、/scanforms/1.pdf 等路径
+func (s *Server) SetFile(path string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[path] = data
+}
+
+// serveFile 返回面单（/labels/{物流单号}.{pdf|zpl|png}）、合并面单（/labels/{订单号}.pdf）和 ScanForm（/scanforms/{n}.pdf）文件
+func (s *Server) serveFile(w http.ResponseWriter, filePath string) {
+	s.mu.Lock()
+	data, ok := s.files[filePath]
+	var pages []string
+	ext := path.Ext(filePath)
+	name := strings.TrimSuffix(path.Base(filePath), ext)
+	if !ok {
+		if strings.HasPrefix(filePath, "/scanforms/") {
+			pages = []string{"SCAN FORM " + name}
+		} else if o, exists := s.orders[name]; exists {
+			for _, label := range o.Labels {
+				pages = append(pages, label.TrackingNumber)
+			}
+		} else {
+		loop:
+			for _, o := range s.orders {
+				for _, label := range slices.Concat(o.Labels, o.pending) {
+					if label.TrackingNumber == name {
+						pages = []string{name}
+						break loop
+					}
+				}
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		if len(pages) == 0 {
+			http.NotFound(w, nil)
+			return
+		}
+		switch ext {
+		case ".pdf":
+			data = LabelPDF(pages...)
+		case ".zpl":
+			data = LabelZPL(pages[0])
+		case ".png":
+			data = labelPNG()
+		default:
+			http.NotFound(w, nil)
+			return
+		}
+	}
+
+	switch ext {
+	case ".pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+	case ".png":
+		w.Header().Set("Content-Type", "image/png")
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	_, _ = w.Write(data)
+}
+
+// LabelPDF 生成 4x6 英寸的 PDF 面单，每个文本一页
+func LabelPDF(texts ...string) []byte {
+	if len(texts) == 0 {
+		texts = []string{""}
+	}
+	var objects []string
+	// 1: Catalog, 2: Pages, 3: Font, 之后每页两个对象（Page、Content）
+	kids := make([]string, len(texts))
+	for i := range texts {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(texts)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+	for i, text := range texts {
+		text = strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(text)
+		content := fmt.Sprintf("BT /F1 18 Tf 24 380 Td (%s) Tj ET\n24 24 240 320 re S", text)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 288 432] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// LabelZPL 生成 ZPL 面单
+func LabelZPL(trackingNumber string) []byte {
+	return []byte(fmt.Sprintf("^XA\n^FO50,50^A0N,40,40^FDMAZON TEST^FS\n^FO50,120^BY3^BCN,120,Y,N,N^FD%s^FS\n^FO30,30^GB752,1158,3^FS\n^XZ\n", trackingNumber))
+}
+
+func labelPNG() []byte {
+	img := image.NewGray(image.Rect(0, 0, 4, 6))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	var b bytes.Buffer
+	_ = png.Encode(&b, img)
+	return b.Bytes()
+}
@@ -0,0 +1,484 @@
+package mazontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+// Order 模拟服务中的订单
+type Order struct {
+	entity.Order
+	SMCode       string             // 物流产品代码
+	LabelStatus  int                // 0:预报失败 1：预报中 2：预报成功
+	Labels       []entity.Label     // 面单
+	MergeLabel   string             // 合并面单
+	Fee          []entity.Fee       // 费用信息
+	FeeDetail    []entity.FeeDetail // 费用详情
+	LogisticsErr string             // 预报失败的错误信息
+	polls        int                // 异步预报剩余的轮询次数
+	pending      []entity.Label     // 异步预报完成后返回的面单
+}
+
+type orderBox struct {
+	Length       float64 `json:"box_length"`
+	Width        float64 `json:"box_width"`
+	Height       float64 `json:"box_height"`
+	ActualWeight float64 `json:"box_actual_weight"`
+}
+
+type orderRequest struct {
+	ReferenceNo      string     `json:"reference_no"`
+	SMCode           string     `json:"sm_code"`
+	Remark           string     `json:"remark"`
+	OAFirstname      string     `json:"oa_firstname"`
+	OACompany        string     `json:"oa_company"`
+	OATelephone      string     `json:"oa_telphone"`
+	OACountry        string     `json:"oa_country"`
+	OAState          string     `json:"oa_state"`
+	OACity           string     `json:"oa_city"`
+	OAPostcode       string     `json:"oa_postcode"`
+	OAStreetAddress1 string     `json:"oa_street_address1"`
+	SignatureService string     `json:"signature_service"`
+	LabelImageFormat string     `json:"label_image_format"`
+	BoxList          []orderBox `json:"box_list"`
+}
+
+// AddOrder 添加订单，订单号为空时自动生成，面单为空时按照 trackingNumbers 生成
+func (s *Server) AddOrder(o Order, trackingNumbers ...string) Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o.OrderCode == "" {
+		o.OrderCode = s.nextOrderCode()
+	}
+	if o.AddTime == "" {
+		o.AddTime = time.Now().Format(time.DateTime)
+	}
+	if o.OrderStatus == entity.OrderStatusUnknown {
+		o.OrderStatus = entity.OrderForecasted
+	}
+	if o.LabelStatus == 0 {
+		o.LabelStatus = 2
+	}
+	if len(o.Labels) == 0 {
+		for _, number := range trackingNumbers {
+			o.Labels = append(o.Labels, s.label(number, "PDF"))
+		}
+	}
+	if o.MergeLabel == "" && len(o.Labels) != 0 {
+		o.MergeLabel = fmt.Sprintf("%s/labels/%s.pdf", s.URL, o.OrderCode)
+	}
+	s.orders[o.OrderCode] = &o
+	return o
+}
+
+// Order 根据订单号返回订单
+func (s *Server) Order(orderCode string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[orderCode]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+func (s *Server) nextOrderCode() string {
+	s.sequence++
+	return fmt.Sprintf("%s%s%06d", s.UserInfo.Code, time.Now().Format("20060102150405"), s.sequence)
+}
+
+func (s *Server) nextTrackingNumber() string {
+	s.sequence++
+	return fmt.Sprintf("92346903977033%08d", s.sequence)
+}
+
+func (s *Server) label(trackingNumber, fileType string) entity.Label {
+	if fileType == "" {
+		fileType = "PDF"
+	}
+	return entity.Label{
+		TrackingNumber: trackingNumber,
+		LabelUrl:       fmt.Sprintf("%s/labels/%s.%s", s.URL, trackingNumber, strings.ToLower(fileType)),
+		FileType:       fileType,
+	}
+}
+
+// findOrder 根据订单号或者参考号查找订单
+func (s *Server) findOrder(orderCode, referenceNo string) *Order {
+	if orderCode != "" {
+		return s.orders[orderCode]
+	}
+	if referenceNo != "" {
+		for _, o := range s.orders {
+			if o.ReferenceNo == referenceNo {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// quote 计算运费
+func (s *Server) quote(req orderRequest) (entity.RateCalcResult, bool) {
+	product, ok := s.Products[req.SMCode]
+	if !ok {
+		return entity.RateCalcResult{}, false
+	}
+
+	weight := 0.0
+	for _, box := range req.BoxList {
+		weight += box.ActualWeight
+	}
+	shipping := entity.DecimalFromFloat(product.Base + product.PerWeight*weight).Round(2)
+	currency := product.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	result := entity.RateCalcResult{
+		SmCode:          req.SMCode,
+		AddressTypeText: "Residential",
+		AddressType:     2,
+		CurrencyCode:    currency,
+		ShippingCharge:  shipping,
+		TotalCharge:     shipping,
+		ChargeDetail: []entity.ChargeDetail{
+			{FtCode: "Freight", FeeTypeCode: "E1", ChargeDesc: "基础运费", Amount: shipping},
+		},
+	}
+	if req.SignatureService != "" {
+		surcharge := entity.DecimalFromFloat(product.Surcharge).Round(2)
+		result.TotalCharge = result.TotalCharge.Add(surcharge)
+		result.ChargeDetail = append(result.ChargeDetail, entity.ChargeDetail{
+			FtCode: "Signature", FeeTypeCode: "E2", ChargeDesc: "签名服务费", Amount: surcharge,
+		})
+	}
+	return result, true
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, "参数错误："+err.Error(), nil)
+		return false
+	}
+	return true
+}
+
+func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
+	var req orderRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.ReferenceNo == "" || len(req.BoxList) == 0 {
+		writeJSON(w, http.StatusBadRequest, "参数错误", nil)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.findOrder("", req.ReferenceNo) != nil {
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf("参考号 %s 已存在", req.ReferenceNo), nil)
+		return
+	}
+	quote, ok := s.quote(req)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf("物流产品 %s 不存在", req.SMCode), nil)
+		return
+	}
+
+	o := &Order{
+		Order: entity.Order{
+			ReferenceNo:    req.ReferenceNo,
+			OrderCode:      s.nextOrderCode(),
+			AddTime:        time.Now().Format(time.DateTime),
+			OrderStatus:    entity.OrderForecasted,
+			Remark:         req.Remark,
+			Firstname:      req.OAFirstname,
+			Company:        req.OACompany,
+			Country:        req.OACountry,
+			Postcode:       req.OAPostcode,
+			State:          req.OAState,
+			City:           req.OACity,
+			StreetAddress1: req.OAStreetAddress1,
+			TelPhone:       req.OATelephone,
+		},
+		SMCode:      req.SMCode,
+		LabelStatus: 2,
+		Fee: []entity.Fee{
+			{FtCode: "Freight", Amount: quote.TotalCharge, CurrencyCode: quote.CurrencyCode, FtName: "运费"},
+		},
+	}
+	boxQuote := req
+	for i, box := range req.BoxList {
+		number := s.nextTrackingNumber()
+		o.Labels = append(o.Labels, s.label(number, req.LabelImageFormat))
+		boxQuote.BoxList = []orderBox{box}
+		q, _ := s.quote(boxQuote)
+		o.FeeDetail = append(o.FeeDetail, entity.FeeDetail{
+			Fee:            entity.Fee{FtCode: "Freight", Amount: q.TotalCharge, CurrencyCode: q.CurrencyCode, FtName: "运费"},
+			TrackingNumber: number,
+			BoxCode:        fmt.Sprintf("%s-%d", o.OrderCode, i+1),
+		})
+	}
+	o.MergeLabel = fmt.Sprintf("%s/labels/%s.pdf", s.URL, o.OrderCode)
+	message := "success"
+	if s.ForecastPolls <= 0 && s.ForecastError != "" {
+		// 同步预报失败
+		o.OrderStatus = entity.OrderSubmitted
+		o.LabelStatus = 0
+		o.LogisticsErr = s.ForecastError
+		o.Labels, o.MergeLabel = nil, ""
+		for i := range o.FeeDetail {
+			o.FeeDetail[i].TrackingNumber = ""
+		}
+		message = s.ForecastError
+	} else if s.ForecastPolls > 0 {
+		// 异步预报，面单和物流单号需要通过获取面单接口获取
+		o.OrderStatus = entity.OrderSubmitted
+		o.LabelStatus = 1
+		o.polls = s.ForecastPolls
+		o.pending, o.Labels, o.MergeLabel = o.Labels, nil, ""
+		for i := range o.FeeDetail {
+			o.FeeDetail[i].TrackingNumber = ""
+		}
+	}
+	s.orders[o.OrderCode] = o
+
+	writeJSON(w, http.StatusOK, message, entity.OrderCreateResult{
+		OrderCode:   o.OrderCode,
+		LabelStatus: o.LabelStatus,
+		Fee:         o.Fee,
+		FeeDetail:   o.FeeDetail,
+		Labels:      o.Labels,
+		MergeLabel:  o.MergeLabel,
+	})
+}
+
+func (s *Server) getOrderInfo(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Type        int    `json:"type"`
+		OrderCode   string `json:"order_code"`
+		ReferenceNo string `json:"reference_no"`
+		DateFrom    string `json:"date_from"`
+		DateTo      string `json:"date_to"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := make([]entity.Order, 0)
+	if req.Type == 1 {
+		for _, o := range s.orders {
+			if (req.DateFrom == "" || o.AddTime >= req.DateFrom) && (req.DateTo == "" || o.AddTime <= req.DateTo) {
+				orders = append(orders, o.Order)
+			}
+		}
+		sort.Slice(orders, func(i, j int) bool {
+			return orders[i].OrderCode < orders[j].OrderCode
+		})
+	} else if o := s.findOrder(req.OrderCode, req.ReferenceNo); o != nil {
+		orders = append(orders, o.Order)
+	}
+	writeJSON(w, http.StatusOK, "success", orders)
+}
+
+func (s *Server) cancelOrder(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OrderCode   string `json:"order_code"`
+		ReferenceNo string `json:"reference_no"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.findOrder(req.OrderCode, req.ReferenceNo)
+	if o == nil {
+		writeJSON(w, http.StatusBadRequest, "订单不存在", nil)
+		return
+	}
+	if o.OrderStatus != entity.OrderCanceled {
+		if !o.OrderStatus.CanCancel() {
+			writeJSON(w, http.StatusBadRequest, fmt.Sprintf("订单状态为%s，不允许取消", o.OrderStatus), nil)
+			return
+		}
+		o.OrderStatus = entity.OrderCanceled
+	}
+	writeJSON(w, http.StatusOK, "success", o.OrderStatus)
+}
+
+func (s *Server) rates(w http.ResponseWriter, r *http.Request) {
+	var req orderRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.quote(req)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf("物流产品 %s 不存在", req.SMCode), nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, "success", result)
+}
+
+func (s *Server) getLabel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OrderCode   string `json:"order_code"`
+		ReferenceNo string `json:"reference_no"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.findOrder(req.OrderCode, req.ReferenceNo)
+	if o == nil {
+		writeJSON(w, http.StatusBadRequest, "订单不存在", nil)
+		return
+	}
+	if o.polls > 0 {
+		o.polls--
+		if o.polls == 0 {
+			s.forecast(o)
+		}
+	}
+	label := entity.ShippingLabel{
+		ReferenceNo:      o.ReferenceNo,
+		OrderCode:        o.OrderCode,
+		OrderAddressType: "Residential",
+		OrderStatus:      o.OrderStatus,
+		LogisticsErr:     o.LogisticsErr,
+		Labels:           o.Labels,
+		MergeLabel:       o.MergeLabel,
+		Fee:              o.Fee,
+		FeeDetail:        o.FeeDetail,
+	}
+	if o.polls > 0 {
+		label.OrderSubStatus = entity.OrderSubStatusWaiting
+		label.OrderWaitingStatus = entity.OrderWaitingForecast
+	} else if o.LogisticsErr != "" {
+		label.OrderSubStatus = entity.OrderSubStatusFailed
+	}
+	writeJSON(w, http.StatusOK, "success", label)
+}
+
+// forecast 完成异步预报
+func (s *Server) forecast(o *Order) {
+	if s.ForecastError != "" {
+		o.LabelStatus = 0
+		o.LogisticsErr = s.ForecastError
+		return
+	}
+
+	o.OrderStatus = entity.OrderForecasted
+	o.LabelStatus = 2
+	o.Labels, o.pending = o.pending, nil
+	for i := range o.FeeDetail {
+		if i < len(o.Labels) {
+			o.FeeDetail[i].TrackingNumber = o.Labels[i].TrackingNumber
+		}
+	}
+	o.MergeLabel = fmt.Sprintf("%s/labels/%s.pdf", s.URL, o.OrderCode)
+}
+
+type logisticsLabel struct {
+	TrackingNumber string `json:"tracking_number"`
+	LabelUrl       string `json:"label_url"`
+	FileType       string `json:"file_type"`
+}
+
+func (s *Server) getLabelInfo(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TrackingNumber string `json:"tracking_number"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	numbers := make(map[string]bool)
+	for _, number := range strings.Split(req.TrackingNumber, ",") {
+		numbers[strings.TrimSpace(number)] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type result struct {
+		ReferenceNo string           `json:"reference_no"`
+		OrderCode   string           `json:"order_code"`
+		MergeLabel  string           `json:"merge_label"`
+		Labels      []logisticsLabel `json:"labels"`
+	}
+	results := make([]result, 0)
+	for _, o := range s.orders {
+		var labels []logisticsLabel
+		for _, label := range o.Labels {
+			if numbers[label.TrackingNumber] {
+				labels = append(labels, logisticsLabel{
+					TrackingNumber: label.TrackingNumber,
+					LabelUrl:       label.LabelUrl,
+					FileType:       label.FileType,
+				})
+			}
+		}
+		if len(labels) != 0 {
+			results = append(results, result{
+				ReferenceNo: o.ReferenceNo,
+				OrderCode:   o.OrderCode,
+				MergeLabel:  o.MergeLabel,
+				Labels:      labels,
+			})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].OrderCode < results[j].OrderCode
+	})
+	writeJSON(w, http.StatusOK, "success", results)
+}
+
+func (s *Server) createScanForm(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TrackingNumber string `json:"tracking_number"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, number := range strings.Split(req.TrackingNumber, ",") {
+		found := false
+		for _, o := range s.orders {
+			for _, label := range o.Labels {
+				if label.TrackingNumber == number {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			writeJSON(w, http.StatusBadRequest, fmt.Sprintf("跟踪号 %s 不存在", number), nil)
+			return
+		}
+	}
+	s.sequence++
+	writeJSON(w, http.StatusOK, "success", []entity.ScanForm{
+		{Url: fmt.Sprintf("%s/scanforms/%d.pdf", s.URL, s.sequence)},
+	})
+}
+
+func (s *Server) getUserInfo(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, "success", s.UserInfo)
+}
@@ -0,0 +1,314 @@
+// Package mazontest 提供一个进程内的美正接口模拟服务，用于在没有网络的环境下进行测试
+//
+//	s := mazontest.NewServer()
+//	defer s.Close()
+//	client := mazon.NewClient(ctx, s.Config())
+//
+// 模拟服务会在内存中保存订单数据，并且可以通过 Inject、ExpireTokens 等方法模拟接口错误、Token 失效和网络延迟。
+package mazontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hiscaler/mazon-go/config"
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+const (
+	AppKey       = "mazontest-app-key"   // 默认 App Key
+	AppToken     = "mazontest-app-token" // 默认 App Token
+	CustomerCode = "EPB001"              // 默认客户代码
+	ShipperCode  = "S0004"               // 默认发件人编码
+	pathPrefix   = "/api/svc"            // 接口路径前缀，兼容沙箱环境地址
+)
+
+// Product 物流产品，用于模拟运费计算
+type Product struct {
+	Base      float64 // 基础运费
+	PerWeight float64 // 每单位重量运费
+	Surcharge float64 // 附加费（比如签名服务）
+	Currency  string  // 币种，默认为 USD
+}
+
+// Fault 需要注入的异常
+type Fault struct {
+	Path       string        // 接口路径，比如 /createOrder，为空时匹配所有接口
+	Code       int           // 接口返回的业务错误码，比如 400、401、500
+	Message    string        // 接口返回的错误信息
+	HTTPStatus int           // HTTP 状态码，设置后直接返回该状态码（模拟网关错误）
+	Latency    time.Duration // 响应延迟
+	Times      int           // 生效次数，小于等于 0 时一直生效
+	Process    bool          // 是否先正常处理请求再返回异常（模拟请求已经处理成功，但是响应超时或者丢失），未设置 HTTPStatus、Code 时中断连接
+}
+
+// Server 美正接口模拟服务
+type Server struct {
+	URL      string // 服务地址
+	AppKey   string
+	AppToken string
+	UserInfo entity.UserInfo
+	Products map[string]Product // 物流产品
+	// ForecastPolls 创建订单后需要调用多少次获取面单接口才会返回面单（模拟异步预报），默认为 0，即同步返回面单
+	ForecastPolls int
+	// ForecastError 设置后预报将失败，同步预报时创建订单接口返回 label_status 为 0 和该错误信息，异步预报时获取面单接口返回该错误信息
+	ForecastError string
+	httpServer    *httptest.Server
+	mu            sync.Mutex
+	tokens        map[string]bool   // 已颁发的 Token
+	orders        map[string]*Order // 订单（以订单号为键）
+	faults        []*Fault
+	requests      map[string]int    // 各接口的请求次数
+	files         map[string][]byte // 自定义的面单文件（以路径为键）
+	sequence      int
+}
+
+// NewUnstartedServer 创建模拟服务但不启动，可以作为 http.Handler 使用，比如作为本地沙箱环境：
+//
+//	http.ListenAndServe("127.0.0.1:8090", mazontest.NewUnstartedServer())
+func NewUnstartedServer() *Server {
+	s := &Server{
+		AppKey:   AppKey,
+		AppToken: AppToken,
+		Products: map[string]Product{
+			"USPS GA13":  {Base: 4.5, PerWeight: 1.2, Surcharge: 3},
+			"UPS GROUND": {Base: 8, PerWeight: 0.9, Surcharge: 5.5},
+			"FEDEX HOME": {Base: 7.5, PerWeight: 1, Surcharge: 5},
+		},
+		tokens:   make(map[string]bool),
+		orders:   make(map[string]*Order),
+		requests: make(map[string]int),
+		files:    make(map[string][]byte),
+	}
+	s.UserInfo = entity.UserInfo{
+		Code:    CustomerCode,
+		Balance: "1000.00",
+		SmCode:  s.productCodes(),
+		Address: []entity.ShipperAddress{
+			{
+				ShipperCode:          ShipperCode,
+				ShipperName:          "Mazon Test",
+				ShipperTelPhone:      "9095550100",
+				ShipperCountry:       "US",
+				ShipperStateProvince: "CA",
+				ShipperCity:          "Ontario",
+				ShipperPostalCode:    "91761",
+				ShipperAddress1:      "2078 E Francis Street",
+			},
+		},
+	}
+	return s
+}
+
+// NewServer 创建并启动模拟服务，使用完毕后需要调用 Close 关闭
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// Start 启动模拟服务
+func (s *Server) Start() {
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+}
+
+// Close 关闭模拟服务
+func (s *Server) Close() {
+	if s.httpServer != nil {
+		s.httpServer.Close()
+	}
+}
+
+// Config 返回指向模拟服务的客户端配置
+func (s *Server) Config() config.Config {
+	return config.Config{
+		Timeout:  10,
+		AppKey:   s.AppKey,
+		AppToken: s.AppToken,
+		BaseURL:  s.URL,
+	}
+}
+
+// Inject 注入异常，按照注入的先后顺序匹配
+func (s *Server) Inject(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range faults {
+		f := faults[i]
+		s.faults = append(s.faults, &f)
+	}
+}
+
+// ResetFaults 清除所有注入的异常
+func (s *Server) ResetFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// ExpireTokens 使所有已颁发的 Token 失效，后续请求将返回 401
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]bool)
+}
+
+// Requests 返回指定接口的请求次数
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func (s *Server) productCodes() []string {
+	codes := make([]string, 0, len(s.Products))
+	for code := range s.Products {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// fault 返回当前请求需要模拟的异常
+func (s *Server) fault(path string) (Fault, bool) {
+	for i, f := range s.faults {
+		if f.Path != "" && f.Path != path {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+		return *f, true
+	}
+	return Fault{}, false
+}
+
+type response struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+	Result  any    `json:"result"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, message string, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response{Code: code, Message: message, Result: result})
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, pathPrefix)
+	s.mu.Lock()
+	s.requests[path]++
+	f, ok := s.fault(path)
+	s.mu.Unlock()
+
+	if ok {
+		if f.Process {
+			s.handle(httptest.NewRecorder(), r, path)
+		}
+		if f.Latency > 0 {
+			select {
+			case <-time.After(f.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if f.HTTPStatus != 0 {
+			http.Error(w, f.Message, f.HTTPStatus)
+			return
+		}
+		if f.Code != 0 {
+			writeJSON(w, f.Code, f.Message, nil)
+			return
+		}
+		if f.Process {
+			// 中断连接，客户端无法收到响应
+			panic(http.ErrAbortHandler)
+		}
+	}
+	s.handle(w, r, path)
+}
+
+// handle 处理请求
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method == http.MethodGet && (strings.HasPrefix(path, "/labels/") || strings.HasPrefix(path, "/scanforms/")) {
+		s.serveFile(w, path)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if path == "/getToken" {
+		s.getToken(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	authorized := s.tokens[r.Header.Get("Authorization")]
+	s.mu.Unlock()
+	if !authorized {
+		writeJSON(w, http.StatusUnauthorized, "token invalid", nil)
+		return
+	}
+
+	switch path {
+	case "/createOrder":
+		s.createOrder(w, r)
+	case "/getOrderInfo":
+		s.getOrderInfo(w, r)
+	case "/cancelOrder":
+		s.cancelOrder(w, r)
+	case "/rates":
+		s.rates(w, r)
+	case "/getLabel":
+		s.getLabel(w, r)
+	case "/getLabelInfo":
+		s.getLabelInfo(w, r)
+	case "/createScanForm":
+		s.createScanForm(w, r)
+	case "/getUserInfo":
+		s.getUserInfo(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) getToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppKey   string `json:"app_key"`
+		AppToken string `json:"app_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, "参数错误", nil)
+		return
+	}
+	if req.AppKey != s.AppKey || req.AppToken != s.AppToken {
+		writeJSON(w, http.StatusBadRequest, "app_key 或 app_token 错误", nil)
+		return
+	}
+
+	s.mu.Lock()
+	s.sequence++
+	token := fmt.Sprintf("mazontest-token-%d", s.sequence)
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	result := entity.Token{AccessToken: token}
+	result.UserInfo.ID = 1
+	result.UserInfo.Account = "mazontest"
+	result.UserInfo.CustomerCode = s.UserInfo.Code
+	writeJSON(w, http.StatusOK, "success", result)
+}
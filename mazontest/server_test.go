@@ -0,0 +1,34 @@
+package mazontest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func post(t *testing.T, url, token, body string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	assert.Nil(t, err)
+	req.Header.Set("Authorization", token)
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	return resp
+}
+
+func TestServer_Inject(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.Inject(Fault{Path: "/getUserInfo", HTTPStatus: http.StatusBadGateway, Times: 1})
+	resp := post(t, s.URL+"/getUserInfo", "", "{}")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+	// 只生效一次
+	resp = post(t, s.URL+pathPrefix+"/getUserInfo", "", "{}")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, s.Requests("/getUserInfo"))
+}
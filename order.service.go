@@ -3,6 +3,11 @@ package mazon
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -13,10 +18,10 @@ import (
 type orderService service
 
 type OrderBox struct {
-	Length          float64 `json:"box_length"`                  // 长（支持两位小数）
-	Width           float64 `json:"box_width"`                   // 宽（支持两位小数）
-	Height          float64 `json:"box_height"`                  // 高（支持两位小数）
-	ActualWeight    float64 `json:"box_actual_weight"`           // 箱子重量（单位 kg，支持两位小数）
+	Length          float64 `json:"box_length"`                  // 长（单位由 WeightUnitType 决定，公制为 cm，英制为 inch，支持两位小数）
+	Width           float64 `json:"box_width"`                   // 宽（单位同长）
+	Height          float64 `json:"box_height"`                  // 高（单位同长）
+	ActualWeight    float64 `json:"box_actual_weight"`           // 箱子重量（单位由 WeightUnitType 决定，公制为 kg，英制为 lbs，支持两位小数）
 	Sku             string  `json:"sku,omitempty"`               // SKU
 	CnName          string  `json:"cn_name,omitempty"`           // 中文名称
 	EngName         string  `json:"eng_name,omitempty"`          // 英文名称
@@ -68,7 +73,7 @@ func (m OrderBox) Validate() error {
 		validation.Field(&m.CnMaterial, validation.When(m.CnMaterial != "", validation.Length(1, 35).Error("中文材质不能超过 {{.max}} 个字符"))),
 		validation.Field(&m.EngMaterial, validation.When(m.EngMaterial != "", validation.Length(1, 35).Error("英文材质不能超过 {{.max}} 个字符"))),
 		validation.Field(&m.ProduceCountry, validation.When(m.ProduceCountry != "", validation.Length(1, 35).Error("生产国家不能超过 {{.max}} 个字符"))),
-		validation.Field(&m.Remark, validation.When(m.Remark != "", validation.Length(1, 35).Error("生产国家不能超过 {{.max}} 个字符"))),
+		validation.Field(&m.Remark, validation.When(m.Remark != "", validation.Length(1, 35).Error("备注不能超过 {{.max}} 个字符"))),
 	)
 }
 
@@ -101,33 +106,13 @@ type CreateOrderRequest struct {
 }
 
 func (m CreateOrderRequest) Validate() error {
-	return validation.ValidateStruct(&m,
+	rules := []*validation.FieldRules{
 		validation.Field(&m.ReferenceNO,
 			validation.Required.Error("订单参考号不能为空"),
 			validation.Length(1, 35).Error("订单参考号不能超过 {{.max}} 个字符"),
 		),
 		validation.Field(&m.SMCode, validation.Required.Error("物流产品代码不能为空")),
 		validation.Field(&m.Remark, validation.When(m.Remark != "", validation.Length(1, 35).Error("备注不能超过 {{.max}} 个字符"))),
-		validation.Field(&m.OAFirstname,
-			validation.Required.Error("收件人不能为空"),
-			validation.Length(3, 35).Error("收件人长度必须在 {{.min}} ~ {{.max}} 个字符"),
-		),
-		validation.Field(&m.OACompany, validation.When(m.OACompany != "", validation.Length(1, 35).Error("收件人公司不能超过 {{.max}} 个字符"))),
-		validation.Field(&m.OAStreetAddress1,
-			validation.Required.Error("收件人地址1不能为空"),
-			validation.Length(1, 35).Error("收件人地址1长度不能超过 {{.max}} 个字符"),
-		),
-		validation.Field(&m.OAStreetAddress2,
-			validation.When(m.OAStreetAddress2 != "", validation.Length(1, 35).Error("收件人地址2长度不能超过 {{.max}} 个字符")),
-		),
-		validation.Field(&m.OAPostcode, validation.Required.Error("收件人邮编不能为空")),
-		validation.Field(&m.OAState, validation.Required.Error("收件人州不能为空")),
-		validation.Field(&m.OACity, validation.Required.Error("收件人城市不能为空")),
-		validation.Field(&m.OACountry, validation.Required.Error("收件人国家（国家二字码）不能为空")),
-		validation.Field(&m.OATelephone,
-			validation.Required.Error("收件人电话不能为空"),
-			validation.Length(10, 15).Error("收件人电话长度必须在 {{.min}} ~ {{.max}} 个字符"),
-		),
 		validation.Field(&m.SignatureService,
 			validation.When(m.SignatureService != "", validation.In("ASS", "SSF").ErrorObject(validation.NewError("422", "无效的签名服务 {{.value}}").SetParams(map[string]interface{}{"value": m.SignatureService}))),
 		),
@@ -135,14 +120,22 @@ func (m CreateOrderRequest) Validate() error {
 		validation.Field(&m.BoxList, validation.Required.Error("包裹信息不能为空")),
 		validation.Field(&m.ShipperAddress, validation.When(m.ShipperCode == "", validation.Required.Error("发件人信息和编码必须填写一个"))),
 		validation.Field(&m.ShipperCode, validation.When(m.ShipperAddress == nil, validation.Required.Error("发件人信息和编码必须填写一个"))),
-	)
+	}
+	rules = append(rules, recipientRules(&m.OAFirstname, &m.OACompany, &m.OATelephone, &m.OACountry, &m.OAState, &m.OACity, &m.OAPostcode, &m.OAStreetAddress1, &m.OAStreetAddress2)...)
+	return validation.ValidateStruct(&m, rules...)
 }
 
 // Create 创建订单
 // https://www.mazonlabel.com/docs/orderapi/%E5%88%9B%E5%BB%BA%E8%AE%A2%E5%8D%95.html
 func (s orderService) Create(ctx context.Context, req CreateOrderRequest) (createRes entity.OrderCreateResult, err error) {
+	if s.normalizer != nil {
+		if err = req.NormalizeAddresses(s.normalizer); err != nil {
+			err = invalidInput(localeOf(ctx, s.config), err)
+			return
+		}
+	}
 	if err = req.Validate(); err != nil {
-		err = invalidInput(err)
+		err = invalidInput(localeOf(ctx, s.config), err)
 		return
 	}
 
@@ -159,11 +152,200 @@ func (s orderService) Create(ctx context.Context, req CreateOrderRequest) (creat
 		return
 	}
 	if res.Result.LabelStatus == 0 {
-		return createRes, errors.New(res.Message)
+		// 预报失败时接口返回的 code 为 200，作为请求错误返回，同时可以使用 errors.Is(err, ErrForecastFailed) 判断
+		err = recheckError(resp, NormalResponse{Code: BadRequestError, Message: res.Message}, nil)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			apiErr.Err = ErrForecastFailed
+		}
+		return
 	}
 	return res.Result, nil
 }
 
+// CreateAndWait 创建订单并等待面单和物流单号生成，返回最终的面单信息
+//
+// 轮询规则参见 shippingLabelService.WaitReady
+func (s orderService) CreateAndWait(ctx context.Context, req CreateOrderRequest, opts WaitOptions) (entity.ShippingLabel, error) {
+	createRes, err := s.Create(ctx, req)
+	if err != nil {
+		return entity.ShippingLabel{}, err
+	}
+	return shippingLabelService(s).WaitReady(ctx, ShippingLabelDetailRequest{OrderCode: createRes.OrderCode}, opts)
+}
+
+// IdempotentCreateResult 幂等创建订单的结果
+type IdempotentCreateResult struct {
+	entity.OrderCreateResult
+	Recovered bool  // 是否为已经存在的订单（根据参考号查询恢复），false 表示本次新创建的订单
+	Cause     error // 恢复订单的原因（创建订单时的超时、内部错误、网络错误或者参考号重复错误），新创建的订单为 nil
+}
+
+// IdempotentOptions 幂等创建订单的设置
+type IdempotentOptions struct {
+	LookupAttempts int           // 根据参考号查询订单的次数，默认为 3
+	LookupInterval time.Duration // 查询不到订单时再次查询的间隔，每次加倍，默认为 1 秒
+	// Resubmit 无法确定订单是否已经创建并且多次查询不到订单时是否重新提交一次，
+	// 订单可能延迟出现在查询结果中，重新提交有重复创建订单的风险，默认为 false（返回 *OrderOutcomeUnknownError）
+	Resubmit bool
+}
+
+func (o IdempotentOptions) withDefaults() IdempotentOptions {
+	if o.LookupAttempts <= 0 {
+		o.LookupAttempts = 3
+	}
+	if o.LookupInterval <= 0 {
+		o.LookupInterval = time.Second
+	}
+	return o
+}
+
+// ErrOrderOutcomeUnknown 无法确定订单是否已经创建，可以使用 errors.Is 判断
+var ErrOrderOutcomeUnknown = errors.New("无法确定订单是否已经创建")
+
+// OrderOutcomeUnknownError 无法确定订单是否已经创建（创建订单时请求超时、内部错误或者网络错误，并且根据参考号查询不到订单）
+//
+// 需要稍后根据参考号查询订单确认，确认订单不存在后再重新提交。
+type OrderOutcomeUnknownError struct {
+	ReferenceNo string // 参考号
+	Err         error  // 创建订单时的错误
+	locale      Locale // 错误信息语言
+}
+
+func (e *OrderOutcomeUnknownError) Error() string {
+	return fmt.Sprintf(translate(e.locale, "%s（参考号：%s）：%s"), translate(e.locale, ErrOrderOutcomeUnknown.Error()), e.ReferenceNo, e.Err.Error())
+}
+
+func (e *OrderOutcomeUnknownError) Unwrap() error {
+	return e.Err
+}
+
+func (e *OrderOutcomeUnknownError) Is(target error) bool {
+	return target == ErrOrderOutcomeUnknown
+}
+
+// isAmbiguousError 是否为无法确定订单是否已经创建的错误（请求超时、美正内部错误、网络错误）
+func isAmbiguousError(e error) bool {
+	if errors.Is(e, ErrRateLimited) {
+		return false
+	}
+	if errors.Is(e, ErrTimeout) || errors.Is(e, ErrInternal) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(e, &urlErr) && !errors.Is(e, context.Canceled)
+}
+
+// duplicateReferenceNoMessage 参考号重复时接口返回的错误信息，比如：参考号 XXX 已存在
+var duplicateReferenceNoMessage = regexp.MustCompile(`^参考号\s*(.+?)\s*已存在$`)
+
+// isDuplicateReferenceNo 是否为参考号 referenceNo 重复的错误（只匹配接口返回的参考号已存在的错误信息，避免把其他错误当作订单已经存在）
+func isDuplicateReferenceNo(e error, referenceNo string) bool {
+	var apiErr *APIError
+	if !errors.As(e, &apiErr) || apiErr.Code != BadRequestError {
+		return false
+	}
+	match := duplicateReferenceNoMessage.FindStringSubmatch(strings.TrimSpace(apiErr.Message))
+	return match != nil && strings.EqualFold(match[1], referenceNo)
+}
+
+// recover 根据参考号查询已经创建（未取消）的订单，返回订单的面单和费用信息
+func (s orderService) recover(ctx context.Context, referenceNo string) (res entity.OrderCreateResult, found bool, err error) {
+	orders, err := s.Query(ctx, OrderQueryRequest{Type: 2, ReferenceNo: referenceNo})
+	if err != nil {
+		return res, false, err
+	}
+	i := slices.IndexFunc(orders, func(o entity.Order) bool {
+		return strings.EqualFold(o.ReferenceNo, referenceNo) && o.OrderStatus != entity.OrderCanceling && o.OrderStatus != entity.OrderCanceled
+	})
+	if i == -1 {
+		return res, false, nil
+	}
+
+	label, err := shippingLabelService(s).Detail(ctx, ShippingLabelDetailRequest{OrderCode: orders[i].OrderCode})
+	if err != nil {
+		return res, false, err
+	}
+	res = entity.OrderCreateResult{
+		OrderCode:   label.OrderCode,
+		LabelStatus: 1,
+		Fee:         label.Fee,
+		FeeDetail:   label.FeeDetail,
+		Labels:      label.Labels,
+		MergeLabel:  label.MergeLabel,
+	}
+	if res.OrderCode == "" {
+		res.OrderCode = orders[i].OrderCode
+	}
+	switch {
+	case label.LogisticsErr != "":
+		res.LabelStatus = 0
+	case labelReady(label):
+		res.LabelStatus = 2
+	}
+	return res, true, nil
+}
+
+// lookup 根据参考号查询订单，查询不到时按间隔（每次加倍）再次查询，最多查询 opts.LookupAttempts 次
+func (s orderService) lookup(ctx context.Context, referenceNo string, opts IdempotentOptions) (res entity.OrderCreateResult, found bool, err error) {
+	interval := opts.LookupInterval
+	for attempt := 1; ; attempt++ {
+		res, found, err = s.recover(ctx, referenceNo)
+		if err != nil || found || attempt >= opts.LookupAttempts {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return res, false, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
+
+// CreateIdempotent 以参考号（ReferenceNO）作为幂等键创建订单
+//
+// 创建订单时如果请求超时、美正内部错误、网络错误（无法确定订单是否已经创建）或者返回参考号重复，
+// 将根据参考号查询订单（Query，Type 为 2）和面单（ShippingLabel.Detail），订单存在时返回已有的订单（Recovered 为 true）。
+// 查询不到订单时按 opts 的间隔再次查询，仍然查询不到时：
+//   - 无法确定的错误返回 *OrderOutcomeUnknownError（包含参考号），opts.Resubmit 为 true 时重新提交一次；
+//   - 参考号重复（或者订单已取消）返回原始错误。
+func (s orderService) CreateIdempotent(ctx context.Context, req CreateOrderRequest, opts IdempotentOptions) (IdempotentCreateResult, error) {
+	opts = opts.withDefaults()
+	locale := localeOf(ctx, s.config)
+	for attempt := 1; ; attempt++ {
+		createRes, err := s.Create(ctx, req)
+		if err == nil {
+			return IdempotentCreateResult{OrderCreateResult: createRes}, nil
+		}
+		ambiguous := isAmbiguousError(err)
+		if !ambiguous && !isDuplicateReferenceNo(err, req.ReferenceNO) {
+			return IdempotentCreateResult{}, err
+		}
+
+		existing, found, lookupErr := s.lookup(ctx, req.ReferenceNO, opts)
+		if lookupErr != nil {
+			err = fmt.Errorf(translate(locale, "%w（根据参考号查询订单失败：%w）"), err, lookupErr)
+			if ambiguous {
+				err = &OrderOutcomeUnknownError{ReferenceNo: req.ReferenceNO, Err: err, locale: locale}
+			}
+			return IdempotentCreateResult{}, err
+		}
+		if found {
+			s.logger.InfoContext(ctx, "Recover order", "reference_no", req.ReferenceNO, "order_code", existing.OrderCode, "cause", err)
+			return IdempotentCreateResult{OrderCreateResult: existing, Recovered: true, Cause: err}, nil
+		}
+		if !ambiguous {
+			return IdempotentCreateResult{}, err
+		}
+		if !opts.Resubmit || attempt == 2 {
+			return IdempotentCreateResult{}, &OrderOutcomeUnknownError{ReferenceNo: req.ReferenceNO, Err: err, locale: locale}
+		}
+		// 多次查询不到订单，重新提交
+		s.logger.WarnContext(ctx, "Resubmit order", "reference_no", req.ReferenceNO, "cause", err)
+	}
+}
+
 type OrderQueryRequest struct {
 	Type        int    `json:"type"`                   // 类型（1 代表按时间搜索、2 代表按票搜索）
 	OrderCode   string `json:"order_code,omitempty"`   // 订单号
@@ -184,7 +366,7 @@ func (m OrderQueryRequest) validate() error {
 // https://www.mazonlabel.com/docs/orderapi/%E8%8E%B7%E5%8F%96%E8%AE%A2%E5%8D%95%E4%BF%A1%E6%81%AF.html
 func (s orderService) Query(ctx context.Context, req OrderQueryRequest) ([]entity.Order, error) {
 	if err := req.validate(); err != nil {
-		return nil, invalidInput(err)
+		return nil, invalidInput(localeOf(ctx, s.config), err)
 	}
 
 	res := struct {
@@ -196,10 +378,6 @@ func (s orderService) Query(ctx context.Context, req OrderQueryRequest) ([]entit
 		SetBody(req).
 		SetResult(&res).
 		Post("/getOrderInfo")
-	if err != nil {
-		return nil, err
-	}
-
 	if err = recheckError(resp, res.NormalResponse, err); err != nil {
 		return nil, err
 	}
@@ -223,15 +401,15 @@ func (m CancelOrderRequest) validate() error {
 // 在订单草稿、已预报、已提交（未在预报执行中）状态时可以进行取消订单操作。
 //
 // 返回值
-// 5：取消中、6：已取消
-func (s orderService) Cancel(ctx context.Context, req CancelOrderRequest) (int, error) {
+// entity.OrderCanceling（5：取消中）、entity.OrderCanceled（6：已取消），出错时返回 entity.OrderStatusUnknown
+func (s orderService) Cancel(ctx context.Context, req CancelOrderRequest) (entity.OrderStatus, error) {
 	if err := req.validate(); err != nil {
-		return -1, invalidInput(err)
+		return entity.OrderStatusUnknown, invalidInput(localeOf(ctx, s.config), err)
 	}
 
 	res := struct {
 		NormalResponse
-		Result int `json:"result"`
+		Result entity.OrderStatus `json:"result"`
 	}{}
 	resp, err := s.httpClient.R().
 		SetContext(ctx).
@@ -239,7 +417,7 @@ func (s orderService) Cancel(ctx context.Context, req CancelOrderRequest) (int,
 		SetResult(&res).
 		Post("/cancelOrder")
 	if err = recheckError(resp, res.NormalResponse, err); err != nil {
-		return -1, err
+		return entity.OrderStatusUnknown, err
 	}
 	return res.Result, nil
 }
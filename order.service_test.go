@@ -1,8 +1,13 @@
 package mazon
 
 import (
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,6 +57,120 @@ func Test_orderService_Cancel(t *testing.T) {
 	})
 	assert.Nil(t, err)
 	if err == nil {
-		assert.Contains(t, status, []int{5, 6})
+		assert.Contains(t, []entity.OrderStatus{entity.OrderCanceling, entity.OrderCanceled}, status)
+	}
+
+	// 出错时返回未知状态
+	status, err = client.Services.Order.Cancel(ctx, CancelOrderRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, entity.OrderStatusUnknown, status)
+}
+
+func Test_orderService_CreateIdempotent(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	c := NewClient(ctx, s.Config(), WithTokenStore(NewMemoryTokenStore()))
+	newRequest := func(referenceNo string) CreateOrderRequest {
+		return CreateOrderRequest{
+			ReferenceNO:      referenceNo,
+			SMCode:           "USPS GA13",
+			OAFirstname:      "ZZZ",
+			OATelephone:      "0731-12345678",
+			OACountry:        "US",
+			OAState:          "CA",
+			OACity:           "Ontario",
+			OAPostcode:       "91761",
+			OAStreetAddress1: "2078 E Francis Street",
+			BoxList:          []OrderBox{{Height: 1, Length: 1, Width: 1, ActualWeight: 1}},
+			IsMoreBox:        1,
+			ShipperCode:      mazontest.ShipperCode,
+		}
+	}
+
+	opts := IdempotentOptions{LookupInterval: 10 * time.Millisecond}
+	tests := []struct {
+		name      string
+		fault     mazontest.Fault
+		resubmit  bool
+		recovered bool
+		cause     error
+		requests  int
+	}{
+		{"created", mazontest.Fault{}, false, false, nil, 1},
+		{"bad gateway after created", mazontest.Fault{Process: true, HTTPStatus: http.StatusBadGateway}, false, true, ErrInternal, 1},
+		{"connection aborted after created", mazontest.Fault{Process: true}, false, true, nil, 0},
+		{"internal error before created with resubmit", mazontest.Fault{Code: InternalError}, true, false, nil, 2},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			referenceNo := fmt.Sprintf("IDEMPOTENT-%d", i)
+			before := s.Requests("/createOrder")
+			if tt.fault != (mazontest.Fault{}) {
+				tt.fault.Path = "/createOrder"
+				tt.fault.Times = 1
+				s.Inject(tt.fault)
+			}
+			opts := opts
+			opts.Resubmit = tt.resubmit
+			res, err := c.Services.Order.CreateIdempotent(ctx, newRequest(referenceNo), opts)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.recovered, res.Recovered)
+			if tt.cause != nil {
+				assert.ErrorIs(t, res.Cause, tt.cause)
+			}
+			if tt.requests != 0 {
+				assert.Equal(t, tt.requests, s.Requests("/createOrder")-before)
+			}
+			o, ok := s.Order(res.OrderCode)
+			if assert.True(t, ok) {
+				assert.Equal(t, referenceNo, o.ReferenceNo)
+			}
+			assert.Equal(t, 2, res.LabelStatus)
+			assert.NotEmpty(t, res.Labels)
+		})
+	}
+
+	// 参考号重复
+	res, err := c.Services.Order.CreateIdempotent(ctx, newRequest("IDEMPOTENT-0"), opts)
+	assert.Nil(t, err)
+	assert.True(t, res.Recovered)
+	assert.True(t, isDuplicateReferenceNo(res.Cause, "IDEMPOTENT-0"))
+
+	// 无法确定订单是否已经创建时默认不重新提交，多次查询后返回包含参考号的错误
+	s.Inject(mazontest.Fault{Path: "/createOrder", Code: InternalError, Times: 1})
+	creates, lookups := s.Requests("/createOrder"), s.Requests("/getOrderInfo")
+	_, err = c.Services.Order.CreateIdempotent(ctx, newRequest("IDEMPOTENT-UNKNOWN"), opts)
+	assert.ErrorIs(t, err, ErrOrderOutcomeUnknown)
+	assert.ErrorIs(t, err, ErrInternal)
+	var unknownErr *OrderOutcomeUnknownError
+	if assert.ErrorAs(t, err, &unknownErr) {
+		assert.Equal(t, "IDEMPOTENT-UNKNOWN", unknownErr.ReferenceNo)
+	}
+	assert.Equal(t, 1, s.Requests("/createOrder")-creates)
+	assert.Equal(t, 3, s.Requests("/getOrderInfo")-lookups)
+
+	// 查询订单失败
+	s.Inject(
+		mazontest.Fault{Path: "/createOrder", Code: InternalError, Times: 1},
+		mazontest.Fault{Path: "/getOrderInfo", Code: BadRequestError, Message: "查询失败", Times: 1},
+	)
+	_, err = c.Services.Order.CreateIdempotent(ctx, newRequest("IDEMPOTENT-LOOKUP"), opts)
+	assert.ErrorIs(t, err, ErrOrderOutcomeUnknown)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	// 其他错误直接返回
+	s.Inject(mazontest.Fault{Path: "/createOrder", Code: BadRequestError, Message: "余额不足", Times: 1})
+	_, err = c.Services.Order.CreateIdempotent(ctx, newRequest("IDEMPOTENT-BALANCE"), opts)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	assert.False(t, isDuplicateReferenceNo(err, "IDEMPOTENT-BALANCE"))
+	assert.NotErrorIs(t, err, ErrOrderOutcomeUnknown)
+
+	// 只有参考号已存在的错误才查询订单
+	for _, message := range []string{"参考号 IDEMPOTENT-0 已存在", "参考号 IDEMPOTENT-MISSING 不存在", "Reference number does not exist", "duplicate request"} {
+		s.Inject(mazontest.Fault{Path: "/createOrder", Code: BadRequestError, Message: message, Times: 1})
+		lookups = s.Requests("/getOrderInfo")
+		_, err = c.Services.Order.CreateIdempotent(ctx, newRequest("IDEMPOTENT-MISSING"), opts)
+		assert.ErrorIs(t, err, ErrBadRequest, message)
+		assert.Equal(t, 0, s.Requests("/getOrderInfo")-lookups, message)
 	}
 }
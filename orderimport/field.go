@@ -0,0 +1,97 @@
+package orderimport
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hiscaler/mazon-go"
+)
+
+// field 表头对应的请求字段
+type field struct {
+	box   bool  // 是否为包裹（OrderBox）字段
+	index []int // 字段在结构体中的位置（嵌套结构体为多级）
+}
+
+// fields 所有支持的字段，键为字段名称
+//
+// 订单字段使用 CreateOrderRequest 的 JSON 字段名称，发件人和退件地址使用 shipper_address.shipper_name 格式，
+// 包裹字段使用 OrderBox 的 JSON 字段名称，与订单字段同名的包裹字段（remark）需要使用 box.remark。
+var fields = func() map[string]field {
+	m := make(map[string]field)
+	orderType := reflect.TypeOf(mazon.CreateOrderRequest{})
+	for i := 0; i < orderType.NumField(); i++ {
+		f := orderType.Field(i)
+		name := jsonName(f)
+		switch {
+		case name == "" || name == "box_list":
+		case f.Type.Kind() == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct:
+			nested := f.Type.Elem()
+			for j := 0; j < nested.NumField(); j++ {
+				if nestedName := jsonName(nested.Field(j)); nestedName != "" {
+					m[name+"."+nestedName] = field{index: []int{i, j}}
+				}
+			}
+		default:
+			m[name] = field{index: []int{i}}
+		}
+	}
+
+	boxType := reflect.TypeOf(mazon.OrderBox{})
+	for i := 0; i < boxType.NumField(); i++ {
+		name := jsonName(boxType.Field(i))
+		if name == "" {
+			continue
+		}
+		f := field{box: true, index: []int{i}}
+		m["box."+name] = f
+		if _, ok := m[name]; !ok {
+			m[name] = f
+		}
+	}
+	return m
+}()
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// set 将单元格的值写入结构体（指针）的字段
+func (f field) set(ptr any, value string) error {
+	v := reflect.ValueOf(ptr).Elem()
+	for _, i := range f.index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || n != float64(int64(n)) {
+			return fmt.Errorf("无效的整数 %q", value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("无效的数字 %q", value)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", v.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+// Package orderimport 从 CSV、Excel（XLSX）文件批量创建订单
+//
+// 第一行为表头，表头名称为 CreateOrderRequest 或者 OrderBox 的 JSON 字段名称（不区分大小写，比如 reference_no、box_length），
+// 发件人和退件地址使用 shipper_address.shipper_name、return_address.city 格式，与订单字段同名的包裹字段使用 box.remark，
+// 其他名称的表头可以通过 WithColumns 设置对应的字段，无法识别的列将被忽略。
+//
+// 每行为一个包裹，参考号相同的多行合并为一个多包裹订单（订单字段以第一行为准，后续行可以留空）。
+// 所有订单在提交之前使用 Validate 规则进行验证，验证失败的订单不会提交。
+//
+//	im := orderimport.New(client, orderimport.WithConcurrency(4), orderimport.WithRateLimit(5))
+//	report, err := im.ImportFile(ctx, "orders.xlsx")
+//	err = report.WriteFile("orders.result.xlsx")
+package orderimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/entity"
+	"golang.org/x/time/rate"
+)
+
+var (
+	ErrEmptyFile              = errors.New("文件中没有数据")
+	ErrMissingReferenceColumn = errors.New("缺少参考号（reference_no）列")
+)
+
+// RowError 行错误
+type RowError struct {
+	Row     int    // 行号（表头为第 1 行），0 表示订单错误（适用于订单的所有行）
+	Field   string // 字段名称，比如 box_length、box_list.0.box_length
+	Message string // 错误信息
+}
+
+func (e RowError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// Order 待创建的订单
+type Order struct {
+	Rows    []int                    // 行号，与 Request.BoxList 一一对应
+	Request mazon.CreateOrderRequest // 创建订单请求
+	Errors  []RowError               // 解析和验证错误，有错误的订单不会提交
+	values  map[string]string        // 第一次出现的订单字段值
+}
+
+// rowErrors 返回指定行的错误（包括订单错误）
+func (o Order) rowErrors(row int) []string {
+	messages := make([]string, 0)
+	for _, e := range o.Errors {
+		if e.Row == 0 || e.Row == row {
+			messages = append(messages, e.Error())
+		}
+	}
+	return messages
+}
+
+// Importer 订单导入
+type Importer struct {
+	client      *mazon.Client
+	columns     map[string]string
+	defaults    func(req *mazon.CreateOrderRequest)
+	concurrency int
+	limiter     *rate.Limiter
+	normalizer  mazon.AddressNormalizer
+	idempotent  mazon.IdempotentOptions
+}
+
+// Option 导入选项
+type Option func(im *Importer)
+
+// WithColumns 设置表头对应的字段，键为表头名称（不区分大小写），值为字段名称，比如 {"参考号": "reference_no"}
+func WithColumns(columns map[string]string) Option {
+	return func(im *Importer) {
+		for header, name := range columns {
+			im.columns[normalize(header)] = normalize(name)
+		}
+	}
+}
+
+// WithDefaults 设置订单的默认值（在读取表格数据之前调用），比如默认的物流产品、发件人编码
+func WithDefaults(fn func(req *mazon.CreateOrderRequest)) Option {
+	return func(im *Importer) {
+		im.defaults = fn
+	}
+}
+
+// WithConcurrency 设置同时创建订单的数量，默认为 4
+func WithConcurrency(n int) Option {
+	return func(im *Importer) {
+		im.concurrency = max(n, 1)
+	}
+}
+
+// WithRateLimit 设置每秒最多创建的订单数量，小于等于 0 时不限制
+func WithRateLimit(perSecond float64) Option {
+	return func(im *Importer) {
+		im.limiter = nil
+		if perSecond > 0 {
+			im.limiter = rate.NewLimiter(rate.Limit(perSecond), 1)
+		}
+	}
+}
+
+// WithAddressNormalizer 设置地址规范化（比如 usaddress.Normalize），在验证之前规范化订单的收件人、发件人和退件地址
+func WithAddressNormalizer(normalize mazon.AddressNormalizer) Option {
+	return func(im *Importer) {
+		im.normalizer = normalize
+	}
+}
+
+// WithIdempotentOptions 设置幂等创建订单的查询和重新提交规则，默认不重新提交
+func WithIdempotentOptions(opts mazon.IdempotentOptions) Option {
+	return func(im *Importer) {
+		im.idempotent = opts
+	}
+}
+
+// New 创建订单导入
+func New(client *mazon.Client, opts ...Option) *Importer {
+	im := &Importer{
+		client:      client,
+		columns:     make(map[string]string),
+		concurrency: 4,
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+	return im
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Parse 将表格数据（第一行为表头）转换为订单并进行验证，验证错误信息的语言可以通过 mazon.WithLocale 设置
+func (im *Importer) Parse(ctx context.Context, records [][]string) ([]*Order, error) {
+	if len(records) < 2 {
+		return nil, ErrEmptyFile
+	}
+
+	columns := make([]string, len(records[0]))
+	referenceColumn := -1
+	for i, header := range records[0] {
+		name := normalize(header)
+		if mapped, ok := im.columns[name]; ok {
+			name = mapped
+		}
+		if _, ok := fields[name]; ok {
+			columns[i] = name
+		}
+		if name == "reference_no" {
+			referenceColumn = i
+		}
+	}
+	if referenceColumn == -1 {
+		return nil, ErrMissingReferenceColumn
+	}
+
+	orders := make([]*Order, 0)
+	byReference := make(map[string]*Order)
+	for i, record := range records[1:] {
+		row := i + 2
+		if isBlank(record) {
+			continue
+		}
+		referenceNo := strings.TrimSpace(cell(record, referenceColumn))
+		o := byReference[referenceNo]
+		if o == nil {
+			o = &Order{values: make(map[string]string)}
+			if im.defaults != nil {
+				im.defaults(&o.Request)
+			}
+			orders = append(orders, o)
+			// 没有参考号的行单独作为一个订单，由验证规则返回错误
+			if referenceNo != "" {
+				byReference[referenceNo] = o
+			}
+		}
+
+		var box mazon.OrderBox
+		for c, name := range columns {
+			value := strings.TrimSpace(cell(record, c))
+			if name == "" || value == "" {
+				continue
+			}
+			f := fields[name]
+			if f.box {
+				if err := f.set(&box, value); err != nil {
+					o.Errors = append(o.Errors, RowError{Row: row, Field: name, Message: err.Error()})
+				}
+				continue
+			}
+			if first, ok := o.values[name]; ok {
+				if first != value {
+					o.Errors = append(o.Errors, RowError{Row: row, Field: name, Message: fmt.Sprintf("与第 %d 行的值 %q 不一致", o.Rows[0], first)})
+				}
+				continue
+			}
+			o.values[name] = value
+			if err := f.set(&o.Request, value); err != nil {
+				o.Errors = append(o.Errors, RowError{Row: row, Field: name, Message: err.Error()})
+			}
+		}
+		o.Rows = append(o.Rows, row)
+		o.Request.BoxList = append(o.Request.BoxList, box)
+	}
+	if len(orders) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	for _, o := range orders {
+		o.values = nil
+		if len(o.Errors) != 0 {
+			continue
+		}
+		err := mazon.ValidateRequest(ctx, validatable(func() error {
+			if im.normalizer != nil {
+				if err := o.Request.NormalizeAddresses(im.normalizer); err != nil {
+					return err
+				}
+			}
+			return o.Request.Validate()
+		}))
+		var validationErr *mazon.ValidationError
+		if !errors.As(err, &validationErr) {
+			continue
+		}
+		for _, fe := range validationErr.Fields {
+			e := RowError{Field: fe.Field, Message: fe.Message}
+			// 包裹字段的错误对应到包裹所在的行，比如 box_list.1.box_length
+			var index int
+			if _, err := fmt.Sscanf(fe.Field, "box_list.%d.", &index); err == nil && index < len(o.Rows) {
+				e.Row = o.Rows[index]
+			}
+			o.Errors = append(o.Errors, e)
+		}
+	}
+	return orders, nil
+}
+
+// validatable 将规范化和验证函数包装为 validation.Validatable，以便使用 mazon.ValidateRequest 转换验证错误
+type validatable func() error
+
+func (fn validatable) Validate() error {
+	return fn()
+}
+
+func cell(record []string, i int) string {
+	if i < len(record) {
+		return record[i]
+	}
+	return ""
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// orderResult 订单创建结果
+type orderResult struct {
+	result    entity.OrderCreateResult
+	err       error
+	submitted bool
+	recovered bool // 订单已经存在（根据参考号恢复）
+}
+
+// Import 解析表格数据并创建订单，返回每一行的结果
+//
+// 验证失败的订单不会提交，单个订单创建失败不会影响其他订单，取消 ctx 后未提交的订单将不再提交。
+// 订单使用 CreateIdempotent 创建，重复导入同一个文件时已经创建的订单不会重复创建（状态为 recovered），
+// 无法确定订单是否已经创建时状态为 unknown，需要根据参考号确认后再处理。
+func (im *Importer) Import(ctx context.Context, records [][]string) (*Report, error) {
+	orders, err := im.Parse(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]orderResult, len(orders))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < im.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = im.create(ctx, orders[i].Request)
+			}
+		}()
+	}
+	for i, o := range orders {
+		if len(o.Errors) == 0 {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return newReport(orders, results), nil
+}
+
+// ImportFile 读取 CSV、XLSX 文件并创建订单
+func (im *Importer) ImportFile(ctx context.Context, filename string) (*Report, error) {
+	records, err := ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return im.Import(ctx, records)
+}
+
+func (im *Importer) create(ctx context.Context, req mazon.CreateOrderRequest) orderResult {
+	if err := ctx.Err(); err != nil {
+		return orderResult{err: err}
+	}
+	if im.limiter != nil {
+		if err := im.limiter.Wait(ctx); err != nil {
+			return orderResult{err: err}
+		}
+	}
+	res, err := im.client.Services.Order.CreateIdempotent(ctx, req, im.idempotent)
+	return orderResult{result: res.OrderCreateResult, err: err, submitted: true, recovered: res.Recovered}
+}
@@ -0,0 +1,207 @@
+package orderimport
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/hiscaler/mazon-go/usaddress"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+const ordersCSV = "\ufeffreference_no,sm_code,oa_firstname,oa_telphone,oa_country,oa_state,oa_city,oa_postcode,oa_street_address1,box_length,box_width,box_height,box_actual_weight,sku,备注\n" +
+	"IMPORT-1,USPS GA13,John Doe,9095550100,US,CA,Ontario,91761,2078 E Francis Street,10,10,10,1,SKU-1,first\n" +
+	"IMPORT-1,,,,,,,,,12,12,12,2,SKU-2,\n" +
+	"IMPORT-2,USPS GA13,Jane Doe,9095550100,US,CA,Ontario,91761,2078 E Francis Street,10,10,10,1.5,SKU-3,\n" +
+	",,,,,,,,,,,,,,\n" +
+	"IMPORT-3,USPS GA13,Jane Doe,9095550100,US,CA,Ontario,91761,2078 E Francis Street,10,10,10,1,SKU-4,\n" +
+	"IMPORT-3,USPS GA13,Jane Doe,9095550100,US,CA,Ontario,91761,2078 E Francis Street,10,10,0,1,SKU-5,\n" +
+	"IMPORT-4,UPS GROUND,Jane Doe,9095550100,US,CA,Ontario,91761,2078 E Francis Street,ten,10,10,1,SKU-6,\n" +
+	"IMPORT-4,FEDEX HOME,,,,,,,,10,10,10,1,SKU-7,\n"
+
+func newImporter(t *testing.T, opts ...Option) (*mazontest.Server, *Importer) {
+	s := mazontest.NewServer()
+	t.Cleanup(s.Close)
+	client := mazon.NewClient(context.Background(), s.Config(), mazon.WithTokenStore(mazon.NewMemoryTokenStore()))
+	opts = append([]Option{
+		WithColumns(map[string]string{"备注": "box.remark"}),
+		WithDefaults(func(req *mazon.CreateOrderRequest) {
+			req.IsMoreBox = 1
+			req.ShipperCode = mazontest.ShipperCode
+		}),
+	}, opts...)
+	return s, New(client, opts...)
+}
+
+func TestImporter_Parse(t *testing.T) {
+	_, im := newImporter(t)
+	records, err := ReadCSV(strings.NewReader(ordersCSV))
+	assert.Nil(t, err)
+	orders, err := im.Parse(context.Background(), records)
+	assert.Nil(t, err)
+	assert.Len(t, orders, 4)
+
+	// 多包裹订单
+	o := orders[0]
+	assert.Equal(t, []int{2, 3}, o.Rows)
+	assert.Empty(t, o.Errors)
+	assert.Equal(t, "IMPORT-1", o.Request.ReferenceNO)
+	assert.Equal(t, mazontest.ShipperCode, o.Request.ShipperCode)
+	assert.Len(t, o.Request.BoxList, 2)
+	assert.Equal(t, 12.0, o.Request.BoxList[1].Length)
+	assert.Equal(t, "SKU-2", o.Request.BoxList[1].Sku)
+	assert.Equal(t, "first", o.Request.BoxList[0].Remark)
+	assert.Empty(t, o.Request.Remark)
+
+	// 包裹验证错误对应到包裹所在的行
+	o = orders[2]
+	assert.Equal(t, []RowError{{Row: 7, Field: "box_list.1.box_height", Message: "高不能为空"}}, o.Errors)
+
+	// 数字格式错误、订单字段不一致
+	o = orders[3]
+	assert.Len(t, o.Errors, 2)
+	assert.Equal(t, 8, o.Errors[0].Row)
+	assert.Equal(t, "box_length", o.Errors[0].Field)
+	assert.Equal(t, 9, o.Errors[1].Row)
+	assert.Equal(t, "sm_code", o.Errors[1].Field)
+
+	_, err = im.Parse(context.Background(), [][]string{{"sku"}, {"SKU-1"}})
+	assert.ErrorIs(t, err, ErrMissingReferenceColumn)
+	_, err = im.Parse(context.Background(), [][]string{{"reference_no"}})
+	assert.ErrorIs(t, err, ErrEmptyFile)
+}
+
+func TestImporter_ParseNormalize(t *testing.T) {
+	records := [][]string{
+		{"reference_no", "sm_code", "oa_firstname", "oa_telphone", "oa_country", "oa_state", "oa_city", "oa_postcode", "oa_street_address1", "box_length", "box_width", "box_height", "box_actual_weight"},
+		{"IMPORT-1", "USPS GA13", "John Doe", "(909) 555-0100", "US", "California", "Ontario", "1761", "2078 E. Francis Street", "10", "10", "10", "1"},
+		{"IMPORT-2", "USPS GA13", "John Doe", "(909) 555-0100", "US", "Calif", "Ontario", "91761", "2078 E. Francis Street", "10", "10", "10", "1"},
+	}
+	// Excel 会去掉邮编的前导 0
+	_, im := newImporter(t, WithAddressNormalizer(usaddress.NewNormalizer(usaddress.WithZIPPadding())))
+	orders, err := im.Parse(context.Background(), records)
+	assert.Nil(t, err)
+	assert.Empty(t, orders[0].Errors)
+	assert.Equal(t, "CA", orders[0].Request.OAState)
+	assert.Equal(t, "01761", orders[0].Request.OAPostcode)
+	assert.Equal(t, "9095550100", orders[0].Request.OATelephone)
+	if assert.Len(t, orders[1].Errors, 1) {
+		assert.Equal(t, "oa_state", orders[1].Errors[0].Field)
+	}
+}
+
+func TestImporter_Import(t *testing.T) {
+	s, im := newImporter(t, WithConcurrency(1), WithRateLimit(100))
+	s.Inject(mazontest.Fault{Path: "/createOrder", Code: mazon.BadRequestError, Message: "余额不足", Times: 1})
+	records, err := ReadCSV(strings.NewReader(ordersCSV))
+	assert.Nil(t, err)
+	report, err := im.Import(context.Background(), records)
+	assert.Nil(t, err)
+
+	assert.Len(t, report.Rows, 7)
+	// 第一个订单创建失败
+	assert.Equal(t, StatusFailed, report.Rows[0].Status)
+	assert.Contains(t, report.Rows[0].Error, "余额不足")
+	assert.Equal(t, StatusFailed, report.Rows[1].Status)
+
+	row := report.Rows[2]
+	assert.Equal(t, 4, row.Row)
+	assert.Equal(t, StatusCreated, row.Status)
+	assert.NotEmpty(t, row.OrderCode)
+	assert.NotEmpty(t, row.TrackingNumber)
+	assert.False(t, row.Fee.Amount.IsZero())
+	assert.Equal(t, row.Fee, row.TotalFee)
+
+	assert.Equal(t, StatusInvalid, report.Rows[3].Status)
+	assert.Empty(t, report.Rows[3].Error)
+	assert.Equal(t, StatusInvalid, report.Rows[4].Status)
+	assert.Contains(t, report.Rows[4].Error, "box_list.1.box_height")
+	assert.Equal(t, []string{"IMPORT-2"}, report.Created())
+	assert.Equal(t, []string{"IMPORT-1", "IMPORT-3", "IMPORT-4"}, report.Failed())
+	assert.Equal(t, 2, s.Requests("/createOrder"))
+
+	var b bytes.Buffer
+	assert.Nil(t, report.WriteCSV(&b))
+	result, err := ReadCSV(&b)
+	assert.Nil(t, err)
+	assert.Equal(t, reportHeader, result[0])
+	assert.Equal(t, []string{"4", "IMPORT-2", "created", row.OrderCode, row.TrackingNumber, row.Fee.Amount.String(), row.Fee.Currency, row.TotalFee.Amount.String(), ""}, result[3])
+}
+
+func TestImporter_ImportUnknown(t *testing.T) {
+	s, im := newImporter(t, WithConcurrency(1), WithIdempotentOptions(mazon.IdempotentOptions{LookupAttempts: 1}))
+	s.Inject(mazontest.Fault{Path: "/createOrder", Code: mazon.InternalError, Times: 1})
+	records, err := ReadCSV(strings.NewReader(ordersCSV))
+	assert.Nil(t, err)
+	report, err := im.Import(context.Background(), records[:4])
+	assert.Nil(t, err)
+
+	// 无法确定是否已经创建的订单不会重新提交
+	assert.Equal(t, StatusUnknown, report.Rows[0].Status)
+	assert.Contains(t, report.Rows[0].Error, "IMPORT-1")
+	assert.Equal(t, StatusCreated, report.Rows[2].Status)
+	assert.Equal(t, []string{"IMPORT-1"}, report.Failed())
+	assert.Equal(t, 2, s.Requests("/createOrder"))
+}
+
+func TestImporter_ImportFile(t *testing.T) {
+	s, im := newImporter(t)
+	records, err := ReadCSV(strings.NewReader(ordersCSV))
+	assert.Nil(t, err)
+
+	// 只导入前 2 个订单
+	f := excelize.NewFile()
+	for i, record := range records[:4] {
+		values := make([]any, len(record))
+		for j, v := range record {
+			values[j] = v
+		}
+		assert.Nil(t, f.SetSheetRow("Sheet1", "A"+string(rune('1'+i)), &values))
+	}
+	filename := filepath.Join(t.TempDir(), "orders.xlsx")
+	assert.Nil(t, f.SaveAs(filename))
+
+	report, err := im.ImportFile(context.Background(), filename)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"IMPORT-1", "IMPORT-2"}, report.Created())
+	assert.NotEqual(t, report.Rows[0].TrackingNumber, report.Rows[1].TrackingNumber)
+	assert.Equal(t, report.Rows[0].OrderCode, report.Rows[1].OrderCode)
+	o, ok := s.Order(report.Rows[0].OrderCode)
+	assert.True(t, ok)
+	assert.Len(t, o.Labels, 2)
+
+	// 重复导入不会重复创建订单
+	again, err := im.ImportFile(context.Background(), filename)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"IMPORT-1", "IMPORT-2"}, again.Created())
+	assert.Equal(t, StatusRecovered, again.Rows[0].Status)
+	assert.Equal(t, report.Rows[0].OrderCode, again.Rows[0].OrderCode)
+	assert.Equal(t, report.Rows[1].TrackingNumber, again.Rows[1].TrackingNumber)
+
+	resultFile := filepath.Join(t.TempDir(), "result.xlsx")
+	assert.Nil(t, report.WriteFile(resultFile))
+	rows, err := ReadFile(resultFile)
+	assert.Nil(t, err)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, "created", rows[1][2])
+
+	_, err = ReadFile("orders.txt")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestImporter_ImportCanceled(t *testing.T) {
+	s, im := newImporter(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	records, _ := ReadCSV(strings.NewReader(ordersCSV))
+	report, err := im.Import(ctx, records)
+	assert.Nil(t, err)
+	assert.Equal(t, StatusSkipped, report.Rows[0].Status)
+	assert.Empty(t, report.Created())
+	assert.Equal(t, 0, s.Requests("/createOrder"))
+}
@@ -0,0 +1,61 @@
+package orderimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrUnsupportedFormat = errors.New("不支持的文件格式，仅支持 CSV 和 XLSX 文件")
+
+// ReadCSV 读取 CSV 文件的所有行（支持 UTF-8 BOM）
+func ReadCSV(r io.Reader) ([][]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != 0 && len(records[0]) != 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+	return records, nil
+}
+
+// ReadXLSX 读取 Excel 文件中指定工作表的所有行，sheet 为空时读取第一个工作表
+func ReadXLSX(r io.Reader, sheet string) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	return f.GetRows(sheet)
+}
+
+// ReadFile 根据扩展名（.csv、.xlsx）读取文件的所有行，Excel 文件读取第一个工作表
+func ReadFile(filename string) ([][]string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext != ".csv" && ext != ".xlsx" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, ext)
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if ext == ".xlsx" {
+		return ReadXLSX(file, "")
+	}
+	return ReadCSV(file)
+}
@@ -0,0 +1,197 @@
+package orderimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/xuri/excelize/v2"
+)
+
+// RowStatus 行状态
+type RowStatus string
+
+const (
+	StatusCreated   RowStatus = "created"   // 创建成功
+	StatusRecovered RowStatus = "recovered" // 订单已经存在（根据参考号查询到已经创建的订单）
+	StatusInvalid   RowStatus = "invalid"   // 验证失败，未提交
+	StatusFailed    RowStatus = "failed"    // 创建失败
+	StatusUnknown   RowStatus = "unknown"   // 无法确定订单是否已经创建（需要根据参考号确认）
+	StatusSkipped   RowStatus = "skipped"   // 未提交（导入已取消）
+)
+
+// RowResult 行结果
+type RowResult struct {
+	Row            int          `json:"row"`             // 行号
+	ReferenceNo    string       `json:"reference_no"`    // 参考号
+	Status         RowStatus    `json:"status"`          // 状态
+	OrderCode      string       `json:"order_code"`      // 订单号
+	TrackingNumber string       `json:"tracking_number"` // 包裹的物流单号（异步预报时为空）
+	Fee            entity.Money `json:"fee"`             // 包裹费用
+	TotalFee       entity.Money `json:"total_fee"`       // 订单总费用
+	Error          string       `json:"error"`           // 错误信息
+}
+
+// Report 导入结果
+type Report struct {
+	Rows []RowResult // 各行结果（按行号排序）
+}
+
+func newReport(orders []*Order, results []orderResult) *Report {
+	r := &Report{Rows: make([]RowResult, 0)}
+	for i, o := range orders {
+		res := results[i]
+		var totalFee entity.Money
+		var fees map[string]entity.Money
+		if res.err == nil && res.submitted {
+			totalFee, _ = res.result.TotalFee()
+			fees, _ = res.result.FeesByTrackingNumber()
+		}
+		for j, row := range o.Rows {
+			rr := RowResult{Row: row, ReferenceNo: o.Request.ReferenceNO}
+			switch {
+			case len(o.Errors) != 0:
+				rr.Status = StatusInvalid
+				rr.Error = strings.Join(o.rowErrors(row), "; ")
+			case res.err != nil && !res.submitted:
+				rr.Status = StatusSkipped
+				rr.Error = res.err.Error()
+			case errors.Is(res.err, mazon.ErrOrderOutcomeUnknown):
+				rr.Status = StatusUnknown
+				rr.Error = res.err.Error()
+			case res.err != nil:
+				rr.Status = StatusFailed
+				rr.Error = res.err.Error()
+			default:
+				rr.Status = StatusCreated
+				if res.recovered {
+					rr.Status = StatusRecovered
+				}
+				rr.OrderCode = res.result.OrderCode
+				rr.TotalFee = totalFee
+				// 面单与包裹的顺序一致
+				if j < len(res.result.Labels) {
+					rr.TrackingNumber = res.result.Labels[j].TrackingNumber
+					rr.Fee = fees[rr.TrackingNumber]
+				}
+			}
+			r.Rows = append(r.Rows, rr)
+		}
+	}
+	slices.SortFunc(r.Rows, func(a, b RowResult) int {
+		return a.Row - b.Row
+	})
+	return r
+}
+
+// referenceNos 返回指定状态的订单参考号（去重，按行号排序）
+func (r *Report) referenceNos(match func(status RowStatus) bool) []string {
+	referenceNos := make([]string, 0)
+	for _, row := range r.Rows {
+		if match(row.Status) && !slices.Contains(referenceNos, row.ReferenceNo) {
+			referenceNos = append(referenceNos, row.ReferenceNo)
+		}
+	}
+	return referenceNos
+}
+
+func (s RowStatus) created() bool {
+	return s == StatusCreated || s == StatusRecovered
+}
+
+// Created 返回创建成功（包括已经存在）的订单参考号
+func (r *Report) Created() []string {
+	return r.referenceNos(RowStatus.created)
+}
+
+// Failed 返回没有创建成功（验证失败、创建失败、无法确定、未提交）的订单参考号
+func (r *Report) Failed() []string {
+	return r.referenceNos(func(status RowStatus) bool { return !status.created() })
+}
+
+var reportHeader = []string{"row", "reference_no", "status", "order_code", "tracking_number", "fee", "currency", "total_fee", "error"}
+
+func (r *Report) records() [][]string {
+	records := make([][]string, 0, len(r.Rows)+1)
+	records = append(records, reportHeader)
+	for _, row := range r.Rows {
+		currency := row.TotalFee.Currency
+		if currency == "" {
+			currency = row.Fee.Currency
+		}
+		fee, totalFee := "", ""
+		if row.Status.created() {
+			fee, totalFee = row.Fee.Amount.String(), row.TotalFee.Amount.String()
+		}
+		records = append(records, []string{
+			strconv.Itoa(row.Row),
+			row.ReferenceNo,
+			string(row.Status),
+			row.OrderCode,
+			row.TrackingNumber,
+			fee,
+			currency,
+			totalFee,
+			row.Error,
+		})
+	}
+	return records
+}
+
+// WriteCSV 将结果写入 CSV 文件
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(r.records()); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteXLSX 将结果写入 Excel 文件
+func (r *Report) WriteXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	for i, record := range r.records() {
+		values := make([]any, len(record))
+		for j, v := range record {
+			values[j] = v
+		}
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+1), &values); err != nil {
+			return err
+		}
+	}
+	return f.Write(w)
+}
+
+// WriteFile 根据扩展名（.csv、.xlsx）将结果写入文件
+func (r *Report) WriteFile(filename string) (err error) {
+	write := r.WriteCSV
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".csv":
+	case ".xlsx":
+		write = r.WriteXLSX
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, ext)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return write(file)
+}
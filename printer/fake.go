@@ -0,0 +1,121 @@
+package printer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// FakePrinter 进程内的模拟打印机，接收 RAW 打印数据并按 ^XA ... ^XZ 拆分为面单，用于测试
+//
+//	f, err := printer.NewFakePrinter()
+//	p := printer.New(f.Addr())
+//	report, err := p.Print(ctx, jobs...)
+//	labels, err := f.WaitLabels(ctx, len(jobs))
+//	f.Close()
+type FakePrinter struct {
+	listener    net.Listener
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	labels      [][]byte
+	connections int
+}
+
+// NewFakePrinter 创建并启动模拟打印机，使用完毕后需要调用 Close 关闭
+func NewFakePrinter() (*FakePrinter, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	f := &FakePrinter{listener: listener}
+	f.wg.Add(1)
+	go f.serve()
+	return f, nil
+}
+
+// Addr 返回模拟打印机地址
+func (f *FakePrinter) Addr() string {
+	return f.listener.Addr().String()
+}
+
+func (f *FakePrinter) serve() {
+	defer f.wg.Done()
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.connections++
+		f.mu.Unlock()
+		// 和真实的打印机一样，依次处理连接
+		b, _ := io.ReadAll(conn)
+		conn.Close()
+		labels := splitLabels(b)
+		f.mu.Lock()
+		f.labels = append(f.labels, labels...)
+		f.mu.Unlock()
+	}
+}
+
+// splitLabels 按 ^XA ... ^XZ 拆分面单
+func splitLabels(b []byte) [][]byte {
+	var labels [][]byte
+	for {
+		start := bytes.Index(b, []byte("^XA"))
+		if start < 0 {
+			return labels
+		}
+		end := bytes.Index(b[start:], []byte("^XZ"))
+		if end < 0 {
+			return labels
+		}
+		end += start + len("^XZ")
+		labels = append(labels, bytes.Clone(b[start:end]))
+		b = b[end:]
+	}
+}
+
+// Labels 返回已接收的面单
+func (f *FakePrinter) Labels() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	labels := make([]string, len(f.labels))
+	for i, label := range f.labels {
+		labels[i] = string(label)
+	}
+	return labels
+}
+
+// WaitLabels 等待接收到至少 n 个面单后返回已接收的面单
+func (f *FakePrinter) WaitLabels(ctx context.Context, n int) ([]string, error) {
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if labels := f.Labels(); len(labels) >= n {
+			return labels, nil
+		}
+		select {
+		case <-ctx.Done():
+			return f.Labels(), ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Connections 返回已接受的连接数量
+func (f *FakePrinter) Connections() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connections
+}
+
+// Close 关闭模拟打印机，等待正在处理的连接完成（尚未接受的连接将被丢弃）
+func (f *FakePrinter) Close() error {
+	err := f.listener.Close()
+	f.wg.Wait()
+	return err
+}
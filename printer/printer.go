@@ -0,0 +1,297 @@
+// Package printer 将 ZPL 面单发送到网络标签打印机（RAW 打印，默认端口 9100）
+//
+//	p := printer.New("192.168.1.100", printer.WithRetry(3, time.Second))
+//	jobs, err := printer.JobsFromLabelFiles(files, 1) // files 为 mazon.LabelDownloader 下载的 ZPL 面单
+//	report, err := p.Print(ctx, jobs...)
+//	for trackingNumber, result := range report.Results {
+//		fmt.Println(trackingNumber, result.Err)
+//	}
+package printer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hiscaler/mazon-go"
+)
+
+// DefaultPort RAW 打印端口
+const DefaultPort = "9100"
+
+var (
+	ErrInvalidZPL              = errors.New("无效的 ZPL 面单")
+	ErrNoJobs                  = errors.New("没有需要打印的面单")
+	ErrDuplicateTrackingNumber = errors.New("重复的物流单号")
+)
+
+// Job 打印任务
+type Job struct {
+	TrackingNumber string // 物流单号
+	Data           []byte // ZPL 面单内容
+	Copies         int    // 打印份数，小于 1 时为 1
+}
+
+// Result 打印结果
+type Result struct {
+	TrackingNumber string    `json:"tracking_number"` // 物流单号
+	Copies         int       `json:"copies"`          // 打印份数
+	Sent           int       `json:"sent"`            // 已发送的份数（小于 Copies 时为部分打印）
+	Attempts       int       `json:"attempts"`        // 连接次数
+	Err            error     `json:"-"`               // 错误，为 nil 时表示已成功发送到打印机
+	PrintedAt      time.Time `json:"printed_at"`      // 发送完成时间
+}
+
+// Report 打印报告
+type Report struct {
+	Results map[string]Result // 打印结果（以物流单号为键）
+	Order   []string          // 物流单号（按打印顺序）
+}
+
+// Succeeded 返回发送成功的物流单号
+func (r Report) Succeeded() []string {
+	numbers := make([]string, 0, len(r.Order))
+	for _, number := range r.Order {
+		if r.Results[number].Err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+// Failed 返回发送失败的物流单号
+func (r Report) Failed() []string {
+	numbers := make([]string, 0)
+	for _, number := range r.Order {
+		if r.Results[number].Err != nil {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+// Dialer 建立网络连接
+type Dialer func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Printer 网络标签打印机
+type Printer struct {
+	addr          string
+	timeout       time.Duration // 连接和写入超时
+	retries       int           // 连接错误的重试次数
+	retryInterval time.Duration // 重试间隔
+	batchSize     int           // 每个连接发送的面单数量
+	dial          Dialer
+}
+
+// Option 打印机选项
+type Option func(p *Printer)
+
+// WithTimeout 设置连接和写入超时时间，默认为 10 秒
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Printer) {
+		p.timeout = timeout
+	}
+}
+
+// WithRetry 设置连接错误时的重试次数和重试间隔，默认重试 2 次，间隔 1 秒
+func WithRetry(retries int, interval time.Duration) Option {
+	return func(p *Printer) {
+		p.retries = max(retries, 0)
+		p.retryInterval = interval
+	}
+}
+
+// WithBatchSize 设置每个连接发送的面单数量，默认为 20
+func WithBatchSize(size int) Option {
+	return func(p *Printer) {
+		if size > 0 {
+			p.batchSize = size
+		}
+	}
+}
+
+// WithDialer 设置建立连接的方法（比如通过代理连接）
+func WithDialer(dial Dialer) Option {
+	return func(p *Printer) {
+		p.dial = dial
+	}
+}
+
+// New 创建打印机，addr 为打印机地址，不包含端口时使用 9100 端口
+func New(addr string, opts ...Option) *Printer {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), DefaultPort)
+	}
+	p := &Printer{
+		addr:          addr,
+		timeout:       10 * time.Second,
+		retries:       2,
+		retryInterval: time.Second,
+		batchSize:     20,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.dial == nil {
+		p.dial = (&net.Dialer{Timeout: p.timeout}).DialContext
+	}
+	return p
+}
+
+// Addr 返回打印机地址
+func (p *Printer) Addr() string {
+	return p.addr
+}
+
+// IsZPL 是否为 ZPL 内容
+func IsZPL(data []byte) bool {
+	return bytes.Contains(data, []byte("^XA")) && bytes.Contains(data, []byte("^XZ"))
+}
+
+// JobsFromLabelFiles 读取下载到本地的 ZPL 面单文件（mazon.LocalLabelStorage）生成打印任务，忽略其他格式的文件
+func JobsFromLabelFiles(files mazon.LabelFiles, copies int) ([]Job, error) {
+	jobs := make([]Job, 0, len(files))
+	for _, file := range files {
+		if file.Kind != mazon.LabelFileLabel || file.Format != mazon.LabelFormatZPL {
+			continue
+		}
+		b, err := os.ReadFile(file.Location)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, Job{TrackingNumber: file.TrackingNumber, Data: b, Copies: copies})
+	}
+	return jobs, nil
+}
+
+// Print 按顺序打印面单，多份时重复发送面单内容
+//
+// 每 batchSize 个面单使用一个连接发送，连接失败或发送中断时重新连接，从失败面单未发送的份数继续发送（最多重试 retries 次），
+// 已发送的份数不会重复打印（写入中断的那一份会重新发送），Result.Sent 为已发送的份数。
+// 部分面单失败时不返回错误，具体结果通过 Report 获取，只有 ctx 取消、没有打印任务或者物流单号重复时返回错误。
+func (p *Printer) Print(ctx context.Context, jobs ...Job) (Report, error) {
+	report := Report{Results: make(map[string]Result, len(jobs)), Order: make([]string, 0, len(jobs))}
+	if len(jobs) == 0 {
+		return report, ErrNoJobs
+	}
+
+	jobs = slices.Clone(jobs)
+	pending := make([]int, 0, len(jobs))
+	for i, job := range jobs {
+		if job.Copies < 1 {
+			jobs[i].Copies = 1
+		}
+		number := job.TrackingNumber
+		if number == "" {
+			number = fmt.Sprintf("#%d", i+1)
+			jobs[i].TrackingNumber = number
+		}
+		if _, exists := report.Results[number]; exists {
+			return Report{}, fmt.Errorf("%w: %s", ErrDuplicateTrackingNumber, number)
+		}
+		report.Order = append(report.Order, number)
+		result := Result{TrackingNumber: number, Copies: jobs[i].Copies}
+		if !IsZPL(job.Data) {
+			result.Err = ErrInvalidZPL
+		} else {
+			pending = append(pending, i)
+		}
+		report.Results[number] = result
+	}
+
+	for start := 0; start < len(pending); start += p.batchSize {
+		batch := pending[start:min(start+p.batchSize, len(pending))]
+		if err := p.printBatch(ctx, jobs, batch, report.Results); err != nil {
+			// ctx 已取消，剩余的面单都不再打印
+			for _, i := range pending[start:] {
+				result := report.Results[jobs[i].TrackingNumber]
+				if result.Err == nil && result.PrintedAt.IsZero() {
+					result.Err = err
+					report.Results[jobs[i].TrackingNumber] = result
+				}
+			}
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// printBatch 使用一个连接发送一批面单，仅在 ctx 取消时返回错误
+func (p *Printer) printBatch(ctx context.Context, jobs []Job, batch []int, results map[string]Result) error {
+	attempt := 0
+	for len(batch) > 0 {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(p.retryInterval):
+			}
+		}
+		attempt++
+
+		sent, err := p.send(ctx, jobs, batch, attempt, results)
+		batch = batch[sent:]
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt > p.retries {
+			// 重试次数用完，当前批次剩余的面单全部失败
+			for _, i := range batch {
+				result := results[jobs[i].TrackingNumber]
+				result.Attempts = attempt
+				result.Err = err
+				results[jobs[i].TrackingNumber] = result
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// send 建立连接并依次发送面单，返回成功发送的面单数量
+func (p *Printer) send(ctx context.Context, jobs []Job, batch []int, attempt int, results map[string]Result) (int, error) {
+	conn, err := p.dial(ctx, "tcp", p.addr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// ctx 取消时中断写入
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetWriteDeadline(time.Now())
+	})
+	defer stop()
+
+	for n, i := range batch {
+		job := jobs[i]
+		if err = ctx.Err(); err != nil {
+			return n, err
+		}
+		if p.timeout > 0 {
+			_ = conn.SetWriteDeadline(time.Now().Add(p.timeout))
+		}
+		result := results[job.TrackingNumber]
+		result.Attempts = attempt
+		// 从第一份未发送的面单继续发送
+		for result.Sent < job.Copies {
+			if _, err = conn.Write(job.Data); err != nil {
+				results[job.TrackingNumber] = result
+				return n, err
+			}
+			result.Sent++
+		}
+		result.Err = nil
+		result.PrintedAt = time.Now()
+		results[job.TrackingNumber] = result
+	}
+	return len(batch), nil
+}
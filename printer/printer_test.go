@@ -0,0 +1,156 @@
+package printer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func label(trackingNumber string) []byte {
+	return []byte(fmt.Sprintf("^XA^FO50,50^BCN,100,Y,N,N^FD%s^FS^XZ\n", trackingNumber))
+}
+
+func waitCtx(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, "192.168.1.100:9100", New("192.168.1.100").Addr())
+	assert.Equal(t, "192.168.1.100:6101", New("192.168.1.100:6101").Addr())
+	assert.Equal(t, "[fe80::1]:9100", New("fe80::1").Addr())
+}
+
+func TestPrinter_Print(t *testing.T) {
+	f, err := NewFakePrinter()
+	assert.Nil(t, err)
+	p := New(f.Addr(), WithBatchSize(2))
+
+	report, err := p.Print(context.Background(),
+		Job{TrackingNumber: "1", Data: label("1")},
+		Job{TrackingNumber: "2", Data: label("2"), Copies: 2},
+		Job{TrackingNumber: "3", Data: []byte("%PDF-1.4")},
+		Job{TrackingNumber: "4", Data: label("4")},
+	)
+	assert.Nil(t, err)
+	labels, err := f.WaitLabels(waitCtx(t), 4)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"1", "2", "4"}, report.Succeeded())
+	assert.Equal(t, []string{"3"}, report.Failed())
+	assert.True(t, errors.Is(report.Results["3"].Err, ErrInvalidZPL))
+	assert.Equal(t, 2, report.Results["2"].Copies)
+	assert.Equal(t, 2, f.Connections())
+	assert.Nil(t, f.Close())
+	assert.Len(t, labels, 4)
+	assert.Contains(t, labels[0], "^FD1^FS")
+	assert.Contains(t, labels[2], "^FD2^FS")
+	assert.Contains(t, labels[3], "^FD4^FS")
+
+	_, err = p.Print(context.Background())
+	assert.True(t, errors.Is(err, ErrNoJobs))
+	_, err = p.Print(context.Background(), Job{TrackingNumber: "1", Data: label("1")}, Job{TrackingNumber: "1", Data: label("1")})
+	assert.True(t, errors.Is(err, ErrDuplicateTrackingNumber))
+}
+
+func TestPrinter_PrintRetry(t *testing.T) {
+	f, err := NewFakePrinter()
+	assert.Nil(t, err)
+
+	// 前两次连接失败
+	var dials atomic.Int32
+	dialer := func(ctx context.Context, network, address string) (net.Conn, error) {
+		if dials.Add(1) <= 2 {
+			return nil, errors.New("connection refused")
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, address)
+	}
+	p := New(f.Addr(), WithDialer(dialer), WithRetry(2, 10*time.Millisecond))
+	report, err := p.Print(context.Background(), Job{TrackingNumber: "1", Data: label("1")})
+	assert.Nil(t, err)
+	assert.Nil(t, report.Results["1"].Err)
+	assert.Equal(t, 3, report.Results["1"].Attempts)
+
+	// 重试次数用完
+	dials.Store(0)
+	p = New(f.Addr(), WithDialer(dialer), WithRetry(1, 10*time.Millisecond))
+	report, err = p.Print(context.Background(), Job{TrackingNumber: "2", Data: label("2")})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2"}, report.Failed())
+	assert.Equal(t, 2, report.Results["2"].Attempts)
+	_, err = f.WaitLabels(waitCtx(t), 1)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	assert.Len(t, f.Labels(), 1)
+}
+
+// failConn 在第 n 次写入时失败
+type failConn struct {
+	net.Conn
+	writes *atomic.Int32
+	n      int32
+}
+
+func (c failConn) Write(b []byte) (int, error) {
+	if c.writes.Add(1) == c.n {
+		return 0, errors.New("broken pipe")
+	}
+	return c.Conn.Write(b)
+}
+
+func TestPrinter_PrintResume(t *testing.T) {
+	f, err := NewFakePrinter()
+	assert.Nil(t, err)
+
+	// 第一个连接发送第 2 份时失败，重新连接后只发送剩余的 2 份
+	var writes atomic.Int32
+	var dials atomic.Int32
+	dialer := func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(ctx, network, address)
+		if err != nil || dials.Add(1) > 1 {
+			return conn, err
+		}
+		return failConn{Conn: conn, writes: &writes, n: 2}, nil
+	}
+	p := New(f.Addr(), WithDialer(dialer), WithRetry(1, 10*time.Millisecond))
+	report, err := p.Print(context.Background(), Job{TrackingNumber: "1", Data: label("1"), Copies: 3})
+	assert.Nil(t, err)
+	assert.Nil(t, report.Results["1"].Err)
+	assert.Equal(t, 2, report.Results["1"].Attempts)
+	assert.Equal(t, 3, report.Results["1"].Sent)
+	labels, err := f.WaitLabels(waitCtx(t), 3)
+	assert.Nil(t, err)
+	assert.Len(t, labels, 3)
+
+	// 重试次数用完时记录已发送的份数
+	writes.Store(0)
+	dials.Store(0)
+	p = New(f.Addr(), WithDialer(dialer), WithRetry(0, 0))
+	report, err = p.Print(context.Background(), Job{TrackingNumber: "2", Data: label("2"), Copies: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2"}, report.Failed())
+	assert.Equal(t, 1, report.Results["2"].Sent)
+	_, err = f.WaitLabels(waitCtx(t), 4)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	assert.Len(t, f.Labels(), 4)
+}
+
+func TestPrinter_PrintCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	dialer := func(ctx context.Context, network, address string) (net.Conn, error) {
+		cancel()
+		return nil, errors.New("connection refused")
+	}
+	p := New("127.0.0.1", WithDialer(dialer), WithRetry(3, time.Second))
+	report, err := p.Print(ctx, Job{TrackingNumber: "1", Data: label("1")}, Job{TrackingNumber: "2", Data: label("2")})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, []string{"1", "2"}, report.Failed())
+}
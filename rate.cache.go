@@ -0,0 +1,259 @@
+package mazon
+
+import (
+	"cmp"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+// DefaultRateCacheTTL 运费试算结果默认的缓存时间
+const DefaultRateCacheTTL = 5 * time.Minute
+
+// RateCache 运费试算结果缓存接口
+//
+// Get 在缓存不存在或已过期时返回 false 和 nil 错误，
+// 实现方需要保证并发安全，可以基于 Redis 等共享缓存实现以便多个实例共用试算结果。
+type RateCache interface {
+	Get(ctx context.Context, key string) (entity.RateCalcResult, bool, error)                          // 读取试算结果
+	Set(ctx context.Context, key string, result entity.RateCalcResult, expiration time.Duration) error // 写入试算结果，并在 expiration 后过期
+}
+
+var _ RateCache = (*MemoryRateCache)(nil)
+
+type memoryRate struct {
+	key       string
+	result    entity.RateCalcResult
+	expiredAt time.Time
+}
+
+// MemoryRateCache 基于内存的试算结果缓存，超过容量时淘汰最久未使用的结果
+type MemoryRateCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List // 最近使用的在前
+}
+
+// NewMemoryRateCache 创建内存缓存，size 为最多缓存的试算结果数量（小于等于 0 时为 1000）
+func NewMemoryRateCache(size int) *MemoryRateCache {
+	if size <= 0 {
+		size = 1000
+	}
+	return &MemoryRateCache{size: size, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (c *MemoryRateCache) Get(_ context.Context, key string) (entity.RateCalcResult, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return entity.RateCalcResult{}, false, nil
+	}
+	rate := e.Value.(*memoryRate)
+	if !time.Now().Before(rate.expiredAt) {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+		return entity.RateCalcResult{}, false, nil
+	}
+	c.lru.MoveToFront(e)
+	return rate.result, true, nil
+}
+
+func (c *MemoryRateCache) Set(_ context.Context, key string, result entity.RateCalcResult, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rate := &memoryRate{key: key, result: result, expiredAt: time.Now().Add(expiration)}
+	if e, ok := c.entries[key]; ok {
+		e.Value = rate
+		c.lru.MoveToFront(e)
+		return nil
+	}
+	c.entries[key] = c.lru.PushFront(rate)
+	for c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*memoryRate).key)
+	}
+	return nil
+}
+
+// Len 返回缓存的试算结果数量（包括已过期但是还没有被清理的结果）
+func (c *MemoryRateCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// RateCacheKey 返回运费试算请求的缓存键（规范化请求的 SHA-256）
+//
+// 不包括订单参考号和备注，文本忽略多余的空格和大小写，电话和邮编只保留数字，尺寸和重量保留两位小数，包裹不区分顺序，
+// 即参考号、备注或者包裹顺序不同的请求使用同一个缓存。
+func RateCacheKey(req RateCalcRequest) string {
+	text := func(s string) string {
+		return strings.ToUpper(strings.Join(strings.Fields(s), " "))
+	}
+	digits := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r < '0' || r > '9' {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	round := func(v float64) float64 {
+		return math.Round(v*100) / 100
+	}
+
+	boxes := make([]RateCalcOrderBox, len(req.BoxList))
+	for i, box := range req.BoxList {
+		boxes[i] = RateCalcOrderBox{
+			Length:       round(box.Length),
+			Width:        round(box.Width),
+			Height:       round(box.Height),
+			ActualWeight: round(box.ActualWeight),
+		}
+	}
+	slices.SortFunc(boxes, func(a, b RateCalcOrderBox) int {
+		return cmp.Or(
+			cmp.Compare(a.Length, b.Length),
+			cmp.Compare(a.Width, b.Width),
+			cmp.Compare(a.Height, b.Height),
+			cmp.Compare(a.ActualWeight, b.ActualWeight),
+		)
+	})
+
+	key := []any{
+		text(req.SMCode),
+		text(req.OAFirstname),
+		text(req.OACompany),
+		digits(req.OATelephone),
+		text(req.OACountry),
+		text(req.OAState),
+		text(req.OACity),
+		digits(req.OAPostcode),
+		text(req.OAStreetAddress1),
+		text(req.OAStreetAddress2),
+		req.IsMoreBox,
+		text(req.SignatureService),
+		req.PickUp,
+		req.WeightUnitType,
+		text(req.ShipperCode),
+		boxes,
+	}
+	if a := req.ShipperAddress; a != nil {
+		key = append(key,
+			text(a.ShipperName),
+			text(a.ShipperCompany),
+			digits(a.ShipperTelPhone),
+			text(a.ShipperCountry),
+			text(a.ShipperStateProvince),
+			text(a.ShipperCity),
+			digits(a.ShipperPostalCode),
+			text(a.ShipperAddress1),
+			text(a.ShipperAddress2),
+		)
+	}
+	b, _ := json.Marshal(key)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// noRateCacheKey 请求 context 中记录不读取缓存的键
+type noRateCacheKey struct{}
+
+// WithoutRateCache 返回不读取缓存的 context，试算结果仍然会写入缓存（用于强制刷新价格，比如下单前的最终报价）
+func WithoutRateCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRateCacheKey{}, true)
+}
+
+// rateCall 正在进行中的试算，相同请求的等待者共享试算结果
+type rateCall struct {
+	done   chan struct{}
+	result entity.RateCalcResult
+	err    error
+}
+
+// rateCache 客户端的试算结果缓存
+type rateCache struct {
+	store  RateCache
+	ttl    time.Duration
+	prefix string // 缓存键前缀（区分不同的账户）
+	mu     sync.Mutex
+	calls  map[string]*rateCall
+}
+
+// do 返回缓存的试算结果，缓存不存在时调用 calc 试算并写入缓存（试算失败时不缓存）
+//
+// 同一时间相同的请求只会有一个在试算，其他的调用方等待并共享该试算结果，
+// 试算在后台进行，调用方（包括发起试算的调用方）的 ctx 结束时直接返回 ctx 的错误，试算完成后仍然会写入缓存。
+func (c *rateCache) do(ctx context.Context, logger *slog.Logger, req RateCalcRequest, calc func(ctx context.Context) (entity.RateCalcResult, error)) (entity.RateCalcResult, error) {
+	key := c.prefix + RateCacheKey(req)
+	if bypass, _ := ctx.Value(noRateCacheKey{}).(bool); !bypass {
+		result, ok, err := c.store.Get(ctx, key)
+		if err != nil {
+			logger.ErrorContext(ctx, "Read rate from cache", "error", err)
+		} else if ok {
+			return result, nil
+		}
+	}
+
+	c.mu.Lock()
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.result, call.err
+		case <-ctx.Done():
+			return entity.RateCalcResult{}, ctx.Err()
+		}
+	}
+	call := &rateCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	// 试算结果由所有等待者共享，不能因为发起者的请求被取消而中断，发起者与其他等待者一样只等待到自己的 ctx 结束
+	go func() {
+		ctx := context.WithoutCancel(ctx)
+		call.result, call.err = calc(ctx)
+		if call.err == nil {
+			if err := c.store.Set(ctx, key, call.result, c.ttl); err != nil {
+				logger.ErrorContext(ctx, "Write rate to cache", "error", err)
+			}
+		}
+
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		return entity.RateCalcResult{}, ctx.Err()
+	}
+}
+
+func newRateCache(store RateCache, ttl time.Duration, appKey string) *rateCache {
+	if ttl <= 0 {
+		ttl = DefaultRateCacheTTL
+	}
+	return &rateCache{
+		store:  store,
+		ttl:    ttl,
+		prefix: fmt.Sprintf("mazon.rate.%s.", appKey),
+		calls:  make(map[string]*rateCall),
+	}
+}
@@ -0,0 +1,162 @@
+package mazon
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func cacheRateRequest() RateCalcRequest {
+	return RateCalcRequest{
+		ReferenceNO:      "TEST-RATE-CACHE",
+		SMCode:           "USPS GA13",
+		OAFirstname:      "ZEB2",
+		OATelephone:      "0731-12345678",
+		OACountry:        "US",
+		OAState:          "CA",
+		OACity:           "Ontario",
+		OAPostcode:       "91761",
+		OAStreetAddress1: "2078 E Francis Street",
+		BoxList: []RateCalcOrderBox{
+			{Height: 1, Length: 1, Width: 1, ActualWeight: 1},
+			{Height: 2, Length: 2, Width: 2, ActualWeight: 2},
+		},
+		IsMoreBox:   1,
+		ShipperCode: mazontest.ShipperCode,
+	}
+}
+
+func TestRateCacheKey(t *testing.T) {
+	req := cacheRateRequest()
+	key := RateCacheKey(req)
+	assert.Len(t, key, 64)
+
+	same := cacheRateRequest()
+	same.ReferenceNO = "ANOTHER"
+	same.Remark = "备注"
+	same.SMCode = " usps  ga13 "
+	same.OAPostcode = "91761 "
+	same.BoxList[0], same.BoxList[1] = same.BoxList[1], same.BoxList[0]
+	same.BoxList[1].ActualWeight = 1.001
+	assert.Equal(t, key, RateCacheKey(same))
+	assert.Equal(t, req.BoxList[0].ActualWeight, 1.0, "不修改请求")
+
+	for name, fn := range map[string]func(r *RateCalcRequest){
+		"sm_code":           func(r *RateCalcRequest) { r.SMCode = "UPS GROUND" },
+		"postcode":          func(r *RateCalcRequest) { r.OAPostcode = "91762" },
+		"signature":         func(r *RateCalcRequest) { r.SignatureService = "SSF" },
+		"weight_unit_type":  func(r *RateCalcRequest) { r.WeightUnitType = entity.WeightUnitImperial },
+		"box":               func(r *RateCalcRequest) { r.BoxList[0].ActualWeight = 1.5 },
+		"shipper_code":      func(r *RateCalcRequest) { r.ShipperCode = "S0005" },
+		"shipper_address":   func(r *RateCalcRequest) { r.ShipperAddress = &entity.ShipperAddress{ShipperPostalCode: "10001"} },
+		"additional_boxes":  func(r *RateCalcRequest) { r.BoxList = append(r.BoxList, r.BoxList[0]) },
+		"street_address_1":  func(r *RateCalcRequest) { r.OAStreetAddress1 = "2080 E Francis Street" },
+		"recipient_company": func(r *RateCalcRequest) { r.OACompany = "SILBER BLITZ" },
+	} {
+		r := cacheRateRequest()
+		fn(&r)
+		assert.NotEqual(t, key, RateCacheKey(r), name)
+	}
+}
+
+func TestMemoryRateCache(t *testing.T) {
+	c := NewMemoryRateCache(2)
+	result := entity.RateCalcResult{SmCode: "USPS GA13", TotalCharge: entity.MustParseDecimal("5.7")}
+	_, ok, err := c.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.Nil(t, c.Set(ctx, "a", result, time.Hour))
+	got, ok, err := c.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, result, got)
+
+	// 超过容量时淘汰最久未使用的结果
+	assert.Nil(t, c.Set(ctx, "b", result, time.Hour))
+	_, _, _ = c.Get(ctx, "a")
+	assert.Nil(t, c.Set(ctx, "c", result, time.Hour))
+	assert.Equal(t, 2, c.Len())
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "a")
+	assert.True(t, ok)
+
+	// 过期
+	assert.Nil(t, c.Set(ctx, "a", result, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, ok, _ = c.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestWithRateCache(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	c := NewClient(ctx, s.Config(), WithTokenStore(NewMemoryTokenStore()), WithRateCache(NewMemoryRateCache(100), time.Minute))
+
+	req := cacheRateRequest()
+	res, err := c.Services.Rate.Calc(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, "8.10", res.TotalCharge.String())
+	assert.Equal(t, 1, s.Requests("/rates"))
+
+	// 参考号和备注不同的相同请求
+	req.ReferenceNO = "TEST-RATE-CACHE-2"
+	req.Remark = "备注"
+	cached, err := c.Services.Rate.Calc(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, res, cached)
+	assert.Equal(t, 1, s.Requests("/rates"))
+
+	// 强制刷新
+	_, err = c.Services.Rate.Calc(WithoutRateCache(ctx), req)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, s.Requests("/rates"))
+
+	// 试算失败的结果不缓存
+	req.SMCode = "DHL"
+	for range 2 {
+		_, err = c.Services.Rate.Calc(ctx, req)
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, 4, s.Requests("/rates"))
+
+	// 同时进行的相同请求只调用一次试算接口
+	s.Inject(mazontest.Fault{Path: "/rates", Latency: 100 * time.Millisecond})
+	req.SMCode = "UPS GROUND"
+	var wg sync.WaitGroup
+	results := make([]entity.RateCalcResult, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.Services.Rate.Calc(ctx, req)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 5, s.Requests("/rates"))
+	for _, result := range results {
+		assert.Equal(t, "UPS GROUND", result.SmCode)
+		assert.Equal(t, "10.70", result.TotalCharge.String())
+	}
+
+	// 发起试算的调用方不会等待超过自己的截止时间，试算完成后仍然写入缓存
+	req.SMCode = "FEDEX HOME"
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.Services.Rate.Calc(timeoutCtx, req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 90*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		res, err := c.Services.Rate.Calc(ctx, req)
+		return err == nil && res.SmCode == "FEDEX HOME"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 6, s.Requests("/rates"))
+}
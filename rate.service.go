@@ -2,6 +2,11 @@ package mazon
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/hiscaler/mazon-go/entity"
@@ -11,10 +16,10 @@ import (
 type rateService service
 
 type RateCalcOrderBox struct {
-	Length       float64 `json:"box_length"`        // 长（支持两位小数）
-	Width        float64 `json:"box_width"`         // 宽（支持两位小数）
-	Height       float64 `json:"box_height"`        // 高（支持两位小数）
-	ActualWeight float64 `json:"box_actual_weight"` // 箱子重量（单位 kg，支持两位小数）
+	Length       float64 `json:"box_length"`        // 长（单位由 WeightUnitType 决定，公制为 cm，英制为 inch，支持两位小数）
+	Width        float64 `json:"box_width"`         // 宽（单位同长）
+	Height       float64 `json:"box_height"`        // 高（单位同长）
+	ActualWeight float64 `json:"box_actual_weight"` // 箱子重量（单位由 WeightUnitType 决定，公制为 kg，英制为 lbs，支持两位小数）
 }
 
 func (m RateCalcOrderBox) Validate() error {
@@ -65,33 +70,18 @@ type RateCalcRequest struct {
 }
 
 func (m RateCalcRequest) Validate() error {
-	return validation.ValidateStruct(&m,
+	return m.validate(true)
+}
+
+// validate 验证试算参数，运费比价时各物流产品分别设置 SMCode，不需要验证 SMCode
+func (m RateCalcRequest) validate(requireSMCode bool) error {
+	rules := []*validation.FieldRules{
 		validation.Field(&m.ReferenceNO,
 			validation.Required.Error("订单参考号不能为空"),
 			validation.Length(1, 35).Error("订单参考号不能超过 {{.max}} 个字符"),
 		),
-		validation.Field(&m.SMCode, validation.Required.Error("物流产品代码不能为空")),
+		validation.Field(&m.SMCode, validation.When(requireSMCode, validation.Required.Error("物流产品代码不能为空"))),
 		validation.Field(&m.Remark, validation.When(m.Remark != "", validation.Length(1, 35).Error("备注不能超过 {{.max}} 个字符"))),
-		validation.Field(&m.OAFirstname,
-			validation.Required.Error("收件人不能为空"),
-			validation.Length(3, 35).Error("收件人长度必须在 {{.min}} ~ {{.max}} 个字符"),
-		),
-		validation.Field(&m.OACompany, validation.When(m.OACompany != "", validation.Length(1, 35).Error("收件人公司不能超过 {{.max}} 个字符"))),
-		validation.Field(&m.OAStreetAddress1,
-			validation.Required.Error("收件人地址1不能为空"),
-			validation.Length(1, 35).Error("收件人地址1长度不能超过 {{.max}} 个字符"),
-		),
-		validation.Field(&m.OAStreetAddress2,
-			validation.When(m.OAStreetAddress2 != "", validation.Length(1, 35).Error("收件人地址2长度不能超过 {{.max}} 个字符")),
-		),
-		validation.Field(&m.OAPostcode, validation.Required.Error("收件人邮编不能为空")),
-		validation.Field(&m.OAState, validation.Required.Error("收件人州不能为空")),
-		validation.Field(&m.OACity, validation.Required.Error("收件人城市不能为空")),
-		validation.Field(&m.OACountry, validation.Required.Error("收件人国家（国家二字码）不能为空")),
-		validation.Field(&m.OATelephone,
-			validation.Required.Error("收件人电话不能为空"),
-			validation.Length(10, 15).Error("收件人电话长度必须在 {{.min}} ~ {{.max}} 个字符"),
-		),
 		validation.Field(&m.SignatureService,
 			validation.When(m.SignatureService != "", validation.In("ASS", "SSF").Error("签名服务参数错误")),
 		),
@@ -102,17 +92,39 @@ func (m RateCalcRequest) Validate() error {
 		),
 		validation.Field(&m.ShipperAddress, validation.When(m.ShipperCode == "", validation.Required.Error("发件人信息和编码必须填写一个"))),
 		validation.Field(&m.ShipperCode, validation.When(m.ShipperAddress == nil, validation.Required.Error("发件人信息和编码必须填写一个"))),
-	)
+	}
+	rules = append(rules, recipientRules(&m.OAFirstname, &m.OACompany, &m.OATelephone, &m.OACountry, &m.OAState, &m.OACity, &m.OAPostcode, &m.OAStreetAddress1, &m.OAStreetAddress2)...)
+	return validation.ValidateStruct(&m, rules...)
 }
 
-// Calc 提交订单预报参数进行费用试算
+// Calc 提交订单预报参数进行费用试算，设置了 WithRateCache 时优先返回缓存的试算结果
 // https://www.mazonlabel.com/docs/orderapi/%E8%B4%B9%E7%94%A8%E8%AF%95%E7%AE%97.html
 func (s rateService) Calc(ctx context.Context, req RateCalcRequest) (calcResult entity.RateCalcResult, err error) {
+	if s.normalizer != nil {
+		if err = req.NormalizeAddresses(s.normalizer); err != nil {
+			err = invalidInput(localeOf(ctx, s.config), err)
+			return
+		}
+	}
 	if err = req.Validate(); err != nil {
-		err = invalidInput(err)
+		err = invalidInput(localeOf(ctx, s.config), err)
 		return
 	}
+	return s.quote(ctx, req)
+}
 
+// quote 试算已经规范化和验证过的请求，设置了 WithRateCache 时优先返回缓存的试算结果
+func (s rateService) quote(ctx context.Context, req RateCalcRequest) (entity.RateCalcResult, error) {
+	if s.rateCache != nil {
+		return s.rateCache.do(ctx, s.logger, req, func(ctx context.Context) (entity.RateCalcResult, error) {
+			return s.calc(ctx, req)
+		})
+	}
+	return s.calc(ctx, req)
+}
+
+// calc 调用试算接口
+func (s rateService) calc(ctx context.Context, req RateCalcRequest) (calcResult entity.RateCalcResult, err error) {
 	res := struct {
 		NormalResponse
 		Result entity.RateCalcResult `json:"result"`
@@ -127,3 +139,161 @@ func (s rateService) Calc(ctx context.Context, req RateCalcRequest) (calcResult
 	}
 	return res.Result, nil
 }
+
+// ErrNoRateQuote 没有可用的报价（没有可以比价的物流产品或者所有物流产品试算失败）
+var ErrNoRateQuote = errors.New("没有可用的物流产品报价")
+
+// RateShopPolicy 运费比价的选择策略，默认选择总金额最低的物流产品
+type RateShopPolicy struct {
+	Signature string   // 需要的签名服务（ASS 为成人签名，SSF 为普通签名），设置后所有物流产品都使用该签名服务试算，选择总金额最低的物流产品
+	Exclude   []string // 不参与比价的物流产品（不区分大小写）
+}
+
+type RateShopRequest struct {
+	RateCalcRequest                // 试算参数（不需要填写 SMCode）
+	SMCodes         []string       // 参与比价的物流产品，为空时使用账户开通的所有物流产品（用户信息中的 sm_code）
+	Policy          RateShopPolicy // 选择策略
+	Concurrency     int            // 同时试算的数量，默认为 4
+}
+
+// RateShopResult 运费比价结果
+type RateShopResult struct {
+	Quotes   []entity.RateCalcResult // 试算成功的报价（按总金额从低到高排序，币种不一致时先按币种分组）
+	Errors   map[string]error        // 试算失败的物流产品和错误
+	Selected entity.RateCalcResult   // 根据选择策略选中的报价
+}
+
+// smCodes 返回参与比价的物流产品（去重并排除策略中指定的物流产品，都不区分大小写，保留第一次出现时的写法）
+func (s rateService) smCodes(ctx context.Context, req RateShopRequest) ([]string, error) {
+	codes := req.SMCodes
+	if len(codes) == 0 {
+		info, err := userService(s).Information(ctx)
+		if err != nil {
+			return nil, err
+		}
+		codes = info.SmCode
+	}
+
+	seen := make(map[string]bool, len(codes)+len(req.Policy.Exclude))
+	for _, code := range req.Policy.Exclude {
+		seen[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+	smCodes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		key := strings.ToUpper(code)
+		if code == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		smCodes = append(smCodes, code)
+	}
+	return smCodes, nil
+}
+
+// Shop 使用多个物流产品同时试算运费，返回按总金额排序的报价，并根据选择策略选择一个物流产品
+//
+// 部分物流产品试算失败（比如不支持收件地址或者签名服务）时不影响其他物流产品，失败原因保存在 Errors 中，
+// 所有物流产品都试算失败时返回 ErrNoRateQuote（包含各物流产品的错误）。
+// 报价的币种不一致时无法比较金额，返回按币种分组排序的报价（Selected 为空）和 entity.ErrCurrencyMismatch。
+// ctx 取消时返回已经试算成功的报价（Selected 为空）和 ctx 的错误。
+func (s rateService) Shop(ctx context.Context, req RateShopRequest) (shopResult RateShopResult, err error) {
+	if req.Policy.Signature != "" {
+		req.SignatureService = req.Policy.Signature
+	}
+	if s.normalizer != nil {
+		if err = req.NormalizeAddresses(s.normalizer); err != nil {
+			err = invalidInput(localeOf(ctx, s.config), err)
+			return
+		}
+	}
+	// 试算参数只需要规范化和验证一次，各物流产品直接试算
+	if err = req.RateCalcRequest.validate(false); err != nil {
+		err = invalidInput(localeOf(ctx, s.config), err)
+		return
+	}
+
+	smCodes, err := s.smCodes(ctx, req)
+	if err != nil {
+		return
+	}
+	if len(smCodes) == 0 {
+		err = localize(localeOf(ctx, s.config), ErrNoRateQuote)
+		return
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	results := make([]entity.RateCalcResult, len(smCodes))
+	errs := make([]error, len(smCodes))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, smCode := range smCodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			calcReq := req.RateCalcRequest
+			calcReq.SMCode = smCode
+			results[i], errs[i] = s.quote(ctx, calcReq)
+			if errs[i] == nil && results[i].SmCode == "" {
+				results[i].SmCode = smCode
+			}
+		}()
+	}
+	wg.Wait()
+
+	shopResult.Quotes = make([]entity.RateCalcResult, 0, len(smCodes))
+	shopResult.Errors = make(map[string]error)
+	for i, smCode := range smCodes {
+		if errs[i] != nil {
+			shopResult.Errors[smCode] = errs[i]
+			continue
+		}
+		shopResult.Quotes = append(shopResult.Quotes, results[i])
+	}
+	// 不同币种的金额不能比较，按币种分组后再按总金额排序
+	var currencies []string
+	for _, quote := range shopResult.Quotes {
+		if currency := strings.ToUpper(quote.CurrencyCode); currency != "" && !slices.Contains(currencies, currency) {
+			currencies = append(currencies, currency)
+		}
+	}
+	slices.SortStableFunc(shopResult.Quotes, func(a, b entity.RateCalcResult) int {
+		if len(currencies) > 1 {
+			if c := strings.Compare(strings.ToUpper(a.CurrencyCode), strings.ToUpper(b.CurrencyCode)); c != 0 {
+				return c
+			}
+		}
+		if c := a.TotalCharge.Cmp(b.TotalCharge); c != 0 {
+			return c
+		}
+		return strings.Compare(a.SmCode, b.SmCode)
+	})
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if len(shopResult.Quotes) == 0 {
+		causes := make([]error, 0, len(smCodes))
+		for _, smCode := range smCodes {
+			causes = append(causes, fmt.Errorf("%s: %w", smCode, shopResult.Errors[smCode]))
+		}
+		err = fmt.Errorf("%w: %w", localize(localeOf(ctx, s.config), ErrNoRateQuote), errors.Join(causes...))
+		return
+	}
+	if len(currencies) > 1 {
+		slices.Sort(currencies)
+		err = fmt.Errorf("%w: %s", localize(localeOf(ctx, s.config), entity.ErrCurrencyMismatch), strings.Join(currencies, ", "))
+		return
+	}
+	shopResult.Selected = shopResult.Quotes[0]
+	return shopResult, nil
+}
@@ -1,10 +1,15 @@
 package mazon
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,3 +45,123 @@ func Test_rateService_Calc(t *testing.T) {
 	fmt.Println(string(b))
 	assert.Equal(t, res.AddressType, 2)
 }
+
+func Test_rateService_Shop(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	c := NewClient(ctx, s.Config(), WithTokenStore(NewMemoryTokenStore()))
+	req := RateShopRequest{
+		RateCalcRequest: RateCalcRequest{
+			ReferenceNO:      "TEST-RATE-SHOP",
+			OAFirstname:      "ZEB2",
+			OATelephone:      "0731-12345678",
+			OACountry:        "US",
+			OAState:          "CA",
+			OACity:           "Ontario",
+			OAPostcode:       "91761",
+			OAStreetAddress1: "2078 E Francis Street",
+			BoxList:          []RateCalcOrderBox{{Height: 1, Length: 1, Width: 1, ActualWeight: 20}},
+			IsMoreBox:        1,
+			ShipperCode:      mazontest.ShipperCode,
+		},
+	}
+
+	// 所有开通的物流产品
+	res, err := c.Services.Rate.Shop(ctx, req)
+	assert.Nil(t, err)
+	smCodes := make([]string, len(res.Quotes))
+	for i, quote := range res.Quotes {
+		smCodes[i] = quote.SmCode
+	}
+	assert.Equal(t, []string{"UPS GROUND", "FEDEX HOME", "USPS GA13"}, smCodes)
+	assert.Equal(t, "UPS GROUND", res.Selected.SmCode)
+	assert.Equal(t, "26.00", res.Selected.TotalCharge.String())
+	assert.Empty(t, res.Errors)
+
+	// 签名服务、排除物流产品
+	req.Policy = RateShopPolicy{Signature: "SSF", Exclude: []string{"ups ground"}}
+	res, err = c.Services.Rate.Shop(ctx, req)
+	assert.Nil(t, err)
+	assert.Len(t, res.Quotes, 2)
+	assert.Equal(t, "USPS GA13", res.Selected.SmCode)
+	assert.Equal(t, "31.50", res.Selected.TotalCharge.String())
+
+	// 部分物流产品试算失败
+	req.Policy = RateShopPolicy{}
+	req.SMCodes = []string{"DHL", "FEDEX HOME", "FEDEX HOME"}
+	req.Concurrency = 1
+	requests := s.Requests("/rates")
+	res, err = c.Services.Rate.Shop(ctx, req)
+	assert.Nil(t, err)
+	if assert.Len(t, res.Quotes, 1) {
+		assert.Equal(t, "FEDEX HOME", res.Selected.SmCode)
+	}
+	assert.ErrorIs(t, res.Errors["DHL"], ErrBadRequest)
+	assert.Equal(t, 2, s.Requests("/rates")-requests)
+
+	// 所有物流产品试算失败
+	req.SMCodes = []string{"DHL"}
+	_, err = c.Services.Rate.Shop(ctx, req)
+	assert.ErrorIs(t, err, ErrNoRateQuote)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	req.Policy.Exclude = []string{"DHL"}
+	_, err = c.Services.Rate.Shop(ctx, req)
+	assert.ErrorIs(t, err, ErrNoRateQuote)
+
+	// 去重不区分大小写
+	req.Policy = RateShopPolicy{}
+	req.SMCodes = []string{"FEDEX HOME", "fedex home", " Fedex Home "}
+	requests = s.Requests("/rates")
+	res, err = c.Services.Rate.Shop(ctx, req)
+	assert.Nil(t, err)
+	assert.Len(t, res.Quotes, 1)
+	assert.Equal(t, 1, s.Requests("/rates")-requests)
+
+	// 币种不一致时不选择报价
+	s.Products["CA POST"] = mazontest.Product{Base: 1, PerWeight: 0.1, Currency: "CAD"}
+	req.SMCodes = []string{"CA POST", "FEDEX HOME", "USPS GA13"}
+	res, err = c.Services.Rate.Shop(ctx, req)
+	assert.ErrorIs(t, err, entity.ErrCurrencyMismatch)
+	assert.ErrorContains(t, err, "CAD, USD")
+	smCodes = make([]string, len(res.Quotes))
+	for i, quote := range res.Quotes {
+		smCodes[i] = quote.SmCode
+	}
+	assert.Equal(t, []string{"CA POST", "FEDEX HOME", "USPS GA13"}, smCodes)
+	assert.Empty(t, res.Selected.SmCode)
+
+	// 参数错误
+	req.BoxList = nil
+	_, err = c.Services.Rate.Shop(ctx, req)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func Test_rateService_ShopOnce(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	var normalized atomic.Int32
+	c := NewClient(ctx, s.Config(), WithTokenStore(NewMemoryTokenStore()), WithAddressNormalizer(func(a entity.Address) (entity.Address, error) {
+		normalized.Add(1)
+		return a, nil
+	}))
+	req := RateShopRequest{RateCalcRequest: cacheRateRequest(), SMCodes: []string{"USPS GA13", "UPS GROUND", "FEDEX HOME"}}
+
+	// 地址只规范化一次
+	res, err := c.Services.Rate.Shop(ctx, req)
+	assert.Nil(t, err)
+	assert.Len(t, res.Quotes, 3)
+	assert.Equal(t, int32(1), normalized.Load())
+
+	// ctx 取消时返回已经试算成功的报价
+	s.Inject(mazontest.Fault{Path: "/rates", Latency: 100 * time.Millisecond})
+	req.Concurrency = 1
+	timeoutCtx, cancel := context.WithTimeout(ctx, 150*time.Millisecond)
+	defer cancel()
+	res, err = c.Services.Rate.Shop(timeoutCtx, req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, res.Quotes, 1)
+	assert.Len(t, res.Errors, 2)
+	assert.Empty(t, res.Selected.SmCode)
+}
@@ -0,0 +1,317 @@
+// Package ratecard 根据合同价格表（分区表、重量价格表和附加费）在本地计算运费
+//
+// 运费试算接口速度较慢并且有频率限制，店铺购物车等需要频繁报价的场景可以使用本地价格表计算与 RateCalcResult 格式相同的报价，
+// 并且可以通过 Reconcile 与美正的试算结果进行对比，检查价格表是否需要更新。
+//
+//	rc, err := ratecard.LoadFile("rates.json")
+//	quote, err := rc.Quote(req)
+//	report := rc.Reconcile(ctx, client, entity.MustParseDecimal("0.01"), reqs...)
+package ratecard
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hiscaler/mazon-go/dimweight"
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+var (
+	ErrNoCard            = errors.New("没有物流产品的价格表")
+	ErrNoZone            = errors.New("没有邮编对应的分区")
+	ErrOverweight        = errors.New("超过价格表的最大重量")
+	ErrExceedsLimits     = errors.New("超过物流产品的最大尺寸或者重量")
+	ErrUnsupportedFormat = errors.New("不支持的文件格式")
+)
+
+// 基础运费的费用信息
+const (
+	FreightFtCode      = "Freight"
+	FreightFeeTypeCode = "E1"
+	FreightChargeDesc  = "基础运费"
+)
+
+// Zone 分区，目的地邮编前 3 位在 From ~ To 范围内（包含）时使用该分区
+type Zone struct {
+	From string `json:"from"` // 起始邮编前缀（3 位）
+	To   string `json:"to"`   // 结束邮编前缀（3 位），为空时与 From 相同
+	Zone int    `json:"zone"` // 分区
+}
+
+func (z Zone) contains(prefix string) bool {
+	to := z.To
+	if to == "" {
+		to = z.From
+	}
+	return prefix >= z.From && prefix <= to
+}
+
+// Rate 重量价格，计费重量不超过 MaxWeight 时的价格
+type Rate struct {
+	Zone      int            `json:"zone"`       // 分区
+	MaxWeight float64        `json:"max_weight"` // 最大重量（单位为价格表的 WeightUnit）
+	Price     entity.Decimal `json:"price"`      // 价格
+}
+
+// Condition 附加费的收取条件
+type Condition string
+
+const (
+	Always             Condition = ""                    // 总是收取
+	Signature          Condition = "signature"           // 需要签名服务（ASS、SSF）
+	AdultSignature     Condition = "adult_signature"     // 需要成人签名服务（ASS）
+	PickUp             Condition = "pick_up"             // 需要提货
+	AdditionalHandling Condition = "additional_handling" // 包裹需要收取附加处理费（根据计费规则）
+	Oversize           Condition = "oversize"            // 超大包裹（根据计费规则）
+)
+
+// Surcharge 附加费
+type Surcharge struct {
+	FeeTypeCode string         `json:"fee_type_code"` // 费用编码
+	FtCode      string         `json:"ft_code"`       // 费用英文名称
+	ChargeDesc  string         `json:"charge_desc"`   // 费用描述
+	Amount      entity.Decimal `json:"amount"`        // 固定金额
+	Percent     entity.Decimal `json:"percent"`       // 基础运费的百分比（比如燃油附加费），12.5 表示 12.5%
+	PerBox      bool           `json:"per_box"`       // 是否按包裹收取（包裹条件总是按符合条件的包裹收取）
+	Condition   Condition      `json:"condition"`     // 收取条件
+}
+
+// Card 物流产品的价格表
+type Card struct {
+	SMCode     string            `json:"sm_code"`              // 物流产品代码
+	Currency   string            `json:"currency"`             // 币种，默认为 USD
+	WeightUnit entity.WeightUnit `json:"weight_unit"`          // 重量价格表的重量单位（lb、kg 等），默认为 lb
+	Rule       *dimweight.Rule   `json:"rule,omitempty"`       // 计费重量规则，为空时按实际重量计费
+	Zones      []Zone            `json:"zones,omitempty"`      // 分区表，为空时使用 RateCard 的分区表
+	Rates      []Rate            `json:"rates"`                // 重量价格表
+	Surcharges []Surcharge       `json:"surcharges,omitempty"` // 附加费
+}
+
+// RateCard 价格表
+type RateCard struct {
+	Zones []Zone  `json:"zones"` // 所有物流产品共用的分区表
+	Cards []*Card `json:"cards"` // 物流产品的价格表
+}
+
+// Card 返回物流产品的价格表（不区分大小写）
+func (rc *RateCard) Card(smCode string) (*Card, bool) {
+	smCode = strings.TrimSpace(smCode)
+	for _, card := range rc.Cards {
+		if strings.EqualFold(card.SMCode, smCode) {
+			return card, true
+		}
+	}
+	return nil, false
+}
+
+// card 返回物流产品的价格表，不存在时添加
+func (rc *RateCard) card(smCode string) *Card {
+	card, ok := rc.Card(smCode)
+	if !ok {
+		card = &Card{SMCode: strings.TrimSpace(smCode)}
+		rc.Cards = append(rc.Cards, card)
+	}
+	return card
+}
+
+// prepare 设置默认值并将重量价格表按分区、重量排序
+func (rc *RateCard) prepare() error {
+	for _, card := range rc.Cards {
+		if card.SMCode == "" {
+			return errors.New("价格表缺少物流产品代码")
+		}
+		if card.Currency == "" {
+			card.Currency = "USD"
+		}
+		if card.WeightUnit == "" {
+			card.WeightUnit = entity.Pound
+		}
+		if !card.WeightUnit.IsValid() {
+			return fmt.Errorf("%s: 无效的重量单位 %s", card.SMCode, card.WeightUnit)
+		}
+		slices.SortStableFunc(card.Rates, func(a, b Rate) int {
+			if a.Zone != b.Zone {
+				return a.Zone - b.Zone
+			}
+			switch {
+			case a.MaxWeight < b.MaxWeight:
+				return -1
+			case a.MaxWeight > b.MaxWeight:
+				return 1
+			}
+			return 0
+		})
+	}
+	return nil
+}
+
+// LoadJSON 读取 JSON 格式的价格表
+func LoadJSON(r io.Reader) (*RateCard, error) {
+	var rc RateCard
+	if err := json.NewDecoder(r).Decode(&rc); err != nil {
+		return nil, err
+	}
+	if err := rc.prepare(); err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
+
+// LoadCSV 读取 CSV 格式的价格表（第一行为表头，列的顺序不限）
+//
+//   - zones 分区表：sm_code（为空或者 * 表示所有物流产品共用）,from,to,zone
+//   - rates 重量价格表：sm_code,zone,max_weight,price,weight_unit（可选）,currency（可选）
+//   - surcharges 附加费（可以为 nil）：sm_code,fee_type_code,ft_code,charge_desc,amount,percent,per_box,condition
+func LoadCSV(zones, rates, surcharges io.Reader) (*RateCard, error) {
+	rc := &RateCard{}
+	err := readCSV(zones, func(row csvRow) error {
+		zone, err := row.int("zone")
+		if err != nil {
+			return err
+		}
+		z := Zone{From: row.get("from"), To: row.get("to"), Zone: zone}
+		if smCode := row.get("sm_code"); smCode == "" || smCode == "*" {
+			rc.Zones = append(rc.Zones, z)
+		} else {
+			card := rc.card(smCode)
+			card.Zones = append(card.Zones, z)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("分区表：%w", err)
+	}
+
+	err = readCSV(rates, func(row csvRow) error {
+		zone, err := row.int("zone")
+		if err != nil {
+			return err
+		}
+		maxWeight, err := strconv.ParseFloat(row.get("max_weight"), 64)
+		if err != nil {
+			return fmt.Errorf("max_weight: %w", err)
+		}
+		price, err := entity.ParseDecimal(row.get("price"))
+		if err != nil {
+			return fmt.Errorf("price: %w", err)
+		}
+		card := rc.card(row.get("sm_code"))
+		for name, field := range map[string]*string{"weight_unit": (*string)(&card.WeightUnit), "currency": &card.Currency} {
+			if v := row.get(name); v != "" {
+				if *field != "" && *field != v {
+					return fmt.Errorf("%s: %s 与 %s 不一致", name, v, *field)
+				}
+				*field = v
+			}
+		}
+		card.Rates = append(card.Rates, Rate{Zone: zone, MaxWeight: maxWeight, Price: price})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("重量价格表：%w", err)
+	}
+
+	if surcharges != nil {
+		err = readCSV(surcharges, func(row csvRow) error {
+			amount, err := entity.ParseDecimal(row.get("amount"))
+			if err != nil {
+				return fmt.Errorf("amount: %w", err)
+			}
+			percent, err := entity.ParseDecimal(row.get("percent"))
+			if err != nil {
+				return fmt.Errorf("percent: %w", err)
+			}
+			perBox := false
+			if v := row.get("per_box"); v != "" {
+				if perBox, err = strconv.ParseBool(v); err != nil {
+					return fmt.Errorf("per_box: %w", err)
+				}
+			}
+			card := rc.card(row.get("sm_code"))
+			card.Surcharges = append(card.Surcharges, Surcharge{
+				FeeTypeCode: row.get("fee_type_code"),
+				FtCode:      row.get("ft_code"),
+				ChargeDesc:  row.get("charge_desc"),
+				Amount:      amount,
+				Percent:     percent,
+				PerBox:      perBox,
+				Condition:   Condition(row.get("condition")),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("附加费：%w", err)
+		}
+	}
+
+	if err = rc.prepare(); err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// LoadFile 根据扩展名读取 JSON 格式的价格表
+func LoadFile(filename string) (*RateCard, error) {
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != ".json" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, ext)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadJSON(f)
+}
+
+// csvRow CSV 行（以表头名称访问）
+type csvRow struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r csvRow) get(name string) string {
+	if i, ok := r.columns[name]; ok && i < len(r.record) {
+		return strings.TrimSpace(r.record[i])
+	}
+	return ""
+}
+
+func (r csvRow) int(name string) (int, error) {
+	v, err := strconv.Atoi(r.get(name))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return v, nil
+}
+
+// readCSV 读取 CSV 文件，fn 返回的错误包含行号
+func readCSV(r io.Reader, fn func(row csvRow) error) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	for i, header := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))] = i
+	}
+	for i, record := range records[1:] {
+		if err = fn(csvRow{columns: columns, record: record}); err != nil {
+			return fmt.Errorf("第 %d 行：%w", i+2, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package ratecard
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/dimweight"
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/usaddress"
+)
+
+// Zone 返回目的地邮编对应的分区（优先使用物流产品的分区表）
+func (rc *RateCard) Zone(card *Card, postcode string) (int, error) {
+	zip, err := usaddress.ParseZIP(postcode)
+	if err != nil {
+		return 0, err
+	}
+	prefix := zip.Code[:3]
+	zones := card.Zones
+	if len(zones) == 0 {
+		zones = rc.Zones
+	}
+	for _, z := range zones {
+		if z.contains(prefix) {
+			return z.Zone, nil
+		}
+	}
+	return 0, fmt.Errorf("%w %s", ErrNoZone, postcode)
+}
+
+// price 返回分区中计费重量对应的价格
+func (c *Card) price(zone int, weight float64) (entity.Decimal, error) {
+	for _, rate := range c.Rates {
+		// 忽略单位转换的浮点误差
+		if rate.Zone == zone && weight <= rate.MaxWeight+1e-6 {
+			return rate.Price, nil
+		}
+	}
+	return entity.Decimal{}, fmt.Errorf("%w（%s 分区 %d，%.2f%s）", ErrOverweight, c.SMCode, zone, weight, c.WeightUnit)
+}
+
+// boxWeights 返回各包裹的计费重量（价格表的重量单位）和标记
+func (c *Card) boxWeights(req mazon.RateCalcRequest) ([]float64, [][]dimweight.Flag, error) {
+	weightUnitType := req.WeightUnitType
+	if weightUnitType == 0 {
+		weightUnitType = entity.WeightUnitMetric
+	}
+	boxUnit := entity.Kilogram
+	if weightUnitType == entity.WeightUnitImperial {
+		boxUnit = entity.Pound
+	}
+
+	weights := make([]float64, len(req.BoxList))
+	flags := make([][]dimweight.Flag, len(req.BoxList))
+	if c.Rule == nil {
+		for i, box := range req.BoxList {
+			weights[i] = entity.Weight{Value: box.ActualWeight, Unit: boxUnit}.In(c.WeightUnit)
+		}
+		return weights, flags, nil
+	}
+
+	res, err := dimweight.New(map[string]dimweight.Rule{c.SMCode: *c.Rule}).Calc(c.SMCode, weightUnitType, req.BoxList...)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, box := range res.Boxes {
+		if box.Has(dimweight.ExceedsLimits) {
+			return nil, nil, fmt.Errorf("%w（第 %d 个包裹）", ErrExceedsLimits, i+1)
+		}
+		weights[i] = entity.Weight{Value: box.BillableWeight, Unit: boxUnit}.In(c.WeightUnit)
+		flags[i] = box.Flags
+	}
+	return weights, flags, nil
+}
+
+// charge 计算附加费，返回 false 表示不需要收取
+func (s Surcharge) charge(req mazon.RateCalcRequest, freights []entity.Decimal, flags [][]dimweight.Flag) (entity.Decimal, bool) {
+	switch s.Condition {
+	case Signature:
+		if req.SignatureService == "" {
+			return entity.Decimal{}, false
+		}
+	case AdultSignature:
+		if req.SignatureService != "ASS" {
+			return entity.Decimal{}, false
+		}
+	case PickUp:
+		if req.PickUp != 1 {
+			return entity.Decimal{}, false
+		}
+	}
+
+	// 需要收费的包裹，包裹条件只收取有对应标记的包裹
+	perBox := s.PerBox
+	boxes := make([]int, 0, len(freights))
+	for i := range freights {
+		if s.Condition == AdditionalHandling || s.Condition == Oversize {
+			perBox = true
+			if !slices.Contains(flags[i], dimweight.Flag(s.Condition)) {
+				continue
+			}
+		}
+		boxes = append(boxes, i)
+	}
+	if len(boxes) == 0 {
+		return entity.Decimal{}, false
+	}
+
+	amount := s.Amount
+	if perBox {
+		amount = amount.Mul(int64(len(boxes)))
+	}
+	if !s.Percent.IsZero() {
+		freight := entity.Decimal{}
+		for _, i := range boxes {
+			freight = freight.Add(freights[i])
+		}
+		amount = amount.Add(freight.MulDecimal(s.Percent).MulDecimal(entity.NewDecimal(1, 2)))
+	}
+	return amount.Round(2), true
+}
+
+// Quote 根据价格表计算运费，返回与运费试算接口格式相同的结果（不包括地址类型）
+//
+// 每个包裹按分区和计费重量分别计算基础运费后汇总，然后计算满足条件的附加费。
+func (rc *RateCard) Quote(req mazon.RateCalcRequest) (entity.RateCalcResult, error) {
+	card, ok := rc.Card(req.SMCode)
+	if !ok {
+		return entity.RateCalcResult{}, fmt.Errorf("%w %s", ErrNoCard, req.SMCode)
+	}
+	zone, err := rc.Zone(card, req.OAPostcode)
+	if err != nil {
+		return entity.RateCalcResult{}, err
+	}
+	weights, flags, err := card.boxWeights(req)
+	if err != nil {
+		return entity.RateCalcResult{}, err
+	}
+
+	freights := make([]entity.Decimal, len(weights))
+	freight := entity.Decimal{}
+	for i, weight := range weights {
+		if freights[i], err = card.price(zone, weight); err != nil {
+			return entity.RateCalcResult{}, err
+		}
+		freight = freight.Add(freights[i])
+	}
+
+	res := entity.RateCalcResult{
+		SmCode:         card.SMCode,
+		CurrencyCode:   card.Currency,
+		ShippingCharge: freight,
+		TotalCharge:    freight,
+		ChargeDetail: []entity.ChargeDetail{{
+			FtCode:      FreightFtCode,
+			FeeTypeCode: FreightFeeTypeCode,
+			ChargeDesc:  FreightChargeDesc,
+			Amount:      freight,
+		}},
+	}
+	for _, surcharge := range card.Surcharges {
+		amount, ok := surcharge.charge(req, freights, flags)
+		if !ok {
+			continue
+		}
+		res.ChargeDetail = append(res.ChargeDetail, entity.ChargeDetail{
+			FtCode:      surcharge.FtCode,
+			FeeTypeCode: surcharge.FeeTypeCode,
+			ChargeDesc:  surcharge.ChargeDesc,
+			Amount:      amount,
+		})
+		res.TotalCharge = res.TotalCharge.Add(amount)
+	}
+	return res, nil
+}
@@ -0,0 +1,178 @@
+package ratecard
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
+	"github.com/stretchr/testify/assert"
+)
+
+// 与模拟服务的 USPS GA13 一致（4.5 + 1.2 × 重量），但是重量按 1kg 向上取整
+const (
+	zonesCSV = "\ufeffsm_code,from,to,zone\n" +
+		"*,900,961,8\n" +
+		"*,000,899,5\n"
+	ratesCSV = "sm_code,zone,max_weight,price,weight_unit,currency\n" +
+		"USPS GA13,8,2,6.90,kg,USD\n" +
+		"USPS GA13,8,1,5.70,kg,USD\n" +
+		"USPS GA13,8,3,8.10,,\n" +
+		"USPS GA13,5,3,7.00,,\n"
+	surchargesCSV = "sm_code,fee_type_code,ft_code,charge_desc,amount,percent,per_box,condition\n" +
+		"USPS GA13,E2,Signature,签名服务费,3,,,signature\n"
+)
+
+func rateRequest(smCode string, weight float64) mazon.RateCalcRequest {
+	return mazon.RateCalcRequest{
+		ReferenceNO:      "TEST-RATE-CARD",
+		SMCode:           smCode,
+		OAFirstname:      "ZEB2",
+		OATelephone:      "0731-12345678",
+		OACountry:        "US",
+		OAState:          "CA",
+		OACity:           "Ontario",
+		OAPostcode:       "91761",
+		OAStreetAddress1: "2078 E Francis Street",
+		BoxList:          []mazon.RateCalcOrderBox{{Length: 10, Width: 10, Height: 10, ActualWeight: weight}},
+		IsMoreBox:        1,
+		WeightUnitType:   entity.WeightUnitMetric,
+		ShipperCode:      mazontest.ShipperCode,
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	rc, err := LoadCSV(strings.NewReader(zonesCSV), strings.NewReader(ratesCSV), strings.NewReader(surchargesCSV))
+	assert.Nil(t, err)
+	assert.Len(t, rc.Zones, 2)
+	card, ok := rc.Card("usps ga13")
+	assert.True(t, ok)
+	assert.Equal(t, entity.Kilogram, card.WeightUnit)
+	assert.Equal(t, "USD", card.Currency)
+	assert.Equal(t, []float64{3, 1, 2, 3}, []float64{card.Rates[0].MaxWeight, card.Rates[1].MaxWeight, card.Rates[2].MaxWeight, card.Rates[3].MaxWeight})
+
+	// 错误包含行号
+	_, err = LoadCSV(strings.NewReader(zonesCSV), strings.NewReader(ratesCSV+"USPS GA13,8,4,abc,,\n"), nil)
+	assert.ErrorIs(t, err, entity.ErrInvalidDecimal)
+	assert.Contains(t, err.Error(), "第 6 行")
+	_, err = LoadCSV(strings.NewReader(zonesCSV), strings.NewReader(ratesCSV+"USPS GA13,8,4,9.30,lb,\n"), nil)
+	assert.ErrorContains(t, err, "weight_unit")
+}
+
+func TestRateCard_Quote(t *testing.T) {
+	rc, err := LoadCSV(strings.NewReader(zonesCSV), strings.NewReader(ratesCSV), strings.NewReader(surchargesCSV))
+	assert.Nil(t, err)
+
+	req := rateRequest("USPS GA13", 1.5)
+	res, err := rc.Quote(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "USPS GA13", res.SmCode)
+	assert.Equal(t, "USD", res.CurrencyCode)
+	assert.Equal(t, "6.90", res.TotalCharge.String())
+	assert.Len(t, res.ChargeDetail, 1)
+
+	// 签名服务、英制单位（3.3 lb ≈ 1.5 kg）
+	req.SignatureService = "SSF"
+	req.WeightUnitType = entity.WeightUnitImperial
+	req.BoxList[0].ActualWeight = 3.3
+	res, err = rc.Quote(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "6.90", res.ShippingCharge.String())
+	assert.Equal(t, "9.90", res.TotalCharge.String())
+	assert.Equal(t, entity.ChargeDetail{FtCode: "Signature", FeeTypeCode: "E2", ChargeDesc: "签名服务费", Amount: entity.MustParseDecimal("3")}, res.ChargeDetail[1])
+
+	// 其他分区
+	req = rateRequest("USPS GA13", 1)
+	req.OAPostcode = "02134"
+	res, err = rc.Quote(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "7.00", res.TotalCharge.String())
+
+	_, err = rc.Quote(rateRequest("USPS GA13", 3.5))
+	assert.ErrorIs(t, err, ErrOverweight)
+	_, err = rc.Quote(rateRequest("UPS GROUND", 1))
+	assert.ErrorIs(t, err, ErrNoCard)
+}
+
+func TestRateCard_QuoteRule(t *testing.T) {
+	rc, err := LoadJSON(strings.NewReader(`{
+	"cards": [{
+		"sm_code": "UPS GROUND",
+		"rule": {"units": 1, "dim_divisor": 139, "round_dimensions": true, "weight_increment": 1, "additional_handling": {"length": 48}, "max": {"length": 108}},
+		"zones": [{"from": "900", "to": "961", "zone": 8}],
+		"rates": [{"zone": 8, "max_weight": 50, "price": "30"}, {"zone": 8, "max_weight": 60, "price": 40}],
+		"surcharges": [
+			{"fee_type_code": "E3", "ft_code": "Fuel", "charge_desc": "燃油附加费", "percent": "10"},
+			{"fee_type_code": "E4", "ft_code": "AHS", "charge_desc": "附加处理费", "amount": "15", "condition": "additional_handling"}
+		]
+	}]
+}`))
+	assert.Nil(t, err)
+
+	// 50 × 12 × 12 inch 的体积重为 52 lb，并且需要收取附加处理费
+	req := rateRequest("UPS GROUND", 10)
+	req.WeightUnitType = entity.WeightUnitImperial
+	req.BoxList[0] = mazon.RateCalcOrderBox{Length: 50, Width: 12, Height: 12, ActualWeight: 10}
+	res, err := rc.Quote(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "40.00", res.ShippingCharge.String())
+	assert.Equal(t, "59.00", res.TotalCharge.String())
+	assert.Equal(t, []string{"E1", "E3", "E4"}, []string{res.ChargeDetail[0].FeeTypeCode, res.ChargeDetail[1].FeeTypeCode, res.ChargeDetail[2].FeeTypeCode})
+	assert.Equal(t, "4.00", res.ChargeDetail[1].Amount.String())
+
+	req.BoxList[0].Length = 120
+	_, err = rc.Quote(req)
+	assert.ErrorIs(t, err, ErrExceedsLimits)
+
+	req.OAPostcode = "00501"
+	_, err = rc.Quote(req)
+	assert.ErrorIs(t, err, ErrNoZone)
+}
+
+func TestRateCard_Reconcile(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	ctx := context.Background()
+	client := mazon.NewClient(ctx, s.Config(), mazon.WithTokenStore(mazon.NewMemoryTokenStore()))
+	rc, err := LoadCSV(strings.NewReader(zonesCSV), strings.NewReader(ratesCSV), strings.NewReader(surchargesCSV))
+	assert.Nil(t, err)
+
+	signature := rateRequest("USPS GA13", 2)
+	signature.SignatureService = "SSF"
+	report := rc.Reconcile(ctx, client, entity.MustParseDecimal("0.01"),
+		signature,
+		rateRequest("USPS GA13", 1.5),
+		rateRequest("UPS GROUND", 1),
+	)
+	assert.Len(t, report.Items, 3)
+
+	// 与模拟服务一致
+	item := report.Items[0]
+	assert.Nil(t, item.Err())
+	assert.False(t, item.Drifted)
+	assert.Equal(t, "9.90", item.Live.TotalCharge.String())
+	assert.True(t, item.Difference.IsZero())
+	assert.Empty(t, item.Charges)
+
+	// 本地按 2kg 计费，模拟服务按实际重量计费
+	item = report.Items[1]
+	assert.True(t, item.Drifted)
+	assert.Equal(t, "-0.60", item.Difference.String())
+	assert.Equal(t, []ChargeDrift{{
+		FeeTypeCode: "E1",
+		ChargeDesc:  "基础运费",
+		Local:       entity.MustParseDecimal("6.90"),
+		Live:        entity.MustParseDecimal("6.30"),
+		Difference:  entity.MustParseDecimal("-0.60"),
+	}}, item.Charges)
+	assert.Equal(t, []Drift{item}, report.Drifted())
+
+	// 没有价格表
+	item = report.Items[2]
+	assert.ErrorIs(t, item.LocalErr, ErrNoCard)
+	assert.Nil(t, item.LiveErr)
+	assert.False(t, item.Drifted)
+	assert.Len(t, report.Failed(), 1)
+}
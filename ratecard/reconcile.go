@@ -0,0 +1,132 @@
+package ratecard
+
+import (
+	"context"
+
+	"github.com/hiscaler/mazon-go"
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+// ChargeDrift 费用差异（按费用编码对比）
+type ChargeDrift struct {
+	FeeTypeCode string         // 费用编码
+	ChargeDesc  string         // 费用描述
+	Local       entity.Decimal // 本地报价金额（没有该费用时为 0）
+	Live        entity.Decimal // 美正报价金额（没有该费用时为 0）
+	Difference  entity.Decimal // 差额（Live - Local）
+}
+
+// Drift 运费试算请求的对比结果
+type Drift struct {
+	Request    mazon.RateCalcRequest
+	Local      entity.RateCalcResult // 本地报价
+	Live       entity.RateCalcResult // 美正报价
+	Difference entity.Decimal        // 总金额差额（Live - Local）
+	Charges    []ChargeDrift         // 金额不一致的费用
+	Drifted    bool                  // 差额是否超过允许的误差（币种不一致时总是为 true）
+	LocalErr   error                 // 本地报价失败的原因
+	LiveErr    error                 // 美正报价失败的原因
+}
+
+// Err 返回报价失败的原因
+func (d Drift) Err() error {
+	if d.LocalErr != nil {
+		return d.LocalErr
+	}
+	return d.LiveErr
+}
+
+// ReconcileReport 对比报告
+type ReconcileReport struct {
+	Tolerance entity.Decimal // 允许的误差
+	Items     []Drift        // 与请求的顺序一致
+}
+
+// Drifted 返回差额超过允许误差的结果
+func (r ReconcileReport) Drifted() []Drift {
+	var items []Drift
+	for _, item := range r.Items {
+		if item.Drifted {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Failed 返回报价失败的结果
+func (r ReconcileReport) Failed() []Drift {
+	var items []Drift
+	for _, item := range r.Items {
+		if item.Err() != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// abs 返回绝对值
+func abs(d entity.Decimal) entity.Decimal {
+	if d.IsNegative() {
+		return d.Neg()
+	}
+	return d
+}
+
+// chargeDrifts 按费用编码对比费用明细
+func chargeDrifts(local, live []entity.ChargeDetail) []ChargeDrift {
+	var drifts []ChargeDrift
+	index := make(map[string]int)
+	add := func(detail entity.ChargeDetail, isLocal bool) {
+		i, ok := index[detail.FeeTypeCode]
+		if !ok {
+			i = len(drifts)
+			index[detail.FeeTypeCode] = i
+			drifts = append(drifts, ChargeDrift{FeeTypeCode: detail.FeeTypeCode, ChargeDesc: detail.ChargeDesc})
+		}
+		if isLocal {
+			drifts[i].Local = drifts[i].Local.Add(detail.Amount)
+		} else {
+			drifts[i].Live = drifts[i].Live.Add(detail.Amount)
+		}
+	}
+	for _, detail := range local {
+		add(detail, true)
+	}
+	for _, detail := range live {
+		add(detail, false)
+	}
+
+	changed := drifts[:0]
+	for _, drift := range drifts {
+		drift.Difference = drift.Live.Sub(drift.Local)
+		if !drift.Difference.IsZero() {
+			changed = append(changed, drift)
+		}
+	}
+	return changed
+}
+
+// Reconcile 使用价格表和美正运费试算接口分别报价并对比，差额的绝对值超过 tolerance 时标记为有差异
+//
+// 请求按顺序逐个试算（不使用客户端的试算结果缓存），ctx 取消后剩余的请求不再试算（LiveErr 为 ctx.Err()）。
+func (rc *RateCard) Reconcile(ctx context.Context, client *mazon.Client, tolerance entity.Decimal, reqs ...mazon.RateCalcRequest) ReconcileReport {
+	ctx = mazon.WithoutRateCache(ctx)
+	report := ReconcileReport{Tolerance: tolerance, Items: make([]Drift, len(reqs))}
+	for i, req := range reqs {
+		item := Drift{Request: req}
+		item.Local, item.LocalErr = rc.Quote(req)
+		if err := ctx.Err(); err != nil {
+			item.LiveErr = err
+		} else {
+			item.Live, item.LiveErr = client.Services.Rate.Calc(ctx, req)
+		}
+		if item.Err() == nil {
+			item.Difference = item.Live.TotalCharge.Sub(item.Local.TotalCharge)
+			item.Charges = chargeDrifts(item.Local.ChargeDetail, item.Live.ChargeDetail)
+			item.Drifted = item.Local.CurrencyCode != item.Live.CurrencyCode ||
+				abs(item.Difference).Cmp(tolerance) > 0
+		}
+		report.Items[i] = item
+	}
+	return report
+}
@@ -23,7 +23,7 @@ func (s scanFormService) Create(ctx context.Context, trackingNumbers ...string)
 		}
 	}
 	if len(numbers) == 0 {
-		return forms, ErrInvalidTrackingNumber
+		return forms, localize(localeOf(ctx, s.config), ErrInvalidTrackingNumber)
 	}
 
 	res := struct {
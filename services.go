@@ -8,9 +8,11 @@ import (
 )
 
 type service struct {
-	config     *config.Config // Config
-	logger     *slog.Logger   // Log
-	httpClient *resty.Client  // HTTP client
+	config     *config.Config    // Config
+	logger     *slog.Logger      // Log
+	httpClient *resty.Client     // HTTP client
+	normalizer AddressNormalizer // 地址规范化（可选）
+	rateCache  *rateCache        // 运费试算结果缓存（可选）
 }
 
 // API Services
@@ -0,0 +1,225 @@
+package mazon
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+// ShipmentBox 带单位的包裹尺寸和重量
+type ShipmentBox struct {
+	Length entity.Length // 长
+	Width  entity.Length // 宽
+	Height entity.Length // 高
+	Weight entity.Weight // 重量
+	Item   OrderBox      // 包裹的 SKU、申报等信息（尺寸和重量字段会被忽略）
+}
+
+// validate 检查尺寸和重量的单位
+func (b ShipmentBox) validate(locale Locale) error {
+	for _, l := range []entity.Length{b.Length, b.Width, b.Height} {
+		if !l.Unit.IsValid() {
+			return fmt.Errorf("%w %q", localize(locale, entity.ErrUnknownLengthUnit), l.Unit)
+		}
+	}
+	if !b.Weight.Unit.IsValid() {
+		return fmt.Errorf("%w %q", localize(locale, entity.ErrUnknownWeightUnit), b.Weight.Unit)
+	}
+	return nil
+}
+
+// imperial 是否所有的尺寸和重量都是英制单位
+func (b ShipmentBox) imperial() bool {
+	return b.Length.Imperial() && b.Width.Imperial() && b.Height.Imperial() && b.Weight.Imperial()
+}
+
+// ShipmentBuilder 订单和运费试算请求构建器
+//
+// 包裹的尺寸和重量使用带单位的 entity.Length、entity.Weight，生成请求时统一转换为 WeightUnitType 对应的单位（英制为 INCH/LBS，公制为 CM/KG），
+// 尺寸四舍五入到两位小数，重量向上取整到两位小数（避免少报重量，比如 3 克为 0.01 KG）。未通过 Units 指定单位类型时，所有包裹都使用英制单位则为英制，否则为公制。
+// 包裹的单位未知（包括零值的 entity.Length、entity.Weight）时生成请求返回 entity.ErrUnknownLengthUnit、entity.ErrUnknownWeightUnit。
+//
+//	req, err := NewShipmentBuilder("business-id").
+//		SMCode("USPS GA13").
+//		Recipient(address).
+//		ShipperCode("S0004").
+//		Box(entity.Inches(10), entity.Inches(8), entity.Inches(4), entity.Pounds(2.5)).
+//		CreateOrderRequest()
+//	if err != nil {
+//		// 未知的单位
+//	}
+type ShipmentBuilder struct {
+	req    CreateOrderRequest
+	units  int
+	boxes  []ShipmentBox
+	locale Locale
+}
+
+// NewShipmentBuilder 创建请求构建器，referenceNo 为订单参考号
+func NewShipmentBuilder(referenceNo string) *ShipmentBuilder {
+	return &ShipmentBuilder{
+		req: CreateOrderRequest{ReferenceNO: referenceNo, IsMoreBox: 1},
+	}
+}
+
+// SMCode 设置物流产品代码
+func (b *ShipmentBuilder) SMCode(smCode string) *ShipmentBuilder {
+	b.req.SMCode = smCode
+	return b
+}
+
+// Remark 设置订单备注
+func (b *ShipmentBuilder) Remark(remark string) *ShipmentBuilder {
+	b.req.Remark = remark
+	return b
+}
+
+// Recipient 设置收件人地址
+func (b *ShipmentBuilder) Recipient(a entity.Address) *ShipmentBuilder {
+	b.req.SetRecipient(a)
+	return b
+}
+
+// Shipper 设置发件人信息
+func (b *ShipmentBuilder) Shipper(a entity.Address) *ShipmentBuilder {
+	b.req.SetShipper(a)
+	return b
+}
+
+// ShipperCode 设置发件人编码
+func (b *ShipmentBuilder) ShipperCode(shipperCode string) *ShipmentBuilder {
+	b.req.ShipperCode = shipperCode
+	return b
+}
+
+// ReturnAddress 设置退件地址
+func (b *ShipmentBuilder) ReturnAddress(a entity.Address) *ShipmentBuilder {
+	b.req.SetReturn(a)
+	return b
+}
+
+// Signature 设置签名服务（ASS 为成人签名，SSF 为普通签名）
+func (b *ShipmentBuilder) Signature(service string) *ShipmentBuilder {
+	b.req.SignatureService = service
+	return b
+}
+
+// PickUp 设置是否需要提货
+func (b *ShipmentBuilder) PickUp(pickUp bool) *ShipmentBuilder {
+	b.req.PickUp = 0
+	if pickUp {
+		b.req.PickUp = 1
+	}
+	return b
+}
+
+// Units 指定包裹单位类型（entity.WeightUnitImperial、entity.WeightUnitMetric）
+func (b *ShipmentBuilder) Units(weightUnitType int) *ShipmentBuilder {
+	b.units = weightUnitType
+	return b
+}
+
+// Label 设置面单格式（PDF、ZPL）和自定义面单打印类型（为空时使用默认值）
+func (b *ShipmentBuilder) Label(imageFormat, customType string) *ShipmentBuilder {
+	b.req.LabelImageFormat = imageFormat
+	b.req.LabelCustomType = customType
+	return b
+}
+
+// MailingDate 设置发货日期（yyyy-MM-dd）
+func (b *ShipmentBuilder) MailingDate(date string) *ShipmentBuilder {
+	b.req.MailingDate = date
+	return b
+}
+
+// Box 添加包裹，item 可以设置包裹的 SKU、申报等信息
+func (b *ShipmentBuilder) Box(length, width, height entity.Length, weight entity.Weight, item ...OrderBox) *ShipmentBuilder {
+	box := ShipmentBox{Length: length, Width: width, Height: height, Weight: weight}
+	if len(item) != 0 {
+		box.Item = item[0]
+	}
+	return b.AddBox(box)
+}
+
+// AddBox 添加包裹
+func (b *ShipmentBuilder) AddBox(boxes ...ShipmentBox) *ShipmentBuilder {
+	b.boxes = append(b.boxes, boxes...)
+	return b
+}
+
+// Locale 设置生成请求时返回的错误信息语言，默认为简体中文
+func (b *ShipmentBuilder) Locale(locale Locale) *ShipmentBuilder {
+	b.locale = locale
+	return b
+}
+
+// weightUnitType 返回生成请求使用的包裹单位类型
+func (b *ShipmentBuilder) weightUnitType() int {
+	if b.units == entity.WeightUnitImperial || b.units == entity.WeightUnitMetric {
+		return b.units
+	}
+	if len(b.boxes) == 0 {
+		return entity.WeightUnitMetric
+	}
+	for _, box := range b.boxes {
+		if !box.imperial() {
+			return entity.WeightUnitMetric
+		}
+	}
+	return entity.WeightUnitImperial
+}
+
+// round2 四舍五入到两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ceil2 向上取整到两位小数（忽略浮点数的计算误差），避免少报重量
+func ceil2(v float64) float64 {
+	if v <= 0 {
+		return round2(v)
+	}
+	return math.Ceil(v*100-1e-6) / 100
+}
+
+// CreateOrderRequest 生成创建订单请求，包裹的单位未知时返回错误
+func (b *ShipmentBuilder) CreateOrderRequest() (CreateOrderRequest, error) {
+	for i, box := range b.boxes {
+		if err := box.validate(b.locale); err != nil {
+			return CreateOrderRequest{}, fmt.Errorf(translate(b.locale, "包裹 %d: %w"), i+1, err)
+		}
+	}
+
+	req := b.req
+	req.WeightUnitType = b.weightUnitType()
+	lengthUnit, weightUnit := entity.Centimeter, entity.Kilogram
+	if req.WeightUnitType == entity.WeightUnitImperial {
+		lengthUnit, weightUnit = entity.Inch, entity.Pound
+	}
+
+	req.BoxList = make([]OrderBox, len(b.boxes))
+	for i, box := range b.boxes {
+		orderBox := box.Item
+		orderBox.Length = round2(box.Length.In(lengthUnit))
+		orderBox.Width = round2(box.Width.In(lengthUnit))
+		orderBox.Height = round2(box.Height.In(lengthUnit))
+		orderBox.ActualWeight = ceil2(box.Weight.In(weightUnit))
+		req.BoxList[i] = orderBox
+	}
+	req.ShipperAddress = cloneShipperAddress(req.ShipperAddress)
+	if req.ReturnAddress != nil {
+		returnAddress := *req.ReturnAddress
+		req.ReturnAddress = &returnAddress
+	}
+	return req, nil
+}
+
+// RateCalcRequest 生成运费试算请求（与 CreateOrderRequest 使用相同的单位和包裹数据），包裹的单位未知时返回错误
+func (b *ShipmentBuilder) RateCalcRequest() (RateCalcRequest, error) {
+	req, err := b.CreateOrderRequest()
+	if err != nil {
+		return RateCalcRequest{}, err
+	}
+	return NewRateCalcRequest(req), nil
+}
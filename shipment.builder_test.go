@@ -0,0 +1,70 @@
+package mazon
+
+import (
+	"testing"
+
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipmentBuilder(t *testing.T) {
+	b := NewShipmentBuilder("TEST-BUILDER").
+		SMCode("USPS GA13").
+		Recipient(testRecipient).
+		ShipperCode("S0004").
+		Signature("SSF").
+		Box(entity.Inches(10), entity.Inches(8), entity.Inches(4.125), entity.Pounds(2.5), OrderBox{Sku: "SKU-1", Length: 99}).
+		Box(entity.Inches(12), entity.Inches(12), entity.Inches(12), entity.Ounces(20))
+
+	// 都是英制单位
+	req, err := b.CreateOrderRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, entity.WeightUnitImperial, req.WeightUnitType)
+	assert.Equal(t, 1, req.IsMoreBox)
+	assert.Equal(t, "SSF", req.SignatureService)
+	assert.Equal(t, OrderBox{Length: 10, Width: 8, Height: 4.13, ActualWeight: 2.5, Sku: "SKU-1"}, req.BoxList[0])
+	assert.Equal(t, 1.25, req.BoxList[1].ActualWeight)
+	assert.Nil(t, req.Validate())
+
+	rateReq, err := b.RateCalcRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, entity.WeightUnitImperial, rateReq.WeightUnitType)
+	assert.Equal(t, RateCalcOrderBox{Length: 10, Width: 8, Height: 4.13, ActualWeight: 2.5}, rateReq.BoxList[0])
+	assert.Equal(t, req.Recipient(), rateReq.Recipient())
+
+	// 混合单位时转换为公制
+	req, err = b.Box(entity.Centimeters(30), entity.Centimeters(20), entity.Millimeters(105), entity.Grams(1234)).CreateOrderRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, entity.WeightUnitMetric, req.WeightUnitType)
+	assert.Equal(t, OrderBox{Length: 25.4, Width: 20.32, Height: 10.48, ActualWeight: 1.14, Sku: "SKU-1"}, req.BoxList[0])
+	assert.Equal(t, OrderBox{Length: 30, Width: 20, Height: 10.5, ActualWeight: 1.24}, req.BoxList[2])
+
+	// 指定单位类型
+	req, err = b.Units(entity.WeightUnitImperial).CreateOrderRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, entity.WeightUnitImperial, req.WeightUnitType)
+	assert.Equal(t, 11.81, req.BoxList[2].Length)
+	assert.Equal(t, 2.73, req.BoxList[2].ActualWeight)
+
+	// 重量向上取整，不会少报重量
+	req, err = NewShipmentBuilder("LIGHT").
+		Box(entity.Centimeters(1), entity.Centimeters(1), entity.Centimeters(1), entity.Grams(3)).
+		Box(entity.Centimeters(1), entity.Centimeters(1), entity.Centimeters(1), entity.Grams(1100)).
+		CreateOrderRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, 0.01, req.BoxList[0].ActualWeight)
+	assert.Equal(t, 1.1, req.BoxList[1].ActualWeight)
+
+	req, err = NewShipmentBuilder("EMPTY").CreateOrderRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, entity.WeightUnitMetric, req.WeightUnitType)
+
+	// 未知单位（包括零值）
+	_, err = b.AddBox(ShipmentBox{}).CreateOrderRequest()
+	assert.ErrorIs(t, err, entity.ErrUnknownLengthUnit)
+	assert.Contains(t, err.Error(), "包裹 4")
+	_, err = NewShipmentBuilder("UNKNOWN").
+		Box(entity.Inches(1), entity.Inches(1), entity.Inches(1), entity.Weight{Value: 1, Unit: "stone"}).
+		RateCalcRequest()
+	assert.ErrorIs(t, err, entity.ErrUnknownWeightUnit)
+}
@@ -3,12 +3,16 @@ package mazon
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/hiscaler/mazon-go/entity"
 )
 
+var ErrForecastFailed = errors.New("预报失败")
+
 // 面单服务
 type shippingLabelService service
 
@@ -28,7 +32,7 @@ func (m ShippingLabelDetailRequest) validate() error {
 // https://www.mazonlabel.com/docs/orderapi/%E8%8E%B7%E5%8F%96%E9%9D%A2%E5%8D%95.html
 func (s shippingLabelService) Detail(ctx context.Context, req ShippingLabelDetailRequest) (label entity.ShippingLabel, err error) {
 	if err = req.validate(); err != nil {
-		return label, invalidInput(err)
+		return label, invalidInput(localeOf(ctx, s.config), err)
 	}
 
 	res := struct {
@@ -57,7 +61,7 @@ func (s shippingLabelService) Query(ctx context.Context, trackingNumbers ...stri
 		}
 	}
 	if len(numbers) == 0 {
-		return labels, errors.New("无效的跟踪号")
+		return labels, localize(localeOf(ctx, s.config), ErrInvalidTrackingNumber)
 	}
 
 	res := struct {
@@ -74,3 +78,87 @@ func (s shippingLabelService) Query(ctx context.Context, trackingNumbers ...stri
 	}
 	return res.Result, nil
 }
+
+// WaitOptions 等待面单的轮询设置
+type WaitOptions struct {
+	Interval    time.Duration // 首次轮询间隔，默认为 2 秒
+	MaxInterval time.Duration // 最大轮询间隔，默认为 30 秒
+	Multiplier  float64       // 每次轮询后间隔的增长倍数，默认为 1.5
+	Timeout     time.Duration // 最长等待时间，默认为 0，即仅由 ctx 控制
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.Interval <= 0 {
+		o.Interval = 2 * time.Second
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 30 * time.Second
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = o.Interval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1.5
+	}
+	return o
+}
+
+// labelReady 面单和物流单号是否都已经生成
+func labelReady(label entity.ShippingLabel) bool {
+	if len(label.Labels) == 0 {
+		return false
+	}
+	for _, l := range label.Labels {
+		if l.TrackingNumber == "" || l.LabelUrl == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitReady 轮询获取面单，直到面单和物流单号都已生成、预报失败（LogisticsErr 不为空）或者 ctx 结束
+//
+// 创建订单后如果 LabelStatus 为 1（预报中），面单和物流单号需要异步获取，可以使用该方法等待预报完成。
+// 预报失败时返回 ErrForecastFailed，等待超时返回 ctx 的错误，两种情况下都会同时返回最后一次获取的面单信息。
+// 请求超时或者美正内部错误时会继续轮询。
+func (s shippingLabelService) WaitReady(ctx context.Context, req ShippingLabelDetailRequest, opts WaitOptions) (label entity.ShippingLabel, err error) {
+	opts = opts.withDefaults()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	interval := opts.Interval
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return label, ctx.Err()
+		case <-timer.C:
+		}
+
+		detail, e := s.Detail(ctx, req)
+		switch {
+		case e == nil:
+			label = detail
+			if label.LogisticsErr != "" {
+				return label, fmt.Errorf("%w: %s", localize(localeOf(ctx, s.config), ErrForecastFailed), label.LogisticsErr)
+			}
+			if labelReady(label) {
+				return label, nil
+			}
+		case errors.Is(e, ErrTimeout), errors.Is(e, ErrInternal):
+			s.logger.WarnContext(ctx, "Wait shipping label", "error", e)
+		default:
+			if ctx.Err() != nil {
+				return label, ctx.Err()
+			}
+			return label, e
+		}
+
+		timer.Reset(interval)
+		interval = min(time.Duration(float64(interval)*opts.Multiplier), opts.MaxInterval)
+	}
+}
@@ -1,8 +1,13 @@
 package mazon
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/hiscaler/mazon-go/mazontest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,3 +35,49 @@ func Test_shippingLabelService_Query(t *testing.T) {
 		}
 	}
 }
+
+func Test_shippingLabelService_WaitReady(t *testing.T) {
+	s := mazontest.NewServer()
+	defer s.Close()
+	s.ForecastPolls = 3
+	c := NewClient(ctx, s.Config(), WithTokenStore(NewMemoryTokenStore()))
+	opts := WaitOptions{Interval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond, Timeout: 5 * time.Second}
+
+	req := CreateOrderRequest{
+		ReferenceNO:      "TEST-WAIT-READY",
+		SMCode:           "USPS GA13",
+		OAFirstname:      "ZZZ",
+		OATelephone:      "0731-12345678",
+		OACountry:        "US",
+		OAState:          "CA",
+		OACity:           "Ontario",
+		OAPostcode:       "91761",
+		OAStreetAddress1: "2078 E Francis Street",
+		BoxList:          []OrderBox{{Height: 1, Length: 1, Width: 1, ActualWeight: 1}},
+		IsMoreBox:        1,
+		ShipperCode:      "S0004",
+	}
+	label, err := c.Services.Order.CreateAndWait(ctx, req, opts)
+	assert.Nil(t, err)
+	if assert.Len(t, label.Labels, 1) {
+		assert.NotEmpty(t, label.Labels[0].TrackingNumber)
+	}
+	assert.Equal(t, entity.OrderForecasted, label.OrderStatus)
+	assert.Equal(t, 3, s.Requests("/getLabel"))
+
+	// 预报失败
+	s.ForecastError = "address not found"
+	req.ReferenceNO = "TEST-WAIT-FAILED"
+	label, err = c.Services.Order.CreateAndWait(ctx, req, opts)
+	assert.True(t, errors.Is(err, ErrForecastFailed))
+	assert.Equal(t, "address not found", label.LogisticsErr)
+
+	// 等待超时
+	s.ForecastPolls = 1000
+	req.ReferenceNO = "TEST-WAIT-TIMEOUT"
+	opts.Timeout = 100 * time.Millisecond
+	label, err = c.Services.Order.CreateAndWait(ctx, req, opts)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.NotEmpty(t, label.OrderCode)
+	assert.Empty(t, label.Labels)
+}
@@ -0,0 +1,192 @@
+package mazon
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hiscaler/aar"
+)
+
+// TokenStore Access Token 存储接口
+//
+// Get 在 Token 不存在或已过期时返回空字符串和 nil 错误，
+// 实现方需要保证并发安全，可以基于 Redis 等共享缓存实现以便多个实例共用同一个 Token。
+type TokenStore interface {
+	Get(ctx context.Context, key string) (string, error)                               // 读取 Token
+	Set(ctx context.Context, key string, token string, expiration time.Duration) error // 写入 Token，并在 expiration 后过期
+	Invalidate(ctx context.Context, key string) error                                  // 使 Token 失效
+}
+
+var (
+	_ TokenStore = (*MemoryTokenStore)(nil)
+	_ TokenStore = (*FileTokenStore)(nil)
+	_ TokenStore = (*AarTokenStore)(nil)
+)
+
+type memoryToken struct {
+	value     string
+	expiredAt time.Time
+}
+
+// MemoryTokenStore 基于内存的 Token 存储，仅在当前进程内有效
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]memoryToken
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]memoryToken)}
+}
+
+func (s *MemoryTokenStore) Get(_ context.Context, key string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tokens[key]
+	if !ok || !time.Now().Before(t.expiredAt) {
+		return "", nil
+	}
+	return t.value, nil
+}
+
+func (s *MemoryTokenStore) Set(_ context.Context, key string, token string, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = memoryToken{value: token, expiredAt: time.Now().Add(expiration)}
+	return nil
+}
+
+func (s *MemoryTokenStore) Invalidate(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore 基于本地文件的 Token 存储，过期时间和 Token 一起写入文件
+type FileTokenStore struct {
+	mu  sync.Mutex
+	dir string // 存储目录
+}
+
+// NewFileTokenStore 创建文件存储，dir 为空时使用系统临时目录
+func NewFileTokenStore(dir string) *FileTokenStore {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return &FileTokenStore{dir: dir}
+}
+
+type fileToken struct {
+	Token     string    `json:"token"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+func (s *FileTokenStore) filename(key string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%x.token", md5.Sum([]byte(key))))
+}
+
+func (s *FileTokenStore) Get(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := os.ReadFile(s.filename(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	var t fileToken
+	if err = json.Unmarshal(b, &t); err != nil {
+		return "", nil
+	}
+	if !time.Now().Before(t.ExpiredAt) {
+		return "", nil
+	}
+	return t.Token, nil
+}
+
+func (s *FileTokenStore) Set(_ context.Context, key string, token string, expiration time.Duration) error {
+	b, err := json.Marshal(fileToken{Token: token, ExpiredAt: time.Now().Add(expiration)})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.filename(key), b, 0600)
+}
+
+func (s *FileTokenStore) Invalidate(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.filename(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// AarTokenStore 基于 aar 的 Token 存储（默认存储方式）
+//
+// aar 根据文件修改时间判断是否过期，所以同一个 key 在 Set 时指定的有效期会被记录下来，
+// 进程重启后未调用 Set 前使用创建时指定的默认有效期
+type AarTokenStore struct {
+	mu        sync.Mutex
+	duration  time.Duration            // 默认有效期
+	durations map[string]time.Duration // Set 时指定的有效期
+}
+
+func NewAarTokenStore(duration time.Duration) *AarTokenStore {
+	return &AarTokenStore{
+		duration:  duration,
+		durations: make(map[string]time.Duration),
+	}
+}
+
+func (s *AarTokenStore) Get(_ context.Context, key string) (string, error) {
+	ar, err := aar.New(key)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	d, ok := s.durations[key]
+	s.mu.Unlock()
+	if !ok {
+		d = s.duration
+	}
+	// 读取失败或者已过期都视为不存在
+	token, _ := ar.SetDuration(d).Read()
+	return token, nil
+}
+
+func (s *AarTokenStore) Set(_ context.Context, key string, token string, expiration time.Duration) error {
+	ar, err := aar.New(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.durations[key] = expiration
+	s.mu.Unlock()
+	return ar.Write([]byte(token))
+}
+
+func (s *AarTokenStore) Invalidate(_ context.Context, key string) error {
+	ar, err := aar.New(key)
+	if err != nil {
+		return err
+	}
+	return ar.Write(nil)
+}
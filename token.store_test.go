@@ -0,0 +1,45 @@
+package mazon
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTokenStore(t *testing.T, store TokenStore) {
+	key := fmt.Sprintf("mazon.test.token.%d", time.Now().UnixNano())
+	defer store.Invalidate(ctx, key)
+
+	token, err := store.Get(ctx, key)
+	assert.Nil(t, err)
+	assert.Empty(t, token)
+
+	assert.Nil(t, store.Set(ctx, key, "abc", time.Minute))
+	token, err = store.Get(ctx, key)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", token)
+
+	assert.Nil(t, store.Invalidate(ctx, key))
+	token, err = store.Get(ctx, key)
+	assert.Nil(t, err)
+	assert.Empty(t, token)
+
+	assert.Nil(t, store.Set(ctx, key, "expired", 0))
+	token, err = store.Get(ctx, key)
+	assert.Nil(t, err)
+	assert.Empty(t, token)
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStore(t, NewMemoryTokenStore())
+}
+
+func TestFileTokenStore(t *testing.T) {
+	testTokenStore(t, NewFileTokenStore(t.TempDir()))
+}
+
+func TestAarTokenStore(t *testing.T) {
+	testTokenStore(t, NewAarTokenStore(time.Hour))
+}
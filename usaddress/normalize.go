@@ -0,0 +1,233 @@
+// Package usaddress 离线的美国地址规范化
+//
+// 不需要请求地址验证服务，使用内置的州、属地数据将州名称转换为二字代码，解析 ZIP+4 邮编，
+// 去除地址中的标点符号，并将超过 35 个字符的街道地址拆分到地址 2 中。
+//
+//	client := mazon.NewClient(ctx, cfg, mazon.WithAddressNormalizer(usaddress.Normalize))
+package usaddress
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/mazon-go/entity"
+)
+
+// MaxLineLength 街道地址的最大长度
+const MaxLineLength = 35
+
+// 验证错误，错误代码可以通过 validation.Error 的 Code() 判断，错误信息可以在 mazon 包中翻译
+var (
+	ErrInvalidZIP     = validation.NewError("validation_us_zip_invalid", "无效的邮编 {{.value}}")
+	ErrInvalidState   = validation.NewError("validation_us_state_invalid", "无效的州 {{.value}}")
+	ErrAddressTooLong = validation.NewError("validation_us_address_too_long", "地址超过 {{.max}} 个字符，无法拆分")
+)
+
+// ZIP 邮编
+type ZIP struct {
+	Code  string // 5 位邮编
+	Plus4 string // 4 位扩展邮编，可以为空
+}
+
+// String 返回 12345 或者 12345-6789 格式的邮编
+func (z ZIP) String() string {
+	if z.Plus4 == "" {
+		return z.Code
+	}
+	return z.Code + "-" + z.Plus4
+}
+
+// Option 规范化选项
+type Option func(o *options)
+
+type options struct {
+	padZIP bool // 是否补齐邮编的前导 0
+}
+
+// WithZIPPadding 为 4 位（或者 8 位）邮编补齐前导 0，比如 2134 转换为 02134
+//
+// 4 位邮编也可能是被截断的邮编，默认返回 ErrInvalidZIP，只有确定数据来源（比如 Excel 导出的文件）会去掉前导 0 时才使用。
+func WithZIPPadding() Option {
+	return func(o *options) {
+		o.padZIP = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// ParseZIP 解析邮编，支持 12345、12345-6789、12345 6789、123456789 格式，无效的邮编返回 ErrInvalidZIP（包含 value 参数）
+//
+// 4 位（或者 8 位）邮编默认为无效的邮编，使用 WithZIPPadding 时补齐前导 0。
+func ParseZIP(s string, opts ...Option) (ZIP, error) {
+	return parseZIP(s, newOptions(opts))
+}
+
+func parseZIP(s string, o options) (ZIP, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return ZIP{}, ErrInvalidZIP.SetParams(map[string]any{"value": s})
+		}
+	}
+	if o.padZIP && (len(digits) == 4 || len(digits) == 8) {
+		digits = "0" + digits
+	}
+	switch len(digits) {
+	case 5:
+		return ZIP{Code: digits}, nil
+	case 9:
+		return ZIP{Code: digits[:5], Plus4: digits[5:]}, nil
+	}
+	return ZIP{}, ErrInvalidZIP.SetParams(map[string]any{"value": s})
+}
+
+// key 转换为大写，去除标点和多余的空格
+func key(s string) string {
+	return strings.ToUpper(clean(s))
+}
+
+// clean 去除标点符号（# - / & 除外）和多余的空格，比如 "St. Louis" 转换为 "St Louis"
+func clean(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '.' || r == '\'' || r == '’':
+			return -1
+		case r == '#' || r == '-' || r == '/' || r == '&':
+			return r
+		case unicode.IsPunct(r):
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// SplitLine 将超过 limit 个字符的地址在单词边界处拆分为两部分，单词超过 limit 个字符时直接截断
+func SplitLine(line string, limit int) (string, string) {
+	runes := []rune(line)
+	if len(runes) <= limit {
+		return line, ""
+	}
+	i := limit
+	for i > 0 && runes[i] != ' ' {
+		i--
+	}
+	if i == 0 {
+		i = limit
+	}
+	return strings.TrimSpace(string(runes[:i])), strings.TrimSpace(string(runes[i:]))
+}
+
+// isUS 是否为美国地址
+func isUS(country string) bool {
+	switch key(country) {
+	case "US", "USA", "UNITED STATES", "UNITED STATES OF AMERICA":
+		return true
+	}
+	return false
+}
+
+// phone 去除电话号码中的格式字符，美国国家代码 1 开头的 11 位号码转换为 10 位号码，包含分机号等字母的号码不做处理
+func phone(s string) string {
+	s = strings.TrimSpace(s)
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == '+' || r == '(' || r == ')' || r == '-' || r == '.' || unicode.IsSpace(r):
+			return -1
+		}
+		return 'x'
+	}, s)
+	if strings.ContainsRune(digits, 'x') {
+		return s
+	}
+	if len(digits) == 11 && digits[0] == '1' {
+		digits = digits[1:]
+	}
+	return digits
+}
+
+// Normalize 规范化美国地址
+//
+//   - 国家转换为 US，州名称转换为大写的二字代码
+//   - 邮编转换为 12345 或者 12345-6789 格式（与 entity.ReturnAddress.ZipCodeAndPlus4 相同）
+//   - 去除城市和街道地址中的标点符号，电话号码只保留数字
+//   - 地址 1 超过 35 个字符时将超出的部分移到地址 2
+//
+// 非美国地址只去除多余的空格。无法识别的州、邮编以及拆分后仍然超长的地址 2 返回 validation.Errors（以 entity.Address 的 JSON 字段名称为键）。
+func Normalize(a entity.Address) (entity.Address, error) {
+	return normalize(a, options{})
+}
+
+// NewNormalizer 返回使用指定选项的 Normalize，比如：
+//
+//	client := mazon.NewClient(ctx, cfg, mazon.WithAddressNormalizer(usaddress.NewNormalizer(usaddress.WithZIPPadding())))
+func NewNormalizer(opts ...Option) func(a entity.Address) (entity.Address, error) {
+	o := newOptions(opts)
+	return func(a entity.Address) (entity.Address, error) {
+		return normalize(a, o)
+	}
+}
+
+func normalize(a entity.Address, o options) (entity.Address, error) {
+	a.Name = strings.Join(strings.Fields(a.Name), " ")
+	a.Company = strings.Join(strings.Fields(a.Company), " ")
+	a.Country = strings.TrimSpace(a.Country)
+	a.State = strings.TrimSpace(a.State)
+	a.City = strings.Join(strings.Fields(a.City), " ")
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+	a.Line1 = strings.Join(strings.Fields(a.Line1), " ")
+	a.Line2 = strings.Join(strings.Fields(a.Line2), " ")
+	a.Phone = strings.TrimSpace(a.Phone)
+	if !isUS(a.Country) {
+		return a, nil
+	}
+
+	errs := validation.Errors{}
+	a.Country = "US"
+	a.Phone = phone(a.Phone)
+	a.City = clean(a.City)
+	if a.State != "" {
+		if code, ok := State(a.State); ok {
+			a.State = code
+		} else {
+			errs["state"] = ErrInvalidState.SetParams(map[string]any{"value": a.State})
+		}
+	}
+	if a.PostalCode != "" {
+		if zip, err := parseZIP(a.PostalCode, o); err == nil {
+			a.PostalCode = zip.String()
+		} else {
+			errs["postal_code"] = err
+		}
+	}
+
+	a.Line1, a.Line2 = clean(a.Line1), clean(a.Line2)
+	if a.Line1 == "" {
+		a.Line1, a.Line2 = a.Line2, ""
+	}
+	if line1, rest := SplitLine(a.Line1, MaxLineLength); rest != "" {
+		a.Line1, a.Line2 = line1, strings.TrimSpace(rest+" "+a.Line2)
+	}
+	if utf8.RuneCountInString(a.Line2) > MaxLineLength {
+		errs["line2"] = ErrAddressTooLong.SetParams(map[string]any{"max": MaxLineLength * 2})
+	}
+	if len(errs) != 0 {
+		return a, errs
+	}
+	return a, nil
+}
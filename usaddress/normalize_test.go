@@ -0,0 +1,157 @@
+package usaddress
+
+import (
+	"errors"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hiscaler/mazon-go/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState(t *testing.T) {
+	tests := []struct {
+		s    string
+		code string
+		ok   bool
+	}{
+		{"ca", "CA", true},
+		{" California ", "CA", true},
+		{"N.Y.", "NY", true},
+		{"new  york", "NY", true},
+		{"Washington, D.C.", "DC", true},
+		{"puerto rico", "PR", true},
+		{"U.S. Virgin Islands", "VI", true},
+		{"ap", "AP", true},
+		{"Calif", "", false},
+		{"XX", "", false},
+	}
+	for _, tt := range tests {
+		code, ok := State(tt.s)
+		assert.Equal(t, tt.ok, ok, tt.s)
+		assert.Equal(t, tt.code, code, tt.s)
+	}
+
+	name, ok := StateName("tx")
+	assert.True(t, ok)
+	assert.Equal(t, "Texas", name)
+}
+
+func TestParseZIP(t *testing.T) {
+	tests := []struct {
+		s    string
+		zip  string
+		fail bool
+	}{
+		{"91761", "91761", false},
+		{"75115-2500", "75115-2500", false},
+		{"75115 2500", "75115-2500", false},
+		{"751152500", "75115-2500", false},
+		{"2134", "", true},
+		{"21341234", "", true},
+		{"917", "", true},
+		{"K1A 0B1", "", true},
+	}
+	for _, tt := range tests {
+		zip, err := ParseZIP(tt.s)
+		if tt.fail {
+			var e validation.Error
+			if assert.ErrorAs(t, err, &e, tt.s) {
+				assert.Equal(t, ErrInvalidZIP.Code(), e.Code())
+				assert.Equal(t, "无效的邮编 "+tt.s, e.Error())
+			}
+			continue
+		}
+		assert.Nil(t, err, tt.s)
+		assert.Equal(t, tt.zip, zip.String(), tt.s)
+	}
+
+	// 补齐前导 0
+	for s, expected := range map[string]string{"2134": "02134", "21341234": "02134-1234", "91761": "91761"} {
+		zip, err := ParseZIP(s, WithZIPPadding())
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, zip.String(), s)
+	}
+	_, err := ParseZIP("917", WithZIPPadding())
+	var e validation.Error
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, ErrInvalidZIP.Code(), e.Code())
+	}
+}
+
+func TestSplitLine(t *testing.T) {
+	first, rest := SplitLine("2078 E Francis Street Building 4 Dock Door 12", 35)
+	assert.Equal(t, "2078 E Francis Street Building 4", first)
+	assert.Equal(t, "Dock Door 12", rest)
+
+	first, rest = SplitLine("Short street", 35)
+	assert.Equal(t, "Short street", first)
+	assert.Empty(t, rest)
+
+	first, rest = SplitLine("ABCDEFGHIJ", 4)
+	assert.Equal(t, "ABCD", first)
+	assert.Equal(t, "EFGHIJ", rest)
+}
+
+func TestNormalize(t *testing.T) {
+	a, err := Normalize(entity.Address{
+		Name:       "  John   Doe ",
+		Phone:      "+1 (909) 555-0100",
+		Country:    "United States",
+		State:      "california",
+		City:       "St. Louis",
+		PostalCode: "91761 1234",
+		Line1:      "2078 E. Francis Street, Building 4, Dock Door 12",
+		Line2:      "Attn: Receiving",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Address{
+		Name:       "John Doe",
+		Phone:      "9095550100",
+		Country:    "US",
+		State:      "CA",
+		City:       "St Louis",
+		PostalCode: "91761-1234",
+		Line1:      "2078 E Francis Street Building 4",
+		Line2:      "Dock Door 12 Attn Receiving",
+	}, a)
+
+	// 非美国地址
+	a, err = Normalize(entity.Address{Country: "CA", State: "Ontario", PostalCode: "K1A 0B1", Line1: "24 Sussex Dr."})
+	assert.Nil(t, err)
+	assert.Equal(t, "Ontario", a.State)
+	assert.Equal(t, "24 Sussex Dr.", a.Line1)
+
+	_, err = Normalize(entity.Address{
+		Country:    "US",
+		State:      "Calif",
+		PostalCode: "917",
+		Line1:      "1234567890 1234567890 1234567890 1234567890",
+		Line2:      "1234567890 1234567890 1234567890",
+	})
+	var errs validation.Errors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Len(t, errs, 3)
+		for name, code := range map[string]string{
+			"state":       "validation_us_state_invalid",
+			"postal_code": "validation_us_zip_invalid",
+			"line2":       "validation_us_address_too_long",
+		} {
+			var e validation.Error
+			if assert.ErrorAs(t, errs[name], &e, name) {
+				assert.Equal(t, code, e.Code(), name)
+			}
+		}
+		assert.Equal(t, "无效的州 Calif", errs["state"].Error())
+		assert.Equal(t, "地址超过 70 个字符，无法拆分", errs["line2"].Error())
+	}
+
+	// 4 位邮编默认无效，使用 WithZIPPadding 时补齐前导 0
+	_, err = Normalize(entity.Address{Country: "US", PostalCode: "2134"})
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Contains(t, errs, "postal_code")
+	}
+	a, err = NewNormalizer(WithZIPPadding())(entity.Address{Country: "US", PostalCode: "2134"})
+	assert.Nil(t, err)
+	assert.Equal(t, "02134", a.PostalCode)
+}
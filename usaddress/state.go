@@ -0,0 +1,116 @@
+package usaddress
+
+import "strings"
+
+// states 州、属地和军邮地区（代码 -> 名称）
+var states = map[string]string{
+	"AL": "Alabama",
+	"AK": "Alaska",
+	"AZ": "Arizona",
+	"AR": "Arkansas",
+	"CA": "California",
+	"CO": "Colorado",
+	"CT": "Connecticut",
+	"DE": "Delaware",
+	"DC": "District of Columbia",
+	"FL": "Florida",
+	"GA": "Georgia",
+	"HI": "Hawaii",
+	"ID": "Idaho",
+	"IL": "Illinois",
+	"IN": "Indiana",
+	"IA": "Iowa",
+	"KS": "Kansas",
+	"KY": "Kentucky",
+	"LA": "Louisiana",
+	"ME": "Maine",
+	"MD": "Maryland",
+	"MA": "Massachusetts",
+	"MI": "Michigan",
+	"MN": "Minnesota",
+	"MS": "Mississippi",
+	"MO": "Missouri",
+	"MT": "Montana",
+	"NE": "Nebraska",
+	"NV": "Nevada",
+	"NH": "New Hampshire",
+	"NJ": "New Jersey",
+	"NM": "New Mexico",
+	"NY": "New York",
+	"NC": "North Carolina",
+	"ND": "North Dakota",
+	"OH": "Ohio",
+	"OK": "Oklahoma",
+	"OR": "Oregon",
+	"PA": "Pennsylvania",
+	"RI": "Rhode Island",
+	"SC": "South Carolina",
+	"SD": "South Dakota",
+	"TN": "Tennessee",
+	"TX": "Texas",
+	"UT": "Utah",
+	"VT": "Vermont",
+	"VA": "Virginia",
+	"WA": "Washington",
+	"WV": "West Virginia",
+	"WI": "Wisconsin",
+	"WY": "Wyoming",
+	// 属地
+	"AS": "American Samoa",
+	"GU": "Guam",
+	"MP": "Northern Mariana Islands",
+	"PR": "Puerto Rico",
+	"VI": "U.S. Virgin Islands",
+	"UM": "U.S. Minor Outlying Islands",
+	"FM": "Federated States of Micronesia",
+	"MH": "Marshall Islands",
+	"PW": "Palau",
+	// 军邮
+	"AA": "Armed Forces Americas",
+	"AE": "Armed Forces Europe",
+	"AP": "Armed Forces Pacific",
+}
+
+// aliases 州名称的其他写法
+var aliases = map[string]string{
+	"WASHINGTON DC":                "DC",
+	"WASHINGTON D C":               "DC",
+	"VIRGIN ISLANDS":               "VI",
+	"US VIRGIN ISLANDS":            "VI",
+	"UNITED STATES VIRGIN ISLANDS": "VI",
+	"MICRONESIA":                   "FM",
+	"NORTHERN MARIANAS":            "MP",
+}
+
+// names 名称（大写，去除标点）-> 代码
+var names = func() map[string]string {
+	m := make(map[string]string, len(states)+len(aliases))
+	for code, name := range states {
+		m[key(name)] = code
+	}
+	for name, code := range aliases {
+		m[key(name)] = code
+	}
+	return m
+}()
+
+// State 返回州、属地的二字代码，s 可以是代码或者名称（不区分大小写，忽略标点和多余的空格），比如 ca、California、N.Y.
+func State(s string) (string, bool) {
+	k := key(s)
+	if _, ok := states[k]; ok {
+		return k, true
+	}
+	if code := strings.ReplaceAll(k, " ", ""); len(code) == 2 {
+		if _, ok := states[code]; ok {
+			return code, true
+		}
+	}
+	code, ok := names[k]
+	return code, ok
+}
+
+// StateName 返回州、属地的名称
+func StateName(code string) (string, bool) {
+	name, ok := states[strings.ToUpper(strings.TrimSpace(code))]
+	return name, ok
+}
@@ -0,0 +1,156 @@
+package zpl
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/pdf417"
+)
+
+// drawBarcode 绘制条码字段，不支持的条码（MaxiCode）返回 ErrUnsupportedBarcode
+func (r *renderer) drawBarcode(origin image.Point, cmd command, data string) error {
+	params := split(cmd.params)
+	switch cmd.name {
+	case "BC":
+		r.drawCode128(origin, params, data)
+	case "B7":
+		r.drawPDF417(origin, params, data)
+	case "BD":
+		return fmt.Errorf("%w: MaxiCode（^BD）", ErrUnsupportedBarcode)
+	}
+	return nil
+}
+
+func isBlack(c color.Color) bool {
+	return color.GrayModel.Convert(c).(color.Gray).Y < 0x80
+}
+
+// code128Content 处理 Code128 的调用码（比如 >; 切换到 C 字符集、>8 为 FNC1），返回编码内容和注释行文本
+func code128Content(data string, mode byte) (content, text string) {
+	if mode == 'D' {
+		// UCC/EAN 模式：以 FNC1 开头，编码时去掉括号
+		return string(code128.FNC1) + strings.NewReplacer("(", "", ")", "").Replace(data), data
+	}
+
+	var c, t strings.Builder
+	for i := 0; i < len(data); i++ {
+		if data[i] == '>' && i+1 < len(data) {
+			switch data[i+1] {
+			case '8':
+				c.WriteRune(code128.FNC1)
+				i++
+				continue
+			case '9', ':', ';', '5', '6', '7':
+				i++
+				continue
+			case '<':
+				c.WriteByte('<')
+				t.WriteByte('<')
+				i++
+				continue
+			case '0':
+				c.WriteByte('>')
+				t.WriteByte('>')
+				i++
+				continue
+			}
+		}
+		c.WriteByte(data[i])
+		t.WriteByte(data[i])
+	}
+	return c.String(), t.String()
+}
+
+// modules 返回一维条码的模块（true 为条）
+func modules(bc barcode.Barcode) []bool {
+	b := bc.Bounds()
+	m := make([]bool, b.Dx())
+	for x := range m {
+		m[x] = isBlack(bc.At(b.Min.X+x, b.Min.Y))
+	}
+	return m
+}
+
+// drawCode128 绘制 Code128 条码（^BCo,h,f,g,e,m）
+func (r *renderer) drawCode128(origin image.Point, params []string, data string) {
+	orientation := charParam(params, 0, r.orientation)
+	height := max(intParam(params, 1, r.barHeight), 1)
+	interpretation := charParam(params, 2, 'Y') == 'Y'
+	above := charParam(params, 3, 'N') == 'Y'
+	content, text := code128Content(data, charParam(params, 5, 'N'))
+
+	bc, err := code128.Encode(content)
+	if err != nil {
+		return
+	}
+	bars := modules(bc)
+	w := r.moduleWidth
+	width := len(bars) * w
+
+	// 注释行
+	textHeight, gap := 0, 0
+	spec := fontSpec{name: '0', height: 9*w + 2, width: 9*w + 2}
+	if interpretation {
+		textHeight, gap = spec.height, w*2
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, width, height+gap+textHeight))
+	barTop := 0
+	if interpretation && above {
+		barTop = textHeight + gap
+	}
+	for i, bar := range bars {
+		if !bar {
+			continue
+		}
+		for y := barTop; y < barTop+height; y++ {
+			for x := i * w; x < (i+1)*w; x++ {
+				mask.Pix[y*mask.Stride+x] = 0xff
+			}
+		}
+	}
+	if interpretation {
+		if f, err := face(false, spec.height); err == nil {
+			tw := textWidth(f, spec, text)
+			top := height + gap
+			if above {
+				top = 0
+			}
+			drawLine(mask, f, spec, (width-tw)/2, top+f.Metrics().Ascent.Ceil(), text)
+		}
+	}
+	// ^FT 的位置为条码左下角
+	r.place(mask, origin, orientation, image.Pt(0, barTop+height), false)
+}
+
+// drawPDF417 绘制 PDF417 条码（^B7o,h,s,c,r,t），h 为行高
+func (r *renderer) drawPDF417(origin image.Point, params []string, data string) {
+	orientation := charParam(params, 0, r.orientation)
+	rowHeight := max(intParam(params, 1, r.moduleWidth*3), 1)
+	security := min(max(intParam(params, 2, 2), 1), 8)
+
+	bc, err := pdf417.Encode(data, byte(security))
+	if err != nil {
+		return
+	}
+	b := bc.Bounds()
+	rows := b.Dy() / 2 // 每行 2 个像素
+	w := r.moduleWidth
+	mask := image.NewAlpha(image.Rect(0, 0, b.Dx()*w, rows*rowHeight))
+	for row := 0; row < rows; row++ {
+		for col := 0; col < b.Dx(); col++ {
+			if !isBlack(bc.At(b.Min.X+col, b.Min.Y+row*2)) {
+				continue
+			}
+			for y := row * rowHeight; y < (row+1)*rowHeight; y++ {
+				for x := col * w; x < (col+1)*w; x++ {
+					mask.Pix[y*mask.Stride+x] = 0xff
+				}
+			}
+		}
+	}
+	r.place(mask, origin, orientation, image.Pt(0, rows*rowHeight), false)
+}
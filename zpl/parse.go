@@ -0,0 +1,129 @@
+package zpl
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// command ZPL 指令，比如 ^FO50,60 的 name 为 FO，params 为 50,60
+//
+// ^A 指令的 name 为 A，字体名称为 params 的第一个字符，比如 ^A0N,30,30 的 params 为 0N,30,30
+type command struct {
+	name   string
+	params string
+}
+
+// parse 解析第一个面单（^XA ... ^XZ）中的指令
+func parse(data []byte) []command {
+	var commands []command
+	started := false
+	i := 0
+	for i < len(data) {
+		if c := data[i]; c != '^' && c != '~' {
+			i++
+			continue
+		}
+		if i+2 > len(data) {
+			break
+		}
+
+		name := strings.ToUpper(string(data[i+1 : min(i+3, len(data))]))
+		start := i + 3
+		if name[0] == 'A' && name != "A@" {
+			name, start = "A", i+2
+		}
+		start = min(start, len(data))
+
+		var end int
+		switch name {
+		case "FD", "FV":
+			// 字段数据到 ^FS 为止
+			end = bytes.Index(data[start:], []byte("^FS"))
+			if end < 0 {
+				end = len(data) - start
+			}
+			end += start
+		default:
+			end = start
+			for end < len(data) && data[end] != '^' && data[end] != '~' {
+				end++
+			}
+		}
+
+		params := string(data[start:end])
+		if name != "FD" && name != "FV" {
+			params = strings.NewReplacer("\r", "", "\n", "").Replace(params)
+		}
+		i = end
+
+		switch name {
+		case "XA":
+			started = true
+			continue
+		case "XZ":
+			if started {
+				return commands
+			}
+			continue
+		}
+		if started {
+			commands = append(commands, command{name: name, params: params})
+		}
+	}
+	return commands
+}
+
+// split 拆分指令参数
+func split(params string) []string {
+	return strings.Split(params, ",")
+}
+
+// intParam 返回第 i 个整数参数，不存在或者无效时返回 def
+func intParam(params []string, i int, def int) int {
+	if i < len(params) {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(params[i]), 64); err == nil {
+			return int(v)
+		}
+	}
+	return def
+}
+
+// floatParam 返回第 i 个浮点数参数，不存在或者无效时返回 def
+func floatParam(params []string, i int, def float64) float64 {
+	if i < len(params) {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(params[i]), 64); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+// charParam 返回第 i 个字符参数（大写），不存在时返回 def
+func charParam(params []string, i int, def byte) byte {
+	if i < len(params) {
+		if s := strings.TrimSpace(params[i]); s != "" {
+			return strings.ToUpper(s)[0]
+		}
+	}
+	return def
+}
+
+// decodeHex 还原 ^FH 指令指定的十六进制转义字符，比如 _1E
+func decodeHex(s string, indicator byte) string {
+	if strings.IndexByte(s, indicator) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == indicator && i+2 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package zpl
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"strconv"
+)
+
+// RenderPDF 渲染 ZPL 中的第一个面单，返回单页 PDF（页面尺寸与面单尺寸一致）
+func RenderPDF(data []byte, opts Options) ([]byte, error) {
+	opts = opts.withDefaults()
+	img, err := Render(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return encodePDF(img, dotsPerInch(opts.DPMM))
+}
+
+// encodePDF 将黑白图片保存为单页 PDF
+func encodePDF(img *image.Gray, dpi float64) ([]byte, error) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	// 1 位灰度图片，1 为白色
+	rowLen := (w + 7) / 8
+	bits := make([]byte, rowLen*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if img.Pix[y*img.Stride+x] >= 0x80 {
+				bits[y*rowLen+x/8] |= 0x80 >> (x % 8)
+			}
+		}
+	}
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(bits); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	// 点转换为 point（1 英寸 = 72 point）
+	points := func(dots int) string {
+		return strconv.FormatFloat(float64(dots)/dpi*72, 'f', 2, 64)
+	}
+	pw, ph := points(w), points(h)
+	content := fmt.Sprintf("q %s 0 0 %s 0 0 cm /Im0 Do Q", pw, ph)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /XObject << /Im0 5 0 R >> >> /Contents 4 0 R >>", pw, ph),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 1 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream", w, h, compressed.Len(), compressed.Bytes()),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes(), nil
+}
@@ -0,0 +1,371 @@
+// Package zpl 将 ZPL 面单渲染为 PNG 图片和单页 PDF，用于在没有打印机的情况下预览面单
+//
+// 支持美正面单使用的指令：文本（^A、^CF、^FB、^FH）、Code128（^BC）、PDF417（^B7）、
+// 方框和线条（^GB）、字段位置（^FO、^FT、^LH、^FW）、反色（^FR）以及面单尺寸（^PW、^LL），其他指令将被忽略。
+//
+// 注意：字体使用 Go 字体近似替代打印机字体；不支持 MaxiCode（^BD，UPS 面单使用），
+// 包含 MaxiCode 的面单返回 ErrUnsupportedBarcode，避免生成无法扫描的面单。
+package zpl
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"math"
+)
+
+var (
+	ErrEmptyLabel         = errors.New("没有找到 ZPL 面单")
+	ErrUnsupportedBarcode = errors.New("不支持的条码类型")
+)
+
+// Options 渲染设置
+type Options struct {
+	DPMM   int     // 打印机分辨率（点/毫米），默认为 8（203 dpi）
+	Width  float64 // 面单宽度（英寸），默认为 4，^PW 指令优先
+	Height float64 // 面单高度（英寸），默认为 6，^LL 指令优先
+}
+
+func (o Options) withDefaults() Options {
+	if o.DPMM <= 0 {
+		o.DPMM = 8
+	}
+	if o.Width <= 0 {
+		o.Width = 4
+	}
+	if o.Height <= 0 {
+		o.Height = 6
+	}
+	return o
+}
+
+// dotsPerInch 返回打印机的 DPI（6、8、12、24 点/毫米分别为 152、203、300、600 dpi）
+func dotsPerInch(dpmm int) float64 {
+	switch dpmm {
+	case 6:
+		return 152
+	case 8:
+		return 203
+	case 12:
+		return 300
+	case 24:
+		return 600
+	}
+	return float64(dpmm) * 25.4
+}
+
+// fontSpec 字体设置
+type fontSpec struct {
+	name        byte // 字体名称（0、A ~ Z）
+	orientation byte // 方向（N、R、I、B）
+	height      int  // 字符高度（点）
+	width       int  // 字符宽度（点）
+}
+
+// fieldBlock ^FB 文本块设置
+type fieldBlock struct {
+	width     int  // 宽度（点）
+	lines     int  // 最大行数
+	spacing   int  // 行间距（点）
+	justify   byte // 对齐方式（L、C、R、J）
+	hangingIn int  // 悬挂缩进（点）
+}
+
+// renderer 渲染状态
+type renderer struct {
+	canvas      *image.Gray
+	dpmm        int
+	home        image.Point // ^LH
+	orientation byte        // ^FW
+	defaultFont fontSpec    // ^CF
+	moduleWidth int         // ^BY 模块宽度
+	ratio       float64     // ^BY 宽窄比
+	barHeight   int         // ^BY 条码高度
+
+	// 当前字段
+	origin   image.Point
+	baseline bool // 使用 ^FT 设置的位置（基线）
+	font     *fontSpec
+	barcode  *command
+	box      []string
+	block    *fieldBlock
+	hex      byte
+	reverse  bool
+	data     string
+	hasData  bool
+}
+
+// Render 渲染 ZPL 中的第一个面单
+func Render(data []byte, opts Options) (*image.Gray, error) {
+	opts = opts.withDefaults()
+	commands := parse(data)
+	if len(commands) == 0 {
+		return nil, ErrEmptyLabel
+	}
+
+	dpi := dotsPerInch(opts.DPMM)
+	width := int(math.Round(opts.Width * dpi))
+	height := int(math.Round(opts.Height * dpi))
+	for _, cmd := range commands {
+		switch cmd.name {
+		case "PW":
+			width = intParam(split(cmd.params), 0, width)
+		case "LL":
+			height = intParam(split(cmd.params), 0, height)
+		}
+	}
+
+	r := &renderer{
+		canvas:      image.NewGray(image.Rect(0, 0, max(width, 1), max(height, 1))),
+		dpmm:        opts.DPMM,
+		orientation: 'N',
+		defaultFont: fontSpec{name: 'A', orientation: 'N', height: 9, width: 5},
+		moduleWidth: 2,
+		ratio:       3,
+		barHeight:   10,
+	}
+	for i := range r.canvas.Pix {
+		r.canvas.Pix[i] = 0xff
+	}
+	for _, cmd := range commands {
+		if err := r.exec(cmd); err != nil {
+			return nil, err
+		}
+	}
+	return r.canvas, nil
+}
+
+// RenderPNG 渲染 ZPL 中的第一个面单，返回 PNG 图片
+func RenderPNG(data []byte, opts Options) ([]byte, error) {
+	img, err := Render(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err = png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (r *renderer) exec(cmd command) error {
+	params := split(cmd.params)
+	switch cmd.name {
+	case "LH":
+		r.home = image.Pt(intParam(params, 0, 0), intParam(params, 1, 0))
+	case "FW":
+		r.orientation = charParam(params, 0, r.orientation)
+	case "CF":
+		f := r.defaultFont
+		if name := charParam(params, 0, 0); name != 0 {
+			f.name = name
+		}
+		f.height = intParam(params, 1, f.height)
+		f.width = intParam(params, 2, 0)
+		if f.width == 0 {
+			f.width = defaultFontWidth(f.name, f.height)
+		}
+		r.defaultFont = f
+	case "BY":
+		r.moduleWidth = max(intParam(params, 0, r.moduleWidth), 1)
+		r.ratio = floatParam(params, 1, r.ratio)
+		r.barHeight = intParam(params, 2, r.barHeight)
+	case "FO", "FT":
+		r.origin = image.Pt(intParam(params, 0, 0), intParam(params, 1, 0))
+		r.baseline = cmd.name == "FT"
+	case "A", "A@":
+		f := fontSpec{name: '0', orientation: r.orientation, height: r.defaultFont.height}
+		rest := cmd.params
+		if cmd.name == "A" && rest != "" {
+			f.name, rest = upper(rest[0]), rest[1:]
+		}
+		if rest != "" && rest[0] != ',' {
+			f.orientation, rest = upper(rest[0]), rest[1:]
+		}
+		ps := split(trimComma(rest))
+		f.height = intParam(ps, 0, f.height)
+		f.width = intParam(ps, 1, 0)
+		if f.width == 0 {
+			f.width = defaultFontWidth(f.name, f.height)
+		}
+		r.font = &f
+	case "FB":
+		r.block = &fieldBlock{
+			width:     intParam(params, 0, 0),
+			lines:     max(intParam(params, 1, 1), 1),
+			spacing:   intParam(params, 2, 0),
+			justify:   charParam(params, 3, 'L'),
+			hangingIn: intParam(params, 4, 0),
+		}
+	case "FH":
+		r.hex = '_'
+		if len(cmd.params) > 0 {
+			r.hex = cmd.params[0]
+		}
+	case "FR":
+		r.reverse = true
+	case "FD", "FV":
+		r.data, r.hasData = cmd.params, true
+	case "BC", "B7", "BD":
+		c := cmd
+		r.barcode = &c
+	case "GB":
+		r.box = params
+	case "FS":
+		return r.field()
+	}
+	return nil
+}
+
+// field 绘制当前字段并重置字段状态
+func (r *renderer) field() (err error) {
+	data := r.data
+	if r.hex != 0 {
+		data = decodeHex(data, r.hex)
+	}
+	origin := r.origin.Add(r.home)
+
+	switch {
+	case r.box != nil:
+		r.drawBox(origin, r.box)
+	case r.barcode != nil && r.hasData:
+		err = r.drawBarcode(origin, *r.barcode, data)
+	case r.hasData:
+		f := r.defaultFont
+		f.orientation = r.orientation
+		if r.font != nil {
+			f = *r.font
+		}
+		r.drawText(origin, f, data)
+	}
+
+	r.baseline = false
+	r.font = nil
+	r.barcode = nil
+	r.box = nil
+	r.block = nil
+	r.hex = 0
+	r.reverse = false
+	r.data, r.hasData = "", false
+	return err
+}
+
+// place 将 mask 按方向旋转后绘制到画布上，anchor 为未旋转时 mask 中与字段位置对应的点
+func (r *renderer) place(mask *image.Alpha, origin image.Point, orientation byte, anchor image.Point, white bool) {
+	w, h := mask.Bounds().Dx(), mask.Bounds().Dy()
+	rotated := rotate(mask, orientation)
+	topLeft := origin
+	if r.baseline {
+		topLeft = origin.Sub(rotatePoint(anchor, w, h, orientation))
+	}
+	r.composite(rotated, topLeft, white)
+}
+
+// composite 将 mask 绘制到画布上，反色字段将反转对应位置的颜色
+func (r *renderer) composite(mask *image.Alpha, topLeft image.Point, white bool) {
+	b := mask.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		cy := topLeft.Y + y
+		if cy < 0 || cy >= r.canvas.Rect.Dy() {
+			continue
+		}
+		for x := 0; x < b.Dx(); x++ {
+			cx := topLeft.X + x
+			if cx < 0 || cx >= r.canvas.Rect.Dx() || mask.Pix[y*mask.Stride+x] < 0x80 {
+				continue
+			}
+			i := cy*r.canvas.Stride + cx
+			switch {
+			case r.reverse:
+				r.canvas.Pix[i] ^= 0xff
+			case white:
+				r.canvas.Pix[i] = 0xff
+			default:
+				r.canvas.Pix[i] = 0
+			}
+		}
+	}
+}
+
+// drawBox 绘制方框和线条（^GBw,h,t,c,r）
+func (r *renderer) drawBox(origin image.Point, params []string) {
+	t := max(intParam(params, 2, 1), 1)
+	w := max(intParam(params, 0, t), t)
+	h := max(intParam(params, 1, t), t)
+	white := charParam(params, 3, 'B') == 'W'
+	rounding := min(max(intParam(params, 4, 0), 0), 8)
+
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	radius := float64(rounding) * float64(min(w, h)) / 16
+	inside := func(x, y int, inset, radius float64) bool {
+		fx, fy := float64(x)+0.5, float64(y)+0.5
+		x0, y0, x1, y1 := inset, inset, float64(w)-inset, float64(h)-inset
+		if fx < x0 || fy < y0 || fx > x1 || fy > y1 {
+			return false
+		}
+		if radius <= 0 {
+			return true
+		}
+		cx := min(max(fx, x0+radius), x1-radius)
+		cy := min(max(fy, y0+radius), y1-radius)
+		return (fx-cx)*(fx-cx)+(fy-cy)*(fy-cy) <= radius*radius
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if inside(x, y, 0, radius) && !inside(x, y, float64(t), max(radius-float64(t), 0)) {
+				mask.Pix[y*mask.Stride+x] = 0xff
+			}
+		}
+	}
+	// ^FT 的位置为方框左下角
+	r.place(mask, origin, 'N', image.Pt(0, h), white)
+}
+
+// rotate 按方向顺时针旋转（R：90 度，I：180 度，B：270 度）
+func rotate(src *image.Alpha, orientation byte) *image.Alpha {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	var dst *image.Alpha
+	switch orientation {
+	case 'R', 'B':
+		dst = image.NewAlpha(image.Rect(0, 0, h, w))
+	case 'I':
+		dst = image.NewAlpha(image.Rect(0, 0, w, h))
+	default:
+		return src
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := rotatePoint(image.Pt(x, y), w, h, orientation)
+			dst.Pix[p.Y*dst.Stride+p.X] = src.Pix[y*src.Stride+x]
+		}
+	}
+	return dst
+}
+
+// rotatePoint 返回点旋转后的位置
+func rotatePoint(p image.Point, w, h int, orientation byte) image.Point {
+	switch orientation {
+	case 'R':
+		return image.Pt(h-1-p.Y, p.X)
+	case 'I':
+		return image.Pt(w-1-p.X, h-1-p.Y)
+	case 'B':
+		return image.Pt(p.Y, w-1-p.X)
+	}
+	return p
+}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+func trimComma(s string) string {
+	if s != "" && s[0] == ',' {
+		return s[1:]
+	}
+	return s
+}
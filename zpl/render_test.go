@@ -0,0 +1,98 @@
+package zpl
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 修改渲染逻辑后使用 go test ./zpl -update 更新对比图片
+var update = flag.Bool("update", false, "update golden images")
+
+func TestParse(t *testing.T) {
+	commands := parse([]byte("ignored^XA\n^FO10,20^A0N,30,30^FDA^B^FS\n^XZ^XA^FO1,1^XZ"))
+	assert.Equal(t, []command{
+		{name: "FO", params: "10,20"},
+		{name: "A", params: "0N,30,30"},
+		{name: "FD", params: "A^B"},
+		{name: "FS", params: ""},
+	}, commands)
+	assert.Equal(t, "[)>\x1e01", decodeHex("[)>_1E01", '_'))
+}
+
+func TestRender_Golden(t *testing.T) {
+	for _, name := range []string{"label"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", name+".zpl"))
+			assert.Nil(t, err)
+			b, err := RenderPNG(data, Options{})
+			assert.Nil(t, err)
+
+			golden := filepath.Join("testdata", name+".png")
+			if *update {
+				assert.Nil(t, os.WriteFile(golden, b, 0644))
+			}
+			expected, err := os.ReadFile(golden)
+			assert.Nil(t, err)
+			expectedImg, err := png.Decode(bytes.NewReader(expected))
+			assert.Nil(t, err)
+			img, _ := png.Decode(bytes.NewReader(b))
+			assert.Equal(t, expectedImg.Bounds(), img.Bounds())
+			diff := 0
+			for y := 0; y < img.Bounds().Dy(); y++ {
+				for x := 0; x < img.Bounds().Dx(); x++ {
+					if img.(*image.Gray).GrayAt(x, y) != expectedImg.(*image.Gray).GrayAt(x, y) {
+						diff++
+					}
+				}
+			}
+			assert.Equal(t, 0, diff, "%d pixels differ from %s", diff, golden)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	_, err := Render([]byte("no label"), Options{})
+	assert.True(t, errors.Is(err, ErrEmptyLabel))
+
+	// 面单尺寸
+	img, err := Render([]byte("^XA^FO0,0^GB10,10,10^FS^XZ"), Options{DPMM: 12, Width: 2, Height: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, image.Rect(0, 0, 600, 300), img.Bounds())
+	assert.Equal(t, uint8(0), img.GrayAt(5, 5).Y)
+	assert.Equal(t, uint8(0xff), img.GrayAt(10, 10).Y)
+
+	// 反色
+	img, err = Render([]byte("^XA^PW100^LL100^FO0,0^GB50,50,50^FS^FO25,25^FR^GB50,50,50^FS^XZ"), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(0), img.GrayAt(10, 10).Y)
+	assert.Equal(t, uint8(0xff), img.GrayAt(30, 30).Y)
+	assert.Equal(t, uint8(0), img.GrayAt(60, 60).Y)
+
+	// ^FT 为左下角
+	img, err = Render([]byte("^XA^PW100^LL100^LH10,10^FT0,50^GB20,20,20^FS^XZ"), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(0), img.GrayAt(15, 45).Y)
+	assert.Equal(t, uint8(0xff), img.GrayAt(15, 65).Y)
+
+	// 不支持 MaxiCode
+	_, err = Render([]byte("^XA^PW300^LL300^FO0,0^BDN,2,1^FDTEST^FS^XZ"), Options{})
+	assert.ErrorIs(t, err, ErrUnsupportedBarcode)
+	_, err = RenderPDF([]byte("^XA^FO0,0^BDN,2,1^FDTEST^FS^XZ"), Options{})
+	assert.ErrorIs(t, err, ErrUnsupportedBarcode)
+}
+
+func TestRenderPDF(t *testing.T) {
+	b, err := RenderPDF([]byte("^XA^FO50,50^A0N,40,40^FDPDF^FS^XZ"), Options{})
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(b, []byte("%PDF-1.4")))
+	assert.Contains(t, string(b), "/MediaBox [0 0 288.00 432.00]")
+	assert.Contains(t, string(b), "/Width 812 /Height 1218")
+}
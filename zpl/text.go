@@ -0,0 +1,170 @@
+package zpl
+
+import (
+	"image"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// 使用 Go 字体替代打印机字体：可缩放字体 0 使用 Go Bold，点阵字体（A ~ Z）使用 Go Mono
+var (
+	loadFont = sync.OnceValues(func() (*opentype.Font, error) {
+		return opentype.Parse(gobold.TTF)
+	})
+	loadMonoFont = sync.OnceValues(func() (*opentype.Font, error) {
+		return opentype.Parse(gomono.TTF)
+	})
+	faces   = make(map[faceKey]font.Face)
+	facesMu sync.Mutex
+)
+
+type faceKey struct {
+	mono   bool
+	height int
+}
+
+// face 返回字符高度（上伸部和下伸部之和）为 height 点的字体
+func face(mono bool, height int) (font.Face, error) {
+	key := faceKey{mono: mono, height: height}
+	facesMu.Lock()
+	defer facesMu.Unlock()
+	if f, ok := faces[key]; ok {
+		return f, nil
+	}
+
+	load := loadFont
+	if mono {
+		load = loadMonoFont
+	}
+	ttf, err := load()
+	if err != nil {
+		return nil, err
+	}
+	opts := &opentype.FaceOptions{Size: float64(height), DPI: 72, Hinting: font.HintingNone}
+	f, err := opentype.NewFace(ttf, opts)
+	if err != nil {
+		return nil, err
+	}
+	m := f.Metrics()
+	if total := (m.Ascent + m.Descent).Ceil(); total > 0 {
+		opts.Size = float64(height) * float64(height) / float64(total)
+		if f, err = opentype.NewFace(ttf, opts); err != nil {
+			return nil, err
+		}
+	}
+	faces[key] = f
+	return f, nil
+}
+
+// defaultFontWidth 返回字体的默认字符宽度
+func defaultFontWidth(name byte, height int) int {
+	if name == '0' {
+		return height
+	}
+	return max(height*5/9, 1)
+}
+
+// textWidth 返回文本宽度（点）
+func textWidth(f font.Face, spec fontSpec, text string) int {
+	if spec.name != '0' {
+		return spec.width * len([]rune(text))
+	}
+	return font.MeasureString(f, text).Ceil() * spec.width / max(spec.height, 1)
+}
+
+// drawLine 将一行文本绘制到 mask 的 (x, baseline) 位置
+func drawLine(mask *image.Alpha, f font.Face, spec fontSpec, x, baseline int, text string) {
+	natural := font.MeasureString(f, text).Ceil()
+	if natural <= 0 {
+		return
+	}
+	ascent := f.Metrics().Ascent.Ceil()
+	line := image.NewAlpha(image.Rect(0, 0, natural, spec.height))
+	d := &font.Drawer{Dst: line, Src: image.Opaque, Face: f, Dot: fixed.P(0, ascent)}
+	d.DrawString(text)
+
+	// 按字符宽度水平缩放
+	width := textWidth(f, spec, text)
+	if width <= 0 {
+		return
+	}
+	dst := image.Rect(x, baseline-ascent, x+width, baseline-ascent+spec.height)
+	draw.ApproxBiLinear.Scale(mask, dst, line, line.Bounds(), draw.Over, nil)
+}
+
+// wrap 按宽度拆分文本，\& 为强制换行
+func wrap(f font.Face, spec fontSpec, text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, `\&`) {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := words[0]
+		for _, word := range words[1:] {
+			if width > 0 && textWidth(f, spec, line+" "+word) > width {
+				lines = append(lines, line)
+				line = word
+			} else {
+				line += " " + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// drawText 绘制文本字段
+func (r *renderer) drawText(origin image.Point, spec fontSpec, text string) {
+	spec.height = max(spec.height, 1)
+	spec.width = max(spec.width, 1)
+	f, err := face(spec.name != '0', spec.height)
+	if err != nil {
+		return
+	}
+	ascent := f.Metrics().Ascent.Ceil()
+
+	lines := []string{text}
+	width := textWidth(f, spec, text)
+	lineHeight := spec.height
+	if r.block != nil {
+		lines = wrap(f, spec, text, r.block.width)
+		if len(lines) > r.block.lines {
+			// 超出最大行数的文本合并到最后一行
+			lines = append(lines[:r.block.lines-1], strings.Join(lines[r.block.lines-1:], " "))
+		}
+		width = max(r.block.width, 1)
+		lineHeight += r.block.spacing
+	}
+	if width <= 0 {
+		return
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, width, lineHeight*(len(lines)-1)+spec.height))
+	for i, line := range lines {
+		x := 0
+		if r.block != nil {
+			switch r.block.justify {
+			case 'C':
+				x = (width - textWidth(f, spec, line)) / 2
+			case 'R':
+				x = width - textWidth(f, spec, line)
+			default:
+				if i > 0 {
+					x = r.block.hangingIn
+				}
+			}
+		}
+		drawLine(mask, f, spec, x, i*lineHeight+ascent, line)
+	}
+	// ^FT 的位置为最后一行的基线
+	r.place(mask, origin, spec.orientation, image.Pt(0, (len(lines)-1)*lineHeight+ascent), false)
+}